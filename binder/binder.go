@@ -0,0 +1,693 @@
+package binder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/emptyland/akino/catalog"
+	"github.com/emptyland/akino/sql/ast"
+	"github.com/emptyland/akino/sql/token"
+)
+
+// Bound is the annotated tree of a command: every column reference in it is
+// linked to a source of FROM, an alias of select list or the catalog.
+type Bound struct {
+	Cmd     ast.Command
+	Columns map[ast.Expr]*ColumnRef       // Identifier or `table.column'
+	Targets map[*ast.SetDefine]*ColumnRef // Columns to update
+	Queries map[ast.Query]*Query          // Sources and results of each query
+	Types   map[ast.Expr]Type             // Filled by Check
+	Dest    *Relation                     // Table of INSERT
+}
+
+// Column reference is bound to this expression.
+func (self *Bound) Column(expr ast.Expr) (*ColumnRef, bool) {
+	ref, found := self.Columns[expr]
+	return ref, found
+}
+
+// A named source in FROM: a table or a subquery.
+type Relation struct {
+	Name    string         // Alias, or name of table
+	Table   *catalog.Table // nil for subquery
+	Query   ast.Query      // nil for table
+	Columns []string
+}
+
+func (self *Relation) column(name string) int {
+	for i, col := range self.Columns {
+		if strings.EqualFold(col, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+type ColumnRef struct {
+	Relation *Relation // nil for alias of select list
+	Name     string
+	Index    int               // Index in Relation.Columns or select list
+	Column   *catalog.Column   // nil if relation is not a table
+	Alias    *ast.SelectColumn // Reference to alias of select list
+	Outer    bool              // Correlated reference to outer query
+}
+
+type Query struct {
+	Relations []*Relation
+	Output    []string     // Names of result columns, empty for unnamed expression
+	Exprs     []ast.Expr   // Expression of each result column, nil if expanded from `*'
+	Refs      []*ColumnRef // Column of each result column, nil for computed expression
+	Types     []Type       // Types of result columns, filled by Check
+}
+
+// Bind SELECT and DML commands, others will be returned without column
+// references.
+func Bind(cat *catalog.Catalog, cmd ast.Command) (*Bound, error) {
+	b := &binder{
+		cat: cat,
+		bound: &Bound{
+			Cmd:     cmd,
+			Columns: make(map[ast.Expr]*ColumnRef),
+			Targets: make(map[*ast.SetDefine]*ColumnRef),
+			Queries: make(map[ast.Query]*Query),
+			Types:   make(map[ast.Expr]Type),
+		},
+	}
+	if err := b.bindCommand(cmd); err != nil {
+		return nil, err
+	}
+	return b.bound, nil
+}
+
+type binder struct {
+	cat   *catalog.Catalog
+	bound *Bound
+}
+
+type scope struct {
+	parent *scope
+	rels   []*Relation
+	hidden map[*Relation]map[string]bool // Coalesced by USING or NATURAL
+	alias  []ast.SelectColumn
+}
+
+func newScope(parent *scope) *scope {
+	return &scope{
+		parent: parent,
+		hidden: make(map[*Relation]map[string]bool),
+	}
+}
+
+func (self *scope) relation(name string) *Relation {
+	for _, rel := range self.rels {
+		if strings.EqualFold(rel.Name, name) {
+			return rel
+		}
+	}
+	return nil
+}
+
+func (self *scope) isHidden(rel *Relation, name string) bool {
+	return self.hidden[rel][strings.ToLower(name)]
+}
+
+func (self *scope) hide(rel *Relation, name string) {
+	if self.hidden[rel] == nil {
+		self.hidden[rel] = make(map[string]bool)
+	}
+	self.hidden[rel][strings.ToLower(name)] = true
+}
+
+// How to resolve names which are also aliases of select list.
+const (
+	aliasNone  = iota // WHERE, ON ...
+	aliasFirst        // ORDER BY
+	aliasLast         // GROUP BY and HAVING
+)
+
+//------------------------------------------------------------------------------
+// Commands
+//------------------------------------------------------------------------------
+func (self *binder) bindCommand(cmd ast.Command) error {
+	switch node := cmd.(type) {
+	case ast.Query:
+		_, err := self.bindQuery(nil, node)
+		return err
+
+	case *ast.Insert:
+		return self.bindInsert(node)
+
+	case *ast.Update:
+		return self.bindUpdate(node)
+
+	case *ast.Delete:
+		return self.bindDelete(node)
+
+	case *ast.Explain:
+		return self.bindCommand(node.Cmd)
+
+	default:
+		return nil
+	}
+}
+
+func (self *binder) bindInsert(cmd *ast.Insert) error {
+	dest, err := self.tableRelation(cmd.Pos(), &cmd.Dest, "")
+	if err != nil {
+		return err
+	}
+	self.bound.Dest = dest
+
+	count := len(dest.Columns)
+	if len(cmd.Column) > 0 {
+		count = len(cmd.Column)
+	}
+	for i := range cmd.Column {
+		id := &cmd.Column[i]
+		_, name := id.Dequote()
+		idx := dest.column(name)
+		if idx < 0 {
+			return fmt.Errorf("[%d] Unknown column %s in table %s", id.Pos(), name, dest.Name)
+		}
+		self.bound.Columns[id] = newColumnRef(dest, idx)
+	}
+
+	empty := newScope(nil)
+	for _, row := range cmd.Rows {
+		if len(row) != count && !cmd.DefaultValues() {
+			return fmt.Errorf("[%d] Column count doesn't match value count", row[0].Pos())
+		}
+		for _, expr := range row {
+			if err = self.bindExpr(empty, expr, aliasNone); err != nil {
+				return err
+			}
+		}
+	}
+
+	if cmd.From != nil {
+		var q *Query
+		if q, err = self.bindQuery(nil, cmd.From); err != nil {
+			return err
+		}
+		if len(q.Output) != count {
+			return fmt.Errorf("[%d] Column count doesn't match value count", cmd.From.Pos())
+		}
+	}
+
+	sc := newScope(nil)
+	sc.rels = append(sc.rels, dest)
+	if up := cmd.Upsert; up != nil {
+		// SQLite: excluded.column is the row proposed for insertion
+		excluded := *dest
+		excluded.Name = "excluded"
+		for _, name := range excluded.Columns {
+			sc.hide(&excluded, name)
+		}
+		sc.rels = append(sc.rels, &excluded)
+
+		for i := range up.Target {
+			if err = self.bindExpr(sc, &up.Target[i], aliasNone); err != nil {
+				return err
+			}
+		}
+		if err = self.bindExpr(sc, up.TargetWhere, aliasNone); err != nil {
+			return err
+		}
+		if err = self.bindSet(sc, up.Set); err != nil {
+			return err
+		}
+		if err = self.bindExpr(sc, up.Where, aliasNone); err != nil {
+			return err
+		}
+		sc.rels = sc.rels[:1]
+	}
+	return self.bindColumns(sc, cmd.Returning)
+}
+
+func (self *binder) bindUpdate(cmd *ast.Update) error {
+	sc := newScope(nil)
+	if cmd.Join != nil {
+		if err := self.bindSources(sc, cmd.Join); err != nil {
+			return err
+		}
+	} else {
+		dest, err := self.tableRelation(cmd.Pos(), &cmd.Dest, "")
+		if err != nil {
+			return err
+		}
+		sc.rels = append(sc.rels, dest)
+	}
+	if err := self.bindSources(sc, cmd.From); err != nil {
+		return err
+	}
+
+	if err := self.bindSet(sc, cmd.Set); err != nil {
+		return err
+	}
+	return self.bindTail(sc, cmd.Where, cmd.OrderBy, cmd.Limit, cmd.Offset, cmd.Returning)
+}
+
+func (self *binder) bindDelete(cmd *ast.Delete) error {
+	sc := newScope(nil)
+	if cmd.Join == nil && cmd.Using == nil {
+		dest, err := self.tableRelation(cmd.Pos(), &cmd.Dest, "")
+		if err != nil {
+			return err
+		}
+		sc.rels = append(sc.rels, dest)
+	} else {
+		if err := self.bindSources(sc, cmd.Join); err != nil {
+			return err
+		}
+		if err := self.bindSources(sc, cmd.Using); err != nil {
+			return err
+		}
+		for _, target := range cmd.Target {
+			if rel := sc.relation(target.Table()); rel == nil || rel.Table == nil {
+				return fmt.Errorf("[%d] Unknown table %s in multi delete", cmd.Pos(),
+					target.Full())
+			}
+		}
+	}
+	return self.bindTail(sc, cmd.Where, cmd.OrderBy, cmd.Limit, cmd.Offset, cmd.Returning)
+}
+
+func (self *binder) bindSet(sc *scope, set []ast.SetDefine) error {
+	for i := range set {
+		def := &set[i]
+
+		var ref *ColumnRef
+		var err error
+		if def.Table != "" {
+			ref, err = self.resolveQualified(sc, def.Table, def.Column, def.SetPos)
+		} else {
+			ref, err = self.resolve(sc, def.Column, def.SetPos, aliasNone)
+		}
+		if err != nil {
+			return err
+		}
+		if ref.Outer || ref.Column == nil {
+			return fmt.Errorf("[%d] Column %s is not updatable", def.SetPos, def.Column)
+		}
+		self.bound.Targets[def] = ref
+
+		if err = self.bindExpr(sc, def.Value, aliasNone); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (self *binder) bindTail(sc *scope, where ast.Expr, orderBy []ast.OrderByItem,
+	limit, offset ast.Expr, returning []ast.SelectColumn) error {
+	if err := self.bindExpr(sc, where, aliasNone); err != nil {
+		return err
+	}
+	for _, item := range orderBy {
+		if err := self.bindExpr(sc, item.Item, aliasNone); err != nil {
+			return err
+		}
+	}
+	if err := self.bindExpr(sc, limit, aliasNone); err != nil {
+		return err
+	}
+	if err := self.bindExpr(sc, offset, aliasNone); err != nil {
+		return err
+	}
+	return self.bindColumns(sc, returning)
+}
+
+//------------------------------------------------------------------------------
+// Queries
+//------------------------------------------------------------------------------
+func (self *binder) bindQuery(parent *scope, query ast.Query) (*Query, error) {
+	switch node := query.(type) {
+	case *ast.Select:
+		return self.bindSelect(parent, node)
+
+	case *ast.Compound:
+		return self.bindCompound(parent, node)
+
+	default:
+		return nil, fmt.Errorf("[%d] Unknown query: %T", query.Pos(), query)
+	}
+}
+
+func (self *binder) bindCompound(parent *scope, cmd *ast.Compound) (*Query, error) {
+	left, err := self.bindQuery(parent, cmd.Left)
+	if err != nil {
+		return nil, err
+	}
+	right, err := self.bindQuery(parent, cmd.Right)
+	if err != nil {
+		return nil, err
+	}
+	if len(left.Output) != len(right.Output) {
+		return nil, fmt.Errorf("[%d] SELECTs to the left and right of %s do not have the same "+
+			"number of result columns", cmd.Right.Pos(), cmd.Op)
+	}
+
+	q := &Query{Output: left.Output}
+	self.bound.Queries[cmd] = q
+
+	// ORDER BY of compound can only refer to result columns.
+	result := &Relation{Query: cmd, Columns: q.Output}
+	sc := newScope(parent)
+	sc.rels = []*Relation{result}
+	for _, item := range cmd.OrderBy {
+		if err = self.bindExpr(sc, item.Item, aliasNone); err != nil {
+			return nil, err
+		}
+	}
+	if err = self.bindExpr(sc, cmd.Limit, aliasNone); err != nil {
+		return nil, err
+	}
+	if err = self.bindExpr(sc, cmd.Offset, aliasNone); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func (self *binder) bindSelect(parent *scope, cmd *ast.Select) (*Query, error) {
+	sc := newScope(parent)
+	if err := self.bindSources(sc, cmd.From); err != nil {
+		return nil, err
+	}
+
+	q := &Query{Relations: sc.rels}
+	for _, col := range cmd.SelColList {
+		if lit, ok := col.SelectExpr.(*ast.Literal); ok && lit.Kind == token.STAR {
+			for _, rel := range sc.rels {
+				for i, name := range rel.Columns {
+					if !sc.isHidden(rel, name) {
+						q.Output = append(q.Output, name)
+						q.Exprs = append(q.Exprs, nil)
+						q.Refs = append(q.Refs, newColumnRef(rel, i))
+					}
+				}
+			}
+			continue
+		}
+		if err := self.bindExpr(sc, col.SelectExpr, aliasNone); err != nil {
+			return nil, err
+		}
+		q.Output = append(q.Output, outputName(&col))
+		q.Exprs = append(q.Exprs, col.SelectExpr)
+		q.Refs = append(q.Refs, self.bound.Columns[col.SelectExpr])
+	}
+	self.bound.Queries[cmd] = q
+
+	if err := self.bindExpr(sc, cmd.Where, aliasNone); err != nil {
+		return nil, err
+	}
+
+	sc.alias = cmd.SelColList
+	for _, expr := range cmd.GroupBy {
+		if err := self.bindExpr(sc, expr, aliasLast); err != nil {
+			return nil, err
+		}
+	}
+	if err := self.bindExpr(sc, cmd.Having, aliasLast); err != nil {
+		return nil, err
+	}
+	for _, item := range cmd.OrderBy {
+		if err := self.bindExpr(sc, item.Item, aliasFirst); err != nil {
+			return nil, err
+		}
+	}
+	sc.alias = nil
+
+	if err := self.bindExpr(sc, cmd.Limit, aliasNone); err != nil {
+		return nil, err
+	}
+	if err := self.bindExpr(sc, cmd.Offset, aliasNone); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func outputName(col *ast.SelectColumn) string {
+	if col.Alias != "" {
+		return col.Alias
+	}
+
+	switch expr := col.SelectExpr.(type) {
+	case *ast.Identifier:
+		_, name := expr.Dequote()
+		return name
+
+	case *ast.BinaryExpr:
+		if id, ok := expr.Rhs.(*ast.Identifier); ok && expr.Op == token.DOT {
+			_, name := id.Dequote()
+			return name
+		}
+	}
+	return ""
+}
+
+func (self *binder) bindColumns(sc *scope, list []ast.SelectColumn) error {
+	for _, col := range list {
+		if lit, ok := col.SelectExpr.(*ast.Literal); ok && lit.Kind == token.STAR {
+			continue
+		}
+		if err := self.bindExpr(sc, col.SelectExpr, aliasNone); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
+// Sources
+//------------------------------------------------------------------------------
+func (self *binder) bindSources(sc *scope, list []ast.Source) error {
+	for i := range list {
+		if _, err := self.bindSource(sc, &list[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns all relations in the source.
+func (self *binder) bindSource(sc *scope, src *ast.Source) ([]*Relation, error) {
+	if ok, join := src.IsJoin(); ok {
+		return self.bindJoin(sc, join)
+	}
+
+	var rel *Relation
+	var err error
+	if ok, sub := src.IsSubquery(); ok {
+		// Subquery in FROM can not see other sources in the same FROM.
+		var q *Query
+		if q, err = self.bindQuery(sc.parent, sub); err != nil {
+			return nil, err
+		}
+		rel = &Relation{Name: src.Alias, Query: sub, Columns: q.Output}
+	} else if rel, err = self.tableRelation(src.Pos(), src.Table, src.Alias); err != nil {
+		return nil, err
+	}
+
+	if rel.Name != "" && sc.relation(rel.Name) != nil {
+		return nil, fmt.Errorf("[%d] Not unique table/alias: %s", src.Pos(), rel.Name)
+	}
+	sc.rels = append(sc.rels, rel)
+	return []*Relation{rel}, nil
+}
+
+func (self *binder) bindJoin(sc *scope, join *ast.Join) ([]*Relation, error) {
+	left, err := self.bindSource(sc, &join.Left)
+	if err != nil {
+		return nil, err
+	}
+	right, err := self.bindSource(sc, &join.Right)
+	if err != nil {
+		return nil, err
+	}
+
+	if join.Type&ast.JT_NATURAL != 0 {
+		for _, lrel := range left {
+			for _, name := range lrel.Columns {
+				if sc.isHidden(lrel, name) {
+					continue
+				}
+				for _, rrel := range right {
+					if rrel.column(name) >= 0 {
+						sc.hide(rrel, name)
+					}
+				}
+			}
+		}
+	}
+
+	for i := range join.Using {
+		id := &join.Using[i]
+		_, name := id.Dequote()
+
+		lref := findColumn(sc, left, name)
+		rref := findColumn(sc, right, name)
+		if lref == nil || rref == nil {
+			return nil, fmt.Errorf("[%d] Unknown column %s in USING clause", id.Pos(), name)
+		}
+		sc.hide(rref.Relation, name)
+		self.bound.Columns[id] = lref
+	}
+
+	if err = self.bindExpr(sc, join.On, aliasNone); err != nil {
+		return nil, err
+	}
+	return append(left, right...), nil
+}
+
+func findColumn(sc *scope, rels []*Relation, name string) *ColumnRef {
+	for _, rel := range rels {
+		if idx := rel.column(name); idx >= 0 && !sc.isHidden(rel, name) {
+			return newColumnRef(rel, idx)
+		}
+	}
+	return nil
+}
+
+func (self *binder) tableRelation(pos int, name *ast.NameRef, alias string) (*Relation, error) {
+	table, found := self.cat.LookupTable(*name)
+	if !found {
+		return nil, fmt.Errorf("[%d] Unknown table %s", pos, name.Full())
+	}
+
+	rel := &Relation{
+		Name:    alias,
+		Table:   table,
+		Columns: make([]string, 0, len(table.Columns)),
+	}
+	if rel.Name == "" {
+		rel.Name = table.Name
+	}
+	for _, col := range table.Columns {
+		rel.Columns = append(rel.Columns, col.Name)
+	}
+	return rel, nil
+}
+
+func newColumnRef(rel *Relation, idx int) *ColumnRef {
+	ref := &ColumnRef{
+		Relation: rel,
+		Name:     rel.Columns[idx],
+		Index:    idx,
+	}
+	if rel.Table != nil {
+		ref.Column = rel.Table.Columns[idx]
+	}
+	return ref
+}
+
+//------------------------------------------------------------------------------
+// Expressions
+//------------------------------------------------------------------------------
+func (self *binder) bindExpr(sc *scope, expr ast.Expr, alias int) error {
+	var err error
+	ast.Walk(expr, func(node ast.Node) bool {
+		if err != nil {
+			return false
+		}
+
+		switch n := node.(type) {
+		case *ast.Identifier:
+			if _, ok := n.BoolConst(); ok {
+				return false
+			}
+			_, name := n.Dequote()
+			var ref *ColumnRef
+			if ref, err = self.resolve(sc, name, n.Pos(), alias); err == nil {
+				self.bound.Columns[n] = ref
+			}
+			return false
+
+		case *ast.BinaryExpr:
+			if n.Op != token.DOT {
+				return true
+			}
+			lhs, ok1 := n.Lhs.(*ast.Identifier)
+			rhs, ok2 := n.Rhs.(*ast.Identifier)
+			if !ok1 || !ok2 {
+				return true
+			}
+			_, table := lhs.Dequote()
+			_, name := rhs.Dequote()
+
+			var ref *ColumnRef
+			if ref, err = self.resolveQualified(sc, table, name, lhs.Pos()); err == nil {
+				self.bound.Columns[n] = ref
+			}
+			return false
+
+		case ast.Query:
+			_, err = self.bindQuery(sc, n)
+			return false
+
+		default:
+			return true
+		}
+	})
+	return err
+}
+
+func (self *binder) resolve(sc *scope, name string, pos, alias int) (*ColumnRef, error) {
+	if alias == aliasFirst {
+		if ref := resolveAlias(sc, name); ref != nil {
+			return ref, nil
+		}
+	}
+
+	outer := false
+	for s := sc; s != nil; s = s.parent {
+		var ref *ColumnRef
+		for _, rel := range s.rels {
+			idx := rel.column(name)
+			if idx < 0 || s.isHidden(rel, name) {
+				continue
+			}
+			if ref != nil {
+				return nil, fmt.Errorf("[%d] Ambiguous column %s", pos, name)
+			}
+			ref = newColumnRef(rel, idx)
+		}
+		if ref != nil {
+			ref.Outer = outer
+			return ref, nil
+		}
+
+		if s == sc && alias == aliasLast {
+			if ref := resolveAlias(sc, name); ref != nil {
+				return ref, nil
+			}
+		}
+		outer = true
+	}
+	return nil, fmt.Errorf("[%d] Unknown column %s", pos, name)
+}
+
+func resolveAlias(sc *scope, name string) *ColumnRef {
+	for i := range sc.alias {
+		if col := &sc.alias[i]; col.Alias != "" && strings.EqualFold(col.Alias, name) {
+			return &ColumnRef{Name: col.Alias, Index: i, Alias: col}
+		}
+	}
+	return nil
+}
+
+func (self *binder) resolveQualified(sc *scope, table, name string, pos int) (*ColumnRef, error) {
+	outer := false
+	for s := sc; s != nil; s = s.parent {
+		if rel := s.relation(table); rel != nil {
+			idx := rel.column(name)
+			if idx < 0 {
+				return nil, fmt.Errorf("[%d] Unknown column %s.%s", pos, table, name)
+			}
+			ref := newColumnRef(rel, idx)
+			ref.Outer = outer
+			return ref, nil
+		}
+		outer = true
+	}
+	return nil, fmt.Errorf("[%d] Unknown table %s", pos, table)
+}
@@ -0,0 +1,200 @@
+package binder
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/emptyland/akino/catalog"
+	"github.com/emptyland/akino/sql/ast"
+	"github.com/emptyland/akino/sql/parser"
+)
+
+func newCatalog(t *testing.T) *catalog.Catalog {
+	cat := catalog.New()
+	for _, sql := range []string{
+		"CREATE TABLE t (id INT PRIMARY KEY, a INT, b VARCHAR(32))",
+		"CREATE TABLE s (id INT PRIMARY KEY, a INT, c INT)",
+		"CREATE TABLE u (x INT, y INT)",
+	} {
+		cmd, err := parser.ParseCommand(sql)
+		if err != nil {
+			t.Fatal(sql, err)
+		}
+		if err = cat.Apply(cmd); err != nil {
+			t.Fatal(sql, err)
+		}
+	}
+	return cat
+}
+
+func TestBindSelect(t *testing.T) {
+	cat := newCatalog(t)
+	bound := assertBind(t, cat, "SELECT t.a, b AS bb FROM t WHERE id > 1 ORDER BY bb")
+	sel := bound.Cmd.(*ast.Select)
+
+	ref := assertRef(t, bound, sel.SelColList[0].SelectExpr, "t", "a")
+	if ref.Index != 1 || ref.Column == nil || ref.Column.Ordinal != 1 {
+		t.Fatal(ref)
+	}
+	assertRef(t, bound, sel.SelColList[1].SelectExpr, "t", "b")
+	assertRef(t, bound, sel.Where.(*ast.BinaryExpr).Lhs, "t", "id")
+
+	ref, _ = bound.Column(sel.OrderBy[0].Item)
+	if ref == nil || ref.Alias != &sel.SelColList[1] {
+		t.Fatal(ref)
+	}
+
+	q := bound.Queries[sel]
+	if len(q.Relations) != 1 || strings.Join(q.Output, ",") != "a,bb" {
+		t.Fatal(q)
+	}
+}
+
+func TestBindJoin(t *testing.T) {
+	cat := newCatalog(t)
+	bound := assertBind(t, cat, "SELECT * FROM t AS x JOIN s ON x.id = s.id WHERE c > 0")
+	sel := bound.Cmd.(*ast.Select)
+	if q := bound.Queries[sel]; strings.Join(q.Output, ",") != "id,a,b,id,a,c" {
+		t.Fatal(q.Output)
+	}
+	on := sel.From[0].Join.On.(*ast.BinaryExpr)
+	assertRef(t, bound, on.Lhs, "x", "id")
+	assertRef(t, bound, on.Rhs, "s", "id")
+
+	bound = assertBind(t, cat, "SELECT id, a FROM t JOIN s USING (id, a)")
+	sel = bound.Cmd.(*ast.Select)
+	assertRef(t, bound, sel.SelColList[0].SelectExpr, "t", "id")
+	assertRef(t, bound, sel.SelColList[1].SelectExpr, "t", "a")
+
+	bound = assertBind(t, cat, "SELECT * FROM t NATURAL JOIN s")
+	sel = bound.Cmd.(*ast.Select)
+	if q := bound.Queries[sel]; strings.Join(q.Output, ",") != "id,a,b,c" {
+		t.Fatal(q.Output)
+	}
+
+	assertFail(t, cat, "SELECT id FROM t, s", "Ambiguous column id")
+	assertFail(t, cat, "SELECT a FROM t JOIN s USING (id)", "Ambiguous column a")
+	assertFail(t, cat, "SELECT * FROM t JOIN u USING (id)", "Unknown column id in USING")
+	assertFail(t, cat, "SELECT * FROM t, t", "Not unique table/alias: t")
+	assertFail(t, cat, "SELECT x.a FROM t", "Unknown table x")
+	assertFail(t, cat, "SELECT t.c FROM t", "Unknown column t.c")
+	assertFail(t, cat, "SELECT * FROM v", "Unknown table v")
+}
+
+func TestBindSubquery(t *testing.T) {
+	cat := newCatalog(t)
+	bound := assertBind(t, cat, "SELECT q.n, x FROM (SELECT a AS n FROM t) AS q, u "+
+		"WHERE x IN (SELECT s.id FROM s WHERE s.a = q.n AND c = y)")
+	sel := bound.Cmd.(*ast.Select)
+
+	ref := assertRef(t, bound, sel.SelColList[0].SelectExpr, "q", "n")
+	if ref.Column != nil || ref.Relation.Query == nil {
+		t.Fatal(ref)
+	}
+
+	var outer int
+	ast.Walk(sel.Where, func(node ast.Node) bool {
+		if expr, ok := node.(ast.Expr); ok {
+			if ref, found := bound.Column(expr); found && ref.Outer {
+				outer++
+			}
+		}
+		return true
+	})
+	if outer != 2 {
+		t.Fatal(outer)
+	}
+
+	assertFail(t, cat, "SELECT * FROM t, (SELECT t.a FROM s) AS q", "Unknown table t")
+	assertFail(t, cat, "SELECT n FROM (SELECT a AS n FROM t) AS q WHERE a > 0",
+		"Unknown column a")
+	assertFail(t, cat, "SELECT a FROM t UNION SELECT a, c FROM s", "same number")
+	assertBind(t, cat, "SELECT a FROM t UNION SELECT c FROM s ORDER BY a")
+}
+
+func TestBindGroupBy(t *testing.T) {
+	cat := newCatalog(t)
+	bound := assertBind(t, cat,
+		"SELECT a AS k, COUNT(*) AS n FROM t GROUP BY k HAVING n > 1 ORDER BY a")
+	sel := bound.Cmd.(*ast.Select)
+	if ref, _ := bound.Column(sel.GroupBy[0]); ref == nil || ref.Alias == nil {
+		t.Fatal(ref)
+	}
+	assertRef(t, bound, sel.OrderBy[0].Item, "t", "a")
+
+	assertFail(t, cat, "SELECT a AS k FROM t WHERE k > 0", "Unknown column k")
+}
+
+func TestBindTrueFalse(t *testing.T) {
+	cat := newCatalog(t)
+	bound := assertBind(t, cat, "SELECT TRUE, false FROM t WHERE TRUE AND a > 0")
+	sel := bound.Cmd.(*ast.Select)
+	if ref, _ := bound.Column(sel.SelColList[0].SelectExpr); ref != nil {
+		t.Fatal(ref)
+	}
+	assertFail(t, cat, "SELECT `true` FROM t", "Unknown column true")
+}
+
+func TestBindDML(t *testing.T) {
+	cat := newCatalog(t)
+	bound := assertBind(t, cat, "INSERT INTO t (id, a) VALUES (1, 2)")
+	ins := bound.Cmd.(*ast.Insert)
+	assertRef(t, bound, &ins.Column[1], "t", "a")
+
+	assertBind(t, cat, "INSERT INTO t (id, a) SELECT x, y FROM u")
+	assertBind(t, cat, "INSERT INTO t (id) VALUES (1) ON CONFLICT (id) DO UPDATE SET a = excluded.a")
+	assertFail(t, cat, "INSERT INTO t (id, d) VALUES (1, 2)", "Unknown column d")
+	assertFail(t, cat, "INSERT INTO t (id, a) VALUES (1)", "Column count")
+	assertFail(t, cat, "INSERT INTO t SELECT x, y FROM u", "Column count")
+
+	bound = assertBind(t, cat, "UPDATE t SET a = a + 1, t.b = 'x' WHERE id = 1")
+	upd := bound.Cmd.(*ast.Update)
+	if ref := bound.Targets[&upd.Set[1]]; ref == nil || ref.Name != "b" {
+		t.Fatal(ref)
+	}
+	assertFail(t, cat, "UPDATE t SET c = 1", "[13] Unknown column c")
+	assertFail(t, cat, "UPDATE t JOIN s ON t.id = s.id SET a = 1", "[35] Ambiguous column a")
+	assertFail(t, cat, "UPDATE t SET x.a = 1", "[13] Unknown")
+
+	assertBind(t, cat, "DELETE FROM t WHERE a = 1")
+	assertBind(t, cat, "DELETE t FROM t JOIN s ON t.id = s.id WHERE s.c = 0")
+	assertFail(t, cat, "DELETE u FROM t JOIN s ON t.id = s.id", "Unknown table u")
+	assertFail(t, cat, "DELETE FROM t WHERE c = 1", "Unknown column c")
+}
+
+func assertBind(t *testing.T, cat *catalog.Catalog, sql string) *Bound {
+	cmd, err := parser.ParseCommand(sql)
+	if err != nil {
+		t.Fatal(sql, err)
+	}
+	bound, err := Bind(cat, cmd)
+	if err != nil {
+		t.Fatal(sql, err)
+	}
+	return bound
+}
+
+func assertFail(t *testing.T, cat *catalog.Catalog, sql, msg string) {
+	cmd, err := parser.ParseCommand(sql)
+	if err != nil {
+		t.Fatal(sql, err)
+	}
+	if _, err = Bind(cat, cmd); err == nil {
+		t.Fatal("Should be fail:", sql)
+	}
+	if !strings.Contains(err.Error(), msg) {
+		t.Fatal(sql, err)
+	}
+	t.Log(err)
+}
+
+func assertRef(t *testing.T, bound *Bound, expr ast.Expr, table, column string) *ColumnRef {
+	ref, found := bound.Column(expr)
+	if !found {
+		t.Fatalf("Column %s.%s not bound", table, column)
+	}
+	if ref.Relation == nil || ref.Relation.Name != table || ref.Name != column {
+		t.Fatal(ref, ref.Relation)
+	}
+	return ref
+}
@@ -0,0 +1,821 @@
+package binder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/emptyland/akino/sql/ast"
+	"github.com/emptyland/akino/sql/function"
+	"github.com/emptyland/akino/sql/token"
+)
+
+// Type of expression inferred by Check.
+type Type struct {
+	Class    function.Type // TYPE_ANY for NULL or unknown
+	Nullable bool
+	Decl     *ast.Type // Declared type of column or CAST, nil if none
+	Collate  string    // Collation of string, empty for the default
+}
+
+func (self Type) String() string {
+	if self.Nullable {
+		return self.Class.String() + " null"
+	}
+	return self.Class.String()
+}
+
+// Type of expression inferred by Check.
+func (self *Bound) Type(expr ast.Expr) (Type, bool) {
+	t, found := self.Types[expr]
+	return t, found
+}
+
+// Collation of expression inferred by Check, empty for the default.
+func (self *Bound) Collation(expr ast.Expr) string {
+	return self.Types[expr].Collate
+}
+
+// Class of declared type of column or CAST.
+func ClassOf(t *ast.Type) function.Type {
+	switch t.Kind {
+	case token.BOOL, token.BOOLEAN:
+		return function.TYPE_BOOL
+
+	case token.BIT, token.TINYINT, token.SMALLINT, token.MEDIUMINT, token.INT,
+		token.INTEGER, token.BIGINT, token.YEAR:
+		return function.TYPE_INT
+
+	case token.FLOAT, token.DOUBLE:
+		return function.TYPE_REAL
+
+	case token.DECIMAL:
+		return function.TYPE_DECIMAL
+
+	case token.DATE, token.DATETIME, token.TIMESTAMP, token.TIME:
+		return function.TYPE_DATETIME
+
+	case token.CHAR, token.VARCHAR, token.TINYTEXT, token.TEXT, token.MEDIUMTEXT,
+		token.LONGTEXT, token.ENUM, token.SET, token.JSON:
+		return function.TYPE_STRING
+
+	case token.BINARY, token.VARBINARY, token.TINYBLOB, token.BLOB, token.MEDIUMBLOB,
+		token.LONGBLOB, token.GEOMETRY, token.POINT, token.LINESTRING, token.POLYGON,
+		token.MULTIPOINT, token.MULTILINESTRING, token.MULTIPOLYGON,
+		token.GEOMETRYCOLLECTION:
+		return function.TYPE_BYTES
+
+	default:
+		return function.TYPE_ANY
+	}
+}
+
+// Infer types of all expressions and results of queries in the bound command,
+// reports operands which can not be implicitly coerced.
+func Check(bound *Bound, reg *function.Registry) error {
+	c := &checker{
+		bound: bound,
+		reg:   reg,
+		done:  make(map[ast.Query]bool),
+	}
+	return c.checkCommand(bound.Cmd)
+}
+
+type checker struct {
+	bound *Bound
+	reg   *function.Registry
+	done  map[ast.Query]bool
+}
+
+//------------------------------------------------------------------------------
+// Coercion
+//------------------------------------------------------------------------------
+func isNumeric(c function.Type) bool {
+	switch c {
+	case function.TYPE_BOOL, function.TYPE_INT, function.TYPE_DECIMAL, function.TYPE_REAL:
+		return true
+
+	default:
+		return false
+	}
+}
+
+// BOOL < INT < DECIMAL < REAL
+func widen(a, b function.Type) function.Type {
+	rank := func(c function.Type) int {
+		switch c {
+		case function.TYPE_REAL:
+			return 3
+		case function.TYPE_DECIMAL:
+			return 2
+		case function.TYPE_INT:
+			return 1
+		default:
+			return 0
+		}
+	}
+	if rank(a) >= rank(b) {
+		return a
+	}
+	return b
+}
+
+// Like MySQL, strings can be coerced to all others by converting, but numbers
+// can not be coerced to date/time or binary strings. Intervals can not be
+// coerced.
+func coercible(a, b function.Type) bool {
+	switch {
+	case a == function.TYPE_INTERVAL || b == function.TYPE_INTERVAL:
+		return false
+	case a == function.TYPE_ANY || b == function.TYPE_ANY || a == b:
+		return true
+	case isNumeric(a) && isNumeric(b):
+		return true
+	case a == function.TYPE_STRING || b == function.TYPE_STRING:
+		return true
+	default:
+		return false
+	}
+}
+
+// Operands can be compared if they are coercible, date/time can also be
+// compared with numbers like 20200102 as the evaluator does.
+func comparable(a, b function.Type) bool {
+	switch {
+	case a == function.TYPE_DATETIME && isNumeric(b), isNumeric(a) && b == function.TYPE_DATETIME:
+		return true
+	default:
+		return coercible(a, b)
+	}
+}
+
+// Result class of CASE, COALESCE and UNION, returns false if incompatible.
+func unify(a, b function.Type) (function.Type, bool) {
+	switch {
+	case a == function.TYPE_ANY:
+		return b, true
+	case b == function.TYPE_ANY || a == b:
+		return a, true
+	case isNumeric(a) && isNumeric(b):
+		return widen(a, b), true
+	case a == function.TYPE_BYTES || b == function.TYPE_BYTES:
+		if a == function.TYPE_STRING || b == function.TYPE_STRING {
+			return function.TYPE_BYTES, true
+		}
+		return function.TYPE_ANY, false
+	case a == function.TYPE_STRING || b == function.TYPE_STRING:
+		return function.TYPE_STRING, true
+	default:
+		return function.TYPE_ANY, false
+	}
+}
+
+// Like MySQL, collations of operands must be the same if both are declared,
+// the declared one is used if another one is the default.
+func mixCollation(pos int, op string, a, b string) (string, error) {
+	switch {
+	case a == "":
+		return b, nil
+	case b == "" || strings.EqualFold(a, b):
+		return a, nil
+	default:
+		return "", fmt.Errorf("[%d] Illegal mix of collations (%s) and (%s) for operation '%s'",
+			pos, a, b, op)
+	}
+}
+
+// Argument of class `got' can be passed to parameter of class `want'.
+func assignable(want, got function.Type) bool {
+	switch {
+	case want == function.TYPE_ANY:
+		return true
+	case want == function.TYPE_INTERVAL || got == function.TYPE_INTERVAL:
+		return want == got
+	}
+
+	switch want {
+	case function.TYPE_STRING:
+		return true
+	case function.TYPE_BOOL, function.TYPE_INT, function.TYPE_DECIMAL, function.TYPE_REAL:
+		return coercible(function.TYPE_INT, got)
+	default:
+		return coercible(want, got)
+	}
+}
+
+//------------------------------------------------------------------------------
+// Commands and Queries
+//------------------------------------------------------------------------------
+func (self *checker) checkCommand(cmd ast.Command) error {
+	switch node := cmd.(type) {
+	case ast.Query:
+		return self.checkQuery(node)
+
+	case *ast.Insert:
+		return self.checkInsert(node)
+
+	case *ast.Update:
+		if err := self.checkNode(node); err != nil {
+			return err
+		}
+		for i := range node.Set {
+			def := &node.Set[i]
+			if err := self.checkAssign(self.bound.Targets[def], def.Value); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case *ast.Explain:
+		return self.checkCommand(node.Cmd)
+
+	default:
+		return self.checkNode(node)
+	}
+}
+
+func (self *checker) checkInsert(cmd *ast.Insert) error {
+	if err := self.checkNode(cmd); err != nil {
+		return err
+	}
+
+	dest := self.bound.Dest
+	columns := make([]*ColumnRef, 0, len(dest.Columns))
+	for i := range cmd.Column {
+		columns = append(columns, self.bound.Columns[&cmd.Column[i]])
+	}
+	if len(cmd.Column) == 0 {
+		for i := range dest.Columns {
+			columns = append(columns, newColumnRef(dest, i))
+		}
+	}
+
+	for _, row := range cmd.Rows {
+		for i, expr := range row {
+			if err := self.checkAssign(columns[i], expr); err != nil {
+				return err
+			}
+		}
+	}
+	if cmd.From != nil {
+		for i, t := range self.bound.Queries[cmd.From].Types {
+			if !coercible(ClassOf(&columns[i].Column.Type), t.Class) {
+				return fmt.Errorf("[%d] Incompatible type %s for column %s", cmd.From.Pos(),
+					t.Class, columns[i].Name)
+			}
+		}
+	}
+	if up := cmd.Upsert; up != nil {
+		for i := range up.Set {
+			def := &up.Set[i]
+			if err := self.checkAssign(self.bound.Targets[def], def.Value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (self *checker) checkAssign(col *ColumnRef, value ast.Expr) error {
+	if col == nil || col.Column == nil {
+		return nil
+	}
+	t, err := self.typeOf(value)
+	if err != nil {
+		return err
+	}
+	if !coercible(ClassOf(&col.Column.Type), t.Class) {
+		return fmt.Errorf("[%d] Incompatible type %s for column %s", value.Pos(), t.Class,
+			col.Name)
+	}
+	return nil
+}
+
+// Infer all expressions in node, queries in node are checked as well.
+func (self *checker) checkNode(node ast.Node) error {
+	var err error
+	ast.Walk(node, func(n ast.Node) bool {
+		if err != nil {
+			return false
+		}
+		if q, ok := n.(ast.Query); ok {
+			err = self.checkQuery(q)
+			return false
+		}
+		if isExpr(n) {
+			_, err = self.typeOf(n)
+			return false
+		}
+		return true
+	})
+	return err
+}
+
+func isExpr(node ast.Node) bool {
+	switch node.(type) {
+	case *ast.Literal, *ast.Identifier, *ast.UnaryExpr, *ast.BinaryExpr, *ast.CallExpr,
+		*ast.Condition, *ast.CastExpr, ast.ExprList, *ast.Variable, *ast.PseudoColumn:
+		return true
+
+	default:
+		return false
+	}
+}
+
+func (self *checker) checkQuery(query ast.Query) error {
+	if self.done[query] {
+		return nil
+	}
+	self.done[query] = true
+
+	q, found := self.bound.Queries[query]
+	if !found {
+		return fmt.Errorf("[%d] Query is not bound", query.Pos())
+	}
+
+	switch node := query.(type) {
+	case *ast.Select:
+		for _, rel := range q.Relations {
+			if rel.Query != nil {
+				if err := self.checkQuery(rel.Query); err != nil {
+					return err
+				}
+			}
+		}
+		q.Types = make([]Type, len(q.Output))
+		for i := range q.Output {
+			var t Type
+			var err error
+			if q.Exprs[i] != nil {
+				t, err = self.typeOf(q.Exprs[i])
+			} else {
+				t, err = self.refType(q.Refs[i])
+			}
+			if err != nil {
+				return err
+			}
+			q.Types[i] = t
+		}
+		for _, src := range node.From {
+			if err := self.checkNode(&src); err != nil {
+				return err
+			}
+		}
+		return self.checkExprs(node.Where, ast.ExprList(node.GroupBy), node.Having,
+			orderByExprs(node.OrderBy), node.Limit, node.Offset)
+
+	case *ast.Compound:
+		if err := self.checkQuery(node.Left); err != nil {
+			return err
+		}
+		if err := self.checkQuery(node.Right); err != nil {
+			return err
+		}
+		left, right := self.bound.Queries[node.Left], self.bound.Queries[node.Right]
+		q.Types = make([]Type, len(left.Types))
+		for i := range left.Types {
+			class, ok := unify(left.Types[i].Class, right.Types[i].Class)
+			if !ok {
+				return fmt.Errorf("[%d] Incompatible types %s and %s in column %d of %s",
+					node.Right.Pos(), left.Types[i].Class, right.Types[i].Class, i+1, node.Op)
+			}
+			collate, err := mixCollation(node.Right.Pos(), node.Op.String(),
+				left.Types[i].Collate, right.Types[i].Collate)
+			if err != nil {
+				return err
+			}
+			q.Types[i] = Type{
+				Class:    class,
+				Nullable: left.Types[i].Nullable || right.Types[i].Nullable,
+				Collate:  collate,
+			}
+		}
+		return self.checkExprs(orderByExprs(node.OrderBy), node.Limit, node.Offset)
+
+	default:
+		return fmt.Errorf("[%d] Unknown query: %T", query.Pos(), query)
+	}
+}
+
+func orderByExprs(list []ast.OrderByItem) ast.ExprList {
+	exprs := make(ast.ExprList, 0, len(list))
+	for _, item := range list {
+		exprs = append(exprs, item.Item)
+	}
+	return exprs
+}
+
+func (self *checker) checkExprs(list ...ast.Expr) error {
+	for _, expr := range list {
+		if expr == nil {
+			continue
+		}
+		if exprs, ok := expr.(ast.ExprList); ok && len(exprs) == 0 {
+			continue
+		}
+		if err := self.checkNode(expr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
+// Expressions
+//------------------------------------------------------------------------------
+func (self *checker) typeOf(expr ast.Expr) (Type, error) {
+	if _, ok := expr.(ast.ExprList); ok {
+		return self.infer(expr) // Not hashable
+	}
+	if t, found := self.bound.Types[expr]; found {
+		return t, nil
+	}
+	t, err := self.infer(expr)
+	if err == nil {
+		self.bound.Types[expr] = t
+	}
+	return t, err
+}
+
+func (self *checker) infer(expr ast.Expr) (Type, error) {
+	switch node := expr.(type) {
+	case *ast.Literal:
+		return literalType(node), nil
+
+	case *ast.Identifier:
+		if _, ok := node.BoolConst(); ok {
+			return Type{Class: function.TYPE_BOOL}, nil
+		}
+		return self.refType(self.bound.Columns[node])
+
+	case *ast.UnaryExpr:
+		return self.inferUnary(node)
+
+	case *ast.BinaryExpr:
+		if node.Op == token.DOT {
+			return self.refType(self.bound.Columns[node])
+		}
+		return self.inferBinary(node)
+
+	case *ast.CallExpr:
+		return self.inferCall(node)
+
+	case *ast.Condition:
+		return self.inferCondition(node)
+
+	case *ast.CastExpr:
+		operand, err := self.typeOf(node.Operand)
+		if err != nil {
+			return Type{}, err
+		}
+		return Type{Class: ClassOf(&node.To), Nullable: operand.Nullable, Decl: &node.To}, nil
+
+	case *ast.IntervalExpr:
+		value, err := self.typeOf(node.Value)
+		if err != nil {
+			return Type{}, err
+		}
+		if !isNumeric(value.Class) && value.Class != function.TYPE_STRING &&
+			value.Class != function.TYPE_ANY {
+			return Type{}, fmt.Errorf("[%d] Incompatible INTERVAL value: %s", node.Value.Pos(),
+				value.Class)
+		}
+		return Type{Class: function.TYPE_INTERVAL, Nullable: value.Nullable}, nil
+
+	case ast.ExprList:
+		for _, elem := range node {
+			if _, err := self.typeOf(elem); err != nil {
+				return Type{}, err
+			}
+		}
+		return Type{Nullable: true}, nil
+
+	case ast.Query:
+		if err := self.checkQuery(node); err != nil {
+			return Type{}, err
+		}
+		q := self.bound.Queries[node]
+		if len(q.Types) != 1 {
+			return Type{}, fmt.Errorf("[%d] Subquery returns %d columns, expected 1", node.Pos(),
+				len(q.Types))
+		}
+		return Type{Class: q.Types[0].Class, Nullable: true, Collate: q.Types[0].Collate}, nil
+
+	default:
+		// Variables and pseudo columns are known at runtime.
+		return Type{Nullable: true}, nil
+	}
+}
+
+// Like MySQL, exact-value numbers with fractional part are DECIMAL.
+func literalType(lit *ast.Literal) Type {
+	switch lit.Kind {
+	case token.INT_LITERAL:
+		return Type{Class: function.TYPE_INT}
+
+	case token.FLOAT_LITERAL:
+		return Type{Class: function.TYPE_DECIMAL}
+
+	case token.STRING_LITERAL:
+		return Type{Class: function.TYPE_STRING}
+
+	case token.DATE, token.TIME, token.TIMESTAMP:
+		return Type{Class: function.TYPE_DATETIME}
+
+	case token.NULL:
+		return Type{Nullable: true}
+
+	default:
+		return Type{}
+	}
+}
+
+func (self *checker) refType(ref *ColumnRef) (Type, error) {
+	switch {
+	case ref == nil:
+		return Type{Nullable: true}, nil
+
+	case ref.Alias != nil:
+		return self.typeOf(ref.Alias.SelectExpr)
+
+	case ref.Column != nil:
+		t := Type{
+			Class:    ClassOf(&ref.Column.Type),
+			Nullable: !ref.Column.NotNull,
+			Decl:     &ref.Column.Type,
+		}
+		if t.Class == function.TYPE_STRING {
+			t.Collate = ref.Relation.Table.Collation(ref.Column)
+		}
+		return t, nil
+
+	case ref.Relation != nil && ref.Relation.Query != nil:
+		if err := self.checkQuery(ref.Relation.Query); err != nil {
+			return Type{}, err
+		}
+		q := self.bound.Queries[ref.Relation.Query]
+		if ref.Index < len(q.Types) {
+			return q.Types[ref.Index], nil
+		}
+		return Type{Nullable: true}, nil
+
+	default:
+		return Type{Nullable: true}, nil
+	}
+}
+
+func (self *checker) inferUnary(expr *ast.UnaryExpr) (Type, error) {
+	operand, err := self.typeOf(expr.Operand)
+	if err != nil {
+		return Type{}, err
+	}
+
+	switch expr.Op {
+	case token.IS_NULL, token.IS_NOT_NULL:
+		return Type{Class: function.TYPE_BOOL}, nil
+
+	case token.NOT:
+		return Type{Class: function.TYPE_BOOL, Nullable: operand.Nullable}, nil
+
+	default: // MINUS
+		class, err := arithmetic(expr.Pos(), expr.Op, operand.Class, function.TYPE_ANY)
+		if err != nil {
+			return Type{}, err
+		}
+		if class == function.TYPE_BOOL {
+			class = function.TYPE_INT
+		}
+		return Type{Class: class, Nullable: operand.Nullable}, nil
+	}
+}
+
+func (self *checker) inferBinary(expr *ast.BinaryExpr) (Type, error) {
+	lhs, err := self.typeOf(expr.Lhs)
+	if err != nil {
+		return Type{}, err
+	}
+	rhs, err := self.typeOf(expr.Rhs)
+	if err != nil {
+		return Type{}, err
+	}
+	nullable := lhs.Nullable || rhs.Nullable
+
+	switch expr.Op {
+	case token.PLUS, token.MINUS, token.STAR, token.SLASH:
+		class, err := arithmetic(expr.Pos(), expr.Op, lhs.Class, rhs.Class)
+		if err != nil {
+			return Type{}, err
+		}
+		// NULL if division by zero or date is out of range.
+		nullable = nullable || expr.Op == token.SLASH || class == function.TYPE_DATETIME
+		return Type{Class: class, Nullable: nullable}, nil
+
+	case token.EQ, token.NE, token.LT, token.LE, token.GT, token.GE, token.LIKE:
+		if !comparable(lhs.Class, rhs.Class) {
+			return Type{}, incompatible(expr.Pos(), expr.Op, lhs.Class, rhs.Class)
+		}
+		if _, err = mixCollation(expr.Pos(), expr.Op.String(), lhs.Collate,
+			rhs.Collate); err != nil {
+			return Type{}, err
+		}
+		return Type{Class: function.TYPE_BOOL, Nullable: nullable}, nil
+
+	case token.IN:
+		if list, ok := expr.Rhs.(ast.ExprList); ok {
+			for _, elem := range list {
+				t := self.bound.Types[elem]
+				if !comparable(lhs.Class, t.Class) {
+					return Type{}, incompatible(elem.Pos(), expr.Op, lhs.Class, t.Class)
+				}
+				if _, err = mixCollation(elem.Pos(), expr.Op.String(), lhs.Collate,
+					t.Collate); err != nil {
+					return Type{}, err
+				}
+			}
+		} else if !comparable(lhs.Class, rhs.Class) {
+			return Type{}, incompatible(expr.Pos(), expr.Op, lhs.Class, rhs.Class)
+		} else if _, err = mixCollation(expr.Pos(), expr.Op.String(), lhs.Collate,
+			rhs.Collate); err != nil {
+			return Type{}, err
+		}
+		return Type{Class: function.TYPE_BOOL, Nullable: true}, nil
+
+	default: // AND, OR
+		return Type{Class: function.TYPE_BOOL, Nullable: nullable}, nil
+	}
+}
+
+// Like MySQL, strings are converted to REAL, and INT / INT is DECIMAL.
+func arithmetic(pos int, op token.Token, a, b function.Type) (function.Type, error) {
+	if a == function.TYPE_INTERVAL || b == function.TYPE_INTERVAL {
+		return dateArithmetic(pos, op, a, b)
+	}
+
+	class := function.TYPE_ANY
+	for _, c := range []function.Type{a, b} {
+		switch {
+		case c == function.TYPE_ANY:
+		case c == function.TYPE_STRING:
+			class = function.TYPE_REAL
+		case isNumeric(c):
+			class = widen(class, c)
+		default:
+			return function.TYPE_ANY, incompatible(pos, op, a, b)
+		}
+	}
+
+	if op == token.SLASH && class != function.TYPE_REAL {
+		return function.TYPE_DECIMAL, nil
+	}
+	if class == function.TYPE_BOOL {
+		return function.TYPE_INT, nil
+	}
+	return class, nil
+}
+
+// date + INTERVAL, INTERVAL + date and date - INTERVAL are DATETIME, strings
+// and numbers are converted to date.
+func dateArithmetic(pos int, op token.Token, a, b function.Type) (function.Type, error) {
+	date, interval := a, b
+	if op == token.PLUS && a == function.TYPE_INTERVAL {
+		date, interval = b, a
+	}
+	switch {
+	case op != token.PLUS && op != token.MINUS, interval != function.TYPE_INTERVAL:
+		return function.TYPE_ANY, incompatible(pos, op, a, b)
+	case date == function.TYPE_ANY || date == function.TYPE_DATETIME ||
+		date == function.TYPE_STRING || date == function.TYPE_INT:
+		return function.TYPE_DATETIME, nil
+	default:
+		return function.TYPE_ANY, incompatible(pos, op, a, b)
+	}
+}
+
+func incompatible(pos int, op token.Token, a, b function.Type) error {
+	return fmt.Errorf("[%d] Incompatible operands for %s: %s and %s", pos, op, a, b)
+}
+
+func (self *checker) inferCall(call *ast.CallExpr) (Type, error) {
+	_, name := call.Func.Dequote()
+	name = strings.ToUpper(name)
+
+	args := make([]Type, len(call.Args))
+	nullable := false
+	for i, arg := range call.Args {
+		t, err := self.typeOf(arg)
+		if err != nil {
+			return Type{}, err
+		}
+		args[i] = t
+		nullable = nullable || t.Nullable
+	}
+
+	fn, found := self.reg.Lookup(name)
+	if !found {
+		return Type{}, fmt.Errorf("[%d] Unknown function %s()", call.Pos(), name)
+	}
+	if !fn.CheckArity(len(call.Args)) {
+		return Type{}, fmt.Errorf("[%d] Function %s() takes %s argument(s), but %d given",
+			call.Pos(), name, fn.Arity(), len(call.Args))
+	}
+	for i, t := range args {
+		if want := fn.ArgType(i); !assignable(want, t.Class) {
+			return Type{}, fmt.Errorf("[%d] Incompatible argument %d of %s(): %s, expected %s",
+				call.Args[i].Pos(), i+1, name, t.Class, want)
+		}
+	}
+
+	t := Type{Class: fn.Return, Nullable: nullable || fn.Aggregate || fn.Window}
+	if fn.Return == function.TYPE_ARG {
+		// Result of all `any' arguments: COALESCE(a, b), LAG(a, 1, b) ...
+		t.Class = function.TYPE_ANY
+		for i, arg := range args {
+			if fn.ArgType(i) != function.TYPE_ANY {
+				continue
+			}
+			class, ok := unify(t.Class, arg.Class)
+			if !ok {
+				return Type{}, fmt.Errorf("[%d] Incompatible argument %d of %s(): %s and %s",
+					call.Args[i].Pos(), i+1, name, t.Class, arg.Class)
+			}
+			t.Class = class
+		}
+	}
+
+	if t.Class == function.TYPE_STRING {
+		var err error
+		for i, arg := range args {
+			if arg.Class != function.TYPE_STRING {
+				continue
+			}
+			if t.Collate, err = mixCollation(call.Args[i].Pos(), name+"()", t.Collate,
+				arg.Collate); err != nil {
+				return Type{}, err
+			}
+		}
+	}
+
+	switch name {
+	case "COUNT":
+		t.Nullable = false
+	case "DATE_ADD", "DATE_SUB":
+		t.Nullable = true // NULL if date is out of range
+	case "COALESCE", "IFNULL":
+		t.Nullable = true
+		for _, arg := range args {
+			t.Nullable = t.Nullable && arg.Nullable
+		}
+	}
+	return t, nil
+}
+
+func (self *checker) inferCondition(cond *ast.Condition) (Type, error) {
+	var test Type
+	var err error
+	if cond.Case != nil {
+		if test, err = self.typeOf(cond.Case); err != nil {
+			return Type{}, err
+		}
+	}
+
+	result := Type{Nullable: cond.Else == nil}
+	branch := func(expr ast.Expr) error {
+		t, err := self.typeOf(expr)
+		if err != nil {
+			return err
+		}
+		class, ok := unify(result.Class, t.Class)
+		if !ok {
+			return fmt.Errorf("[%d] Incompatible types of CASE: %s and %s", expr.Pos(),
+				result.Class, t.Class)
+		}
+		result.Class = class
+		result.Nullable = result.Nullable || t.Nullable
+		result.Collate, err = mixCollation(expr.Pos(), "CASE", result.Collate, t.Collate)
+		return err
+	}
+
+	for _, block := range cond.Blocks {
+		when, err := self.typeOf(block.When)
+		if err != nil {
+			return Type{}, err
+		}
+		if cond.Case != nil && !comparable(test.Class, when.Class) {
+			return Type{}, incompatible(block.When.Pos(), token.EQ, test.Class, when.Class)
+		}
+		if cond.Case != nil {
+			if _, err = mixCollation(block.When.Pos(), "CASE", test.Collate,
+				when.Collate); err != nil {
+				return Type{}, err
+			}
+		}
+		if err = branch(block.Then); err != nil {
+			return Type{}, err
+		}
+	}
+	if cond.Else != nil {
+		if err = branch(cond.Else); err != nil {
+			return Type{}, err
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,153 @@
+package binder
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/emptyland/akino/catalog"
+	"github.com/emptyland/akino/sql/ast"
+	"github.com/emptyland/akino/sql/function"
+	"github.com/emptyland/akino/sql/parser"
+)
+
+func TestCheckSelect(t *testing.T) {
+	cat := newCatalog(t)
+	bound := assertCheck(t, cat, "SELECT id + 1, a / 2, a * 1.5, b, CAST(1 AS DOUBLE), NULL, "+
+		"CAST(b AS INT), COUNT(*), SUM(a), COALESCE(a, 0), a = '1', id IS NULL FROM t")
+	assertTypes(t, bound, "int,decimal null,decimal null,string null,real,any null,"+
+		"int null,int,int null,int,bool null,bool")
+
+	bound = assertCheck(t, cat, "SELECT CASE WHEN a > 0 THEN a ELSE 1.0, "+
+		"CASE a WHEN 1 THEN 'x', -b, a + '1' FROM t")
+	assertTypes(t, bound, "decimal null,string null,real null,real null")
+
+	bound = assertCheck(t, cat, "SELECT * FROM (SELECT id, b AS name FROM t) AS q")
+	assertTypes(t, bound, "int,string null")
+
+	bound = assertCheck(t, cat, "SELECT id FROM t UNION SELECT 1.5 FROM s")
+	assertTypes(t, bound, "decimal")
+
+	bound = assertCheck(t, cat, "SELECT TRUE, FALSE AND a > 0 FROM t WHERE TRUE")
+	assertTypes(t, bound, "bool,bool null")
+
+	bound = assertCheck(t, cat, "SELECT a FROM t WHERE a IN (SELECT c FROM s)")
+	sel := bound.Cmd.(*ast.Select)
+	if typ, found := bound.Type(sel.Where); !found || typ.Class != function.TYPE_BOOL {
+		t.Fatal(typ)
+	}
+}
+
+func TestCheckError(t *testing.T) {
+	cat := newCatalog(t)
+	assertApplySQL(t, cat, "CREATE TABLE d (at DATETIME, data BLOB)")
+
+	assertCheckFail(t, cat, "SELECT at + 1 FROM d", "Incompatible operands for +")
+	assertCheckFail(t, cat, "SELECT * FROM d WHERE data > 1", "Incompatible operands for >")
+	assertCheckFail(t, cat, "SELECT * FROM d WHERE data IN (1, 2)", "Incompatible operands for IN")
+	assertCheckFail(t, cat, "SELECT CASE WHEN 1 THEN data ELSE 0 FROM d",
+		"Incompatible types of CASE")
+	assertCheckFail(t, cat, "SELECT ABS(data) + 1 FROM d", "Incompatible operands for +")
+	assertCheckFail(t, cat, "SELECT SQRT(at) FROM d", "Incompatible argument 1 of SQRT()")
+	assertCheckFail(t, cat, "SELECT FOO(1) FROM d", "Unknown function FOO()")
+	assertCheckFail(t, cat, "SELECT COUNT(at, data) FROM d",
+		"[7] Function COUNT() takes 1 argument(s), but 2 given")
+	assertCheckFail(t, cat, "SELECT ROW_NUMBER(at) FROM d", "Function ROW_NUMBER() takes 0")
+	assertCheckFail(t, cat, "SELECT at FROM d UNION SELECT 1 FROM t", "Incompatible types")
+	assertCheckFail(t, cat, "INSERT INTO d (at) VALUES (1)", "Incompatible type int for column at")
+	assertCheckFail(t, cat, "UPDATE t SET a = at FROM d", "Incompatible type datetime")
+
+	assertCheck(t, cat, "SELECT * FROM d WHERE at > '2020-01-01' AND data = 'x'")
+	assertCheck(t, cat, "SELECT * FROM d WHERE at > 20200101 AND 20200101.5 <> at")
+	assertCheck(t, cat, "INSERT INTO d VALUES ('2020-01-01 00:00:00', NULL)")
+	assertCheck(t, cat, "INSERT INTO t (id, a) VALUES (1, 2) ON DUPLICATE KEY UPDATE a = VALUES(a) + 1")
+	assertCheckFail(t, cat, "INSERT INTO d (at) VALUES (NULL) ON DUPLICATE KEY UPDATE at = VALUES(data)",
+		"Incompatible type bytes for column at")
+}
+
+func TestCheckDateTime(t *testing.T) {
+	cat := newCatalog(t)
+	assertApplySQL(t, cat, "CREATE TABLE d (at DATETIME NOT NULL, n INT NOT NULL)")
+
+	bound := assertCheck(t, cat, "SELECT at + INTERVAL 1 DAY, INTERVAL n HOUR + '2020-01-01', "+
+		"DATE_SUB(at, INTERVAL '1:30' HOUR_MINUTE), DATE '2020-01-02', YEAR(at), "+
+		"CURRENT_TIMESTAMP, NOW() > at FROM d")
+	assertTypes(t, bound, "datetime null,datetime null,datetime null,datetime,int,datetime,bool")
+
+	assertCheckFail(t, cat, "SELECT INTERVAL 1 DAY - at FROM d", "Incompatible operands for -")
+	assertCheckFail(t, cat, "SELECT at * INTERVAL 1 DAY FROM d", "Incompatible operands for *")
+	assertCheckFail(t, cat, "SELECT at + INTERVAL at DAY FROM d", "Incompatible INTERVAL value")
+	assertCheckFail(t, cat, "SELECT INTERVAL 1 DAY = at FROM d", "Incompatible operands for =")
+	assertCheckFail(t, cat, "SELECT DATE_ADD(at, 1) FROM d", "Incompatible argument 2 of DATE_ADD()")
+	assertCheckFail(t, cat, "SELECT YEAR(INTERVAL 1 DAY) FROM d", "Incompatible argument 1 of YEAR()")
+}
+
+func TestCheckCollation(t *testing.T) {
+	cat := newCatalog(t)
+	assertApplySQL(t, cat, "CREATE TABLE c (x TEXT COLLATE nocase, y TEXT COLLATE rtrim, z TEXT, "+
+		"n INT) COLLATE utf8mb4_general_ci")
+
+	bound := assertCheck(t, cat, "SELECT x, UPPER(x), z, COALESCE(NULL, y), n, 'a', "+
+		"CASE WHEN n > 0 THEN x ELSE 'b' FROM c WHERE x = 'A' AND y IN ('a', 'b')")
+	q := bound.Queries[bound.Cmd.(ast.Query)]
+	for i, expected := range []string{"nocase", "nocase", "utf8mb4_general_ci", "rtrim", "", "",
+		"nocase"} {
+		if q.Types[i].Collate != expected {
+			t.Fatalf("column %d: unexpected collation %s", i+1, q.Types[i].Collate)
+		}
+	}
+	sel := bound.Cmd.(*ast.Select)
+	if where := sel.Where.(*ast.BinaryExpr).Lhs.(*ast.BinaryExpr); bound.Collation(where.Lhs) !=
+		"nocase" || bound.Collation(where) != "" {
+		t.Fatal(bound.Collation(where.Lhs), bound.Collation(where))
+	}
+	assertCheck(t, cat, "SELECT * FROM c, t WHERE x = b")
+	assertCheck(t, cat, "SELECT x FROM c UNION SELECT b FROM t")
+
+	assertCheckFail(t, cat, "SELECT * FROM c WHERE x = y",
+		"Illegal mix of collations (nocase) and (rtrim) for operation '='")
+	assertCheckFail(t, cat, "SELECT * FROM c WHERE x IN ('a', z)", "Illegal mix of collations")
+	assertCheckFail(t, cat, "SELECT CONCAT(x, y) FROM c", "for operation 'CONCAT()'")
+	assertCheckFail(t, cat, "SELECT CASE WHEN n THEN x ELSE y FROM c", "for operation 'CASE'")
+	assertCheckFail(t, cat, "SELECT x FROM c UNION SELECT y FROM c", "for operation 'UNION'")
+}
+
+func assertCheck(t *testing.T, cat *catalog.Catalog, sql string) *Bound {
+	bound := assertBind(t, cat, sql)
+	if err := Check(bound, function.Builtin()); err != nil {
+		t.Fatal(sql, err)
+	}
+	return bound
+}
+
+func assertCheckFail(t *testing.T, cat *catalog.Catalog, sql, msg string) {
+	bound := assertBind(t, cat, sql)
+	err := Check(bound, function.Builtin())
+	if err == nil {
+		t.Fatal("Should be fail:", sql)
+	}
+	if !strings.Contains(err.Error(), msg) {
+		t.Fatal(sql, err)
+	}
+	t.Log(err)
+}
+
+func assertTypes(t *testing.T, bound *Bound, expected string) {
+	q := bound.Queries[bound.Cmd.(ast.Query)]
+	types := make([]string, 0, len(q.Types))
+	for _, typ := range q.Types {
+		types = append(types, typ.String())
+	}
+	if strings.Join(types, ",") != expected {
+		t.Fatal(strings.Join(types, ","))
+	}
+}
+
+func assertApplySQL(t *testing.T, cat *catalog.Catalog, sql string) {
+	cmd, err := parser.ParseCommand(sql)
+	if err != nil {
+		t.Fatal(sql, err)
+	}
+	if err = cat.Apply(cmd); err != nil {
+		t.Fatal(sql, err)
+	}
+}
@@ -0,0 +1,457 @@
+package catalog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/emptyland/akino/sql/ast"
+	"github.com/emptyland/akino/sql/collate"
+	"github.com/emptyland/akino/sql/datetime"
+	"github.com/emptyland/akino/sql/decimal"
+	"github.com/emptyland/akino/sql/token"
+)
+
+const MAIN_DATABASE = "main"
+
+// Catalog is the source of truth of schema, it is built by DDL commands.
+//
+// All names are case-insensitive.
+type Catalog struct {
+	Current    string            // Database for unqualified names
+	Collations *collate.Registry // Collations can be declared in schema
+	databases  map[string]*Database
+}
+
+type Database struct {
+	Name    string
+	Charset string
+	Collate string
+	tables  map[string]*Table
+	indexes map[string]*Index
+}
+
+type Table struct {
+	Database    string
+	Name        string
+	Temp        bool
+	Columns     []*Column
+	PrimaryKey  []string
+	Unique      [][]string
+	ForeignKey  []ast.ForeignKey
+	Check       []ast.Expr
+	Constraint  []ast.Constraint
+	Indexes     []*Index
+	Options     ast.TableOptions
+	Template    ast.Query // CREATE TABLE ... AS SELECT, no columns before binding
+	columnIndex map[string]int
+	dbCollate   string // Default collation of database
+}
+
+type Column struct {
+	Name       string
+	Ordinal    int
+	Type       ast.Type
+	NotNull    bool
+	PrimaryKey bool
+	Unique     bool
+	AutoIncr   bool
+	Default    ast.Expr
+	Collate    string
+	Comment    string
+}
+
+type Index struct {
+	Database string
+	Name     string
+	Table    string
+	Unique   bool
+	Columns  []ast.IndexDefine
+}
+
+func New() *Catalog {
+	cat := &Catalog{
+		Current:    MAIN_DATABASE,
+		Collations: collate.Builtin(),
+		databases:  make(map[string]*Database),
+	}
+	cat.databases[MAIN_DATABASE] = newDatabase(MAIN_DATABASE)
+	return cat
+}
+
+func newDatabase(name string) *Database {
+	return &Database{
+		Name:    name,
+		tables:  make(map[string]*Table),
+		indexes: make(map[string]*Index),
+	}
+}
+
+func key(name string) string {
+	return strings.ToLower(name)
+}
+
+//------------------------------------------------------------------------------
+// Lookup
+//------------------------------------------------------------------------------
+func (self *Catalog) Database(name string) (*Database, bool) {
+	db, found := self.databases[key(name)]
+	return db, found
+}
+
+func (self *Catalog) Databases() []*Database {
+	list := make([]*Database, 0, len(self.databases))
+	for _, db := range self.databases {
+		list = append(list, db)
+	}
+	return list
+}
+
+// Lookup table by `db.table' or `table' in the current database.
+func (self *Catalog) LookupTable(name ast.NameRef) (*Table, bool) {
+	db, found := self.database(&name)
+	if !found {
+		return nil, false
+	}
+	return db.Table(name.Table())
+}
+
+// Lookup index by `db.index' or `index' in the current database.
+func (self *Catalog) LookupIndex(name ast.NameRef) (*Index, bool) {
+	db, found := self.database(&name)
+	if !found {
+		return nil, false
+	}
+	return db.Index(name.Table())
+}
+
+func (self *Catalog) database(name *ast.NameRef) (*Database, bool) {
+	if name.Database() == "" {
+		return self.Database(self.Current)
+	}
+	return self.Database(name.Database())
+}
+
+func (self *Database) Table(name string) (*Table, bool) {
+	table, found := self.tables[key(name)]
+	return table, found
+}
+
+func (self *Database) Index(name string) (*Index, bool) {
+	index, found := self.indexes[key(name)]
+	return index, found
+}
+
+func (self *Table) Column(name string) (*Column, bool) {
+	if i, found := self.columnIndex[key(name)]; found {
+		return self.Columns[i], true
+	}
+	return nil, false
+}
+
+func (self *Table) Full() string {
+	return self.Database + "." + self.Name
+}
+
+// Collation of column is inherited from table and database, it is empty if no
+// one is declared.
+func (self *Table) Collation(col *Column) string {
+	switch {
+	case col.Collate != "":
+		return col.Collate
+	case self.Options.Collate != "":
+		return self.Options.Collate
+	default:
+		return self.dbCollate
+	}
+}
+
+// Collation of index column is the collation of column if it is not declared.
+func (self *Table) IndexCollation(def *ast.IndexDefine) string {
+	if def.Collate != "" {
+		return def.Collate
+	}
+	if col, found := self.Column(def.Name); found {
+		return self.Collation(col)
+	}
+	return ""
+}
+
+//------------------------------------------------------------------------------
+// DDL
+//------------------------------------------------------------------------------
+
+// Apply a command to catalog, commands which do not change schema will be
+// ignored.
+func (self *Catalog) Apply(cmd ast.Command) error {
+	switch node := cmd.(type) {
+	case *ast.CreateDatabase:
+		return self.createDatabase(node)
+
+	case *ast.DropDatabase:
+		return self.dropDatabase(node.Pos(), node.Name, node.IfExists)
+
+	case *ast.Attach:
+		return self.createDatabase(&ast.CreateDatabase{CreatePos: node.Pos(), Name: node.Name})
+
+	case *ast.Detach:
+		return self.dropDatabase(node.Pos(), node.Name, false)
+
+	case *ast.Use:
+		if _, found := self.Database(node.Name); !found {
+			return fmt.Errorf("[%d] Unknown database %s", node.Pos(), node.Name)
+		}
+		self.Current = node.Name
+		return nil
+
+	case *ast.CreateTable:
+		return self.createTable(node)
+
+	case *ast.CreateIndex:
+		return self.createIndex(node)
+
+	default:
+		return nil
+	}
+}
+
+func (self *Catalog) createDatabase(cmd *ast.CreateDatabase) error {
+	if _, found := self.Database(cmd.Name); found {
+		if cmd.IfNotExists {
+			return nil
+		}
+		return fmt.Errorf("[%d] Database %s already exists", cmd.Pos(), cmd.Name)
+	}
+
+	db := newDatabase(cmd.Name)
+	db.Charset = cmd.Options.Charset
+	db.Collate = cmd.Options.Collate
+	self.databases[key(cmd.Name)] = db
+	return nil
+}
+
+func (self *Catalog) dropDatabase(pos int, name string, ifExists bool) error {
+	if _, found := self.Database(name); !found {
+		if ifExists {
+			return nil
+		}
+		return fmt.Errorf("[%d] Unknown database %s", pos, name)
+	}
+
+	delete(self.databases, key(name))
+	if key(self.Current) == key(name) {
+		self.Current = ""
+	}
+	return nil
+}
+
+func (self *Catalog) createTable(cmd *ast.CreateTable) error {
+	db, found := self.database(&cmd.Table)
+	if !found {
+		return fmt.Errorf("[%d] Unknown database %s", cmd.Pos(), cmd.Table.Database())
+	}
+	if _, found = db.Table(cmd.Table.Table()); found {
+		if cmd.IfNotExists {
+			return nil
+		}
+		return fmt.Errorf("[%d] Table %s already exists", cmd.Pos(), cmd.Table.Full())
+	}
+
+	table := &Table{
+		Database:    db.Name,
+		Name:        cmd.Table.Table(),
+		Temp:        cmd.Temp,
+		ForeignKey:  cmd.ForeignKey,
+		Check:       cmd.CheckConstraint,
+		Constraint:  cmd.Constraint,
+		Options:     cmd.Options,
+		Template:    cmd.Template,
+		columnIndex: make(map[string]int),
+		dbCollate:   db.Collate,
+	}
+	if err := self.checkCollation(cmd.Pos(), cmd.Options.Collate); err != nil {
+		return err
+	}
+	for i := range cmd.Scheme {
+		def := &cmd.Scheme[i]
+		if _, found = table.columnIndex[key(def.Name)]; found {
+			return fmt.Errorf("[%d] Duplicated column %s in table %s", cmd.Pos(), def.Name,
+				table.Name)
+		}
+		if err := checkType(&def.ColumnType); err != nil {
+			return fmt.Errorf("[%d] %v for column %s", cmd.Pos(), err, def.Name)
+		}
+		if err := self.checkCollation(cmd.Pos(), def.Collate); err != nil {
+			return err
+		}
+		table.columnIndex[key(def.Name)] = len(table.Columns)
+		table.Columns = append(table.Columns, &Column{
+			Name:       def.Name,
+			Ordinal:    i,
+			Type:       def.ColumnType,
+			NotNull:    def.NotNull || def.PrimaryKey,
+			PrimaryKey: def.PrimaryKey,
+			Unique:     def.Unique,
+			AutoIncr:   def.AutoIncr,
+			Default:    def.Default,
+			Collate:    def.Collate,
+			Comment:    def.Comment,
+		})
+		if def.PrimaryKey {
+			table.PrimaryKey = append(table.PrimaryKey, def.Name)
+		}
+	}
+
+	// Keys of table constraints are in the declared order: PRIMARY KEY (b, a)
+	for _, c := range cmd.Constraint {
+		switch {
+		case c.Kind == token.PRIMARY && len(c.Column) > 0:
+			table.PrimaryKey = c.Column
+		case c.Kind == token.UNIQUE:
+			table.Unique = append(table.Unique, c.Column)
+		}
+	}
+	for _, col := range table.Columns {
+		if col.Unique && !table.inUnique(col.Name) {
+			table.Unique = append(table.Unique, []string{col.Name})
+		}
+	}
+	if err := table.checkColumns(cmd.Pos(), table.PrimaryKey); err != nil {
+		return err
+	}
+	for _, fk := range table.ForeignKey {
+		if err := table.checkColumns(cmd.Pos(), fk.Column); err != nil {
+			return err
+		}
+	}
+
+	db.tables[key(table.Name)] = table
+	return nil
+}
+
+func (self *Catalog) checkCollation(pos int, name string) error {
+	if _, found := self.Collations.Lookup(name); !found {
+		return fmt.Errorf("[%d] Unknown collation: '%s'", pos, name)
+	}
+	return nil
+}
+
+// Precision and scale of DECIMAL(M, D) and fractional seconds precision of
+// DATETIME(fsp), TIMESTAMP(fsp) and TIME(fsp) must be in range.
+func checkType(t *ast.Type) error {
+	switch t.Kind {
+	case token.DATETIME, token.TIMESTAMP, token.TIME:
+		if t.Width == nil {
+			return nil
+		}
+		fsp, _ := strconv.Atoi(t.Width.Value)
+		return datetime.CheckFsp(fsp)
+
+	case token.DECIMAL:
+		precision, scale := decimal.DEFAULT_PRECISION, 0
+		if t.Width != nil {
+			precision, _ = strconv.Atoi(t.Width.Value)
+		}
+		if t.Decimal != nil {
+			scale, _ = strconv.Atoi(t.Decimal.Value)
+		}
+		return decimal.CheckType(precision, scale)
+	}
+	return nil
+}
+
+func (self *Table) inUnique(name string) bool {
+	for _, unique := range self.Unique {
+		for _, col := range unique {
+			if key(col) == key(name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (self *Table) checkColumns(pos int, name []string) error {
+	if self.Template != nil {
+		return nil
+	}
+	for _, col := range name {
+		if _, found := self.Column(col); !found {
+			return fmt.Errorf("[%d] Unknown column %s in table %s", pos, col, self.Name)
+		}
+	}
+	return nil
+}
+
+func (self *Catalog) createIndex(cmd *ast.CreateIndex) error {
+	db, found := self.database(&cmd.Name)
+	if !found {
+		return fmt.Errorf("[%d] Unknown database %s", cmd.Pos(), cmd.Name.Database())
+	}
+	if _, found = db.Index(cmd.Name.Table()); found {
+		if cmd.IfNotExists {
+			return nil
+		}
+		return fmt.Errorf("[%d] Index %s already exists", cmd.Pos(), cmd.Name.Full())
+	}
+
+	table, found := db.Table(cmd.Table)
+	if !found {
+		return fmt.Errorf("[%d] Unknown table %s", cmd.Pos(), cmd.Table)
+	}
+	for _, idx := range cmd.Index {
+		if err := table.checkColumns(cmd.Pos(), []string{idx.Name}); err != nil {
+			return err
+		}
+	}
+
+	index := &Index{
+		Database: db.Name,
+		Name:     cmd.Name.Table(),
+		Table:    table.Name,
+		Unique:   cmd.Unique,
+		Columns:  cmd.Index,
+	}
+	db.indexes[key(index.Name)] = index
+	table.Indexes = append(table.Indexes, index)
+	return nil
+}
+
+// For DROP TABLE, drop all indexes of table as well.
+func (self *Catalog) DropTable(name ast.NameRef) error {
+	db, found := self.database(&name)
+	if !found {
+		return fmt.Errorf("Unknown database %s", name.Database())
+	}
+	table, found := db.Table(name.Table())
+	if !found {
+		return fmt.Errorf("Unknown table %s", name.Full())
+	}
+	for _, index := range table.Indexes {
+		delete(db.indexes, key(index.Name))
+	}
+	delete(db.tables, key(table.Name))
+	return nil
+}
+
+// For DROP INDEX.
+func (self *Catalog) DropIndex(name ast.NameRef) error {
+	db, found := self.database(&name)
+	if !found {
+		return fmt.Errorf("Unknown database %s", name.Database())
+	}
+	index, found := db.Index(name.Table())
+	if !found {
+		return fmt.Errorf("Unknown index %s", name.Full())
+	}
+	if table, found := db.Table(index.Table); found {
+		for i, elem := range table.Indexes {
+			if elem == index {
+				table.Indexes = append(table.Indexes[:i], table.Indexes[i+1:]...)
+				break
+			}
+		}
+	}
+	delete(db.indexes, key(index.Name))
+	return nil
+}
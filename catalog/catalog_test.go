@@ -0,0 +1,203 @@
+package catalog
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/emptyland/akino/sql/ast"
+	"github.com/emptyland/akino/sql/parser"
+	"github.com/emptyland/akino/sql/token"
+)
+
+func TestCreateTable(t *testing.T) {
+	cat := New()
+	assertApply(t, cat, `CREATE TABLE t (
+		id INT PRIMARY KEY AUTOINCR,
+		name VARCHAR(32) NOT NULL UNIQUE,
+		a INT, b INT,
+		CONSTRAINT uk_ab UNIQUE (a, b)
+	)`)
+
+	table, found := cat.LookupTable(ast.NameRef{First: "T"})
+	if !found || table.Database != MAIN_DATABASE {
+		t.Fatal(table)
+	}
+	if len(table.Columns) != 4 || len(table.PrimaryKey) != 1 || table.PrimaryKey[0] != "id" {
+		t.Fatal(table.Columns, table.PrimaryKey)
+	}
+	if len(table.Unique) != 2 || len(table.Unique[0]) != 2 || table.Unique[1][0] != "name" {
+		t.Fatal(table.Unique)
+	}
+
+	col, found := table.Column("NAME")
+	if !found || col.Ordinal != 1 || !col.NotNull || col.Type.Kind != token.VARCHAR {
+		t.Fatal(col)
+	}
+	if col, _ = table.Column("id"); !col.AutoIncr || !col.NotNull {
+		t.Fatal(col)
+	}
+
+	if _, found = cat.LookupTable(ast.NameRef{First: "main", Second: "t"}); !found {
+		t.Fatal("fail")
+	}
+	if _, found = cat.LookupTable(ast.NameRef{First: "u"}); found {
+		t.Fatal("fail")
+	}
+}
+
+func TestCreateTableKey(t *testing.T) {
+	cat := New()
+	assertApply(t, cat, "CREATE TABLE t (a INT, b INT, c INT, PRIMARY KEY (b, a), UNIQUE (c, a))")
+	table, _ := cat.LookupTable(ast.NameRef{First: "t"})
+	if fmt.Sprint(table.PrimaryKey) != "[b a]" || fmt.Sprint(table.Unique) != "[[c a]]" {
+		t.Fatal(table.PrimaryKey, table.Unique)
+	}
+	if col, _ := table.Column("a"); !col.PrimaryKey || !col.NotNull {
+		t.Fatal(col)
+	}
+
+	assertApply(t, cat, "CREATE TABLE u (a INT, b INT, c INT UNIQUE, UNIQUE (b, a), UNIQUE (b))")
+	table, _ = cat.LookupTable(ast.NameRef{First: "u"})
+	if len(table.PrimaryKey) != 0 || fmt.Sprint(table.Unique) != "[[b a] [b] [c]]" {
+		t.Fatal(table.PrimaryKey, table.Unique)
+	}
+}
+
+func TestCreateTableError(t *testing.T) {
+	cat := New()
+	assertApply(t, cat, "CREATE TABLE t (a INT)")
+	assertApply(t, cat, "CREATE TABLE IF NOT EXISTS t (b INT)")
+
+	assertFail(t, cat, "CREATE TABLE t (a INT)")
+	assertFail(t, cat, "CREATE TABLE u (a INT, A INT)")
+	assertFail(t, cat, "CREATE TABLE u (a INT, FOREIGN KEY (b) REFERENCES t (a))")
+	assertFail(t, cat, "CREATE TABLE nodb.u (a INT)")
+	assertFail(t, cat, "CREATE TABLE u (a DECIMAL(66, 2))")
+	assertFail(t, cat, "CREATE TABLE u (a DECIMAL(5, 6))")
+	assertApply(t, cat, "CREATE TABLE u (a DECIMAL(65, 30), b DECIMAL, c DECIMAL(5))")
+	assertFail(t, cat, "CREATE TABLE v (a DATETIME(7))")
+	assertFail(t, cat, "CREATE TABLE v (a TIME(10))")
+	assertApply(t, cat, "CREATE TABLE v (a DATETIME(6), b TIMESTAMP(3), c TIME, d DATE)")
+	assertFail(t, cat, "CREATE TABLE w (a TEXT COLLATE latin1_swedish_ci)")
+	assertFail(t, cat, "CREATE TABLE w (a TEXT) COLLATE latin1_swedish_ci")
+	assertApply(t, cat, "CREATE TABLE w (a TEXT COLLATE NoCase) COLLATE utf8_bin")
+}
+
+func TestDatabase(t *testing.T) {
+	cat := New()
+	assertApply(t, cat, "CREATE DATABASE db CHARSET utf8mb4")
+	assertApply(t, cat, "USE db")
+	assertApply(t, cat, "CREATE TABLE t (a INT)")
+	assertApply(t, cat, "ATTACH 'aux.db' AS aux")
+	assertApply(t, cat, "CREATE TABLE aux.t (b INT)")
+
+	if db, found := cat.Database("DB"); !found || db.Charset != "utf8mb4" {
+		t.Fatal(db)
+	}
+	if table, found := cat.LookupTable(ast.NameRef{First: "t"}); !found || table.Full() != "db.t" {
+		t.Fatal(table)
+	}
+	if table, found := cat.LookupTable(ast.NameRef{First: "aux", Second: "t"}); !found ||
+		table.Columns[0].Name != "b" {
+		t.Fatal(table)
+	}
+	if _, found := cat.LookupTable(ast.NameRef{First: "main", Second: "t"}); found {
+		t.Fatal("fail")
+	}
+
+	assertApply(t, cat, "DETACH aux")
+	assertApply(t, cat, "DROP DATABASE db")
+	assertApply(t, cat, "DROP DATABASE IF EXISTS db")
+	if len(cat.Databases()) != 1 || cat.Current != "" {
+		t.Fatal(cat.Databases(), cat.Current)
+	}
+	assertFail(t, cat, "USE db")
+	assertFail(t, cat, "CREATE TABLE t (a INT)")
+}
+
+func TestIndex(t *testing.T) {
+	cat := New()
+	assertApply(t, cat, "CREATE TABLE t (a INT, b INT)")
+	assertApply(t, cat, "CREATE UNIQUE INDEX idx_ab ON t (a, b DESC)")
+	assertFail(t, cat, "CREATE INDEX idx_ab ON t (a)")
+	assertFail(t, cat, "CREATE INDEX idx_c ON t (c)")
+	assertFail(t, cat, "CREATE INDEX idx_a ON u (a)")
+
+	index, found := cat.LookupIndex(ast.NameRef{First: "idx_ab"})
+	if !found || !index.Unique || index.Table != "t" || len(index.Columns) != 2 {
+		t.Fatal(index)
+	}
+	table, _ := cat.LookupTable(ast.NameRef{First: "t"})
+	if len(table.Indexes) != 1 {
+		t.Fatal(table.Indexes)
+	}
+
+	if err := cat.DropIndex(ast.NameRef{First: "idx_ab"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, found = cat.LookupIndex(ast.NameRef{First: "idx_ab"}); found || len(table.Indexes) != 0 {
+		t.Fatal("fail")
+	}
+
+	assertApply(t, cat, "CREATE INDEX idx_a ON t (a)")
+	if err := cat.DropTable(ast.NameRef{First: "t"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, found = cat.LookupIndex(ast.NameRef{First: "idx_a"}); found {
+		t.Fatal("fail")
+	}
+	if err := cat.DropTable(ast.NameRef{First: "t"}); err == nil {
+		t.Fatal("fail")
+	}
+}
+
+func TestCollation(t *testing.T) {
+	cat := New()
+	assertApply(t, cat, "CREATE DATABASE db COLLATE utf8mb4_bin")
+	assertApply(t, cat, "CREATE TABLE db.t (a TEXT, b TEXT COLLATE nocase) COLLATE rtrim")
+	assertApply(t, cat, "CREATE TABLE db.u (a TEXT)")
+	assertApply(t, cat, "CREATE TABLE v (a TEXT)")
+	assertApply(t, cat, "CREATE INDEX db.idx_ab ON t (a COLLATE utf8mb4_general_ci, b)")
+
+	table, _ := cat.LookupTable(ast.NameRef{First: "db", Second: "t"})
+	for name, expected := range map[string]string{"a": "rtrim", "b": "nocase"} {
+		if col, _ := table.Column(name); table.Collation(col) != expected {
+			t.Fatal(name, table.Collation(col))
+		}
+	}
+	index, _ := cat.LookupIndex(ast.NameRef{First: "db", Second: "idx_ab"})
+	if a, b := table.IndexCollation(&index.Columns[0]),
+		table.IndexCollation(&index.Columns[1]); a != "utf8mb4_general_ci" || b != "nocase" {
+		t.Fatal(a, b)
+	}
+
+	table, _ = cat.LookupTable(ast.NameRef{First: "db", Second: "u"})
+	if table.Collation(table.Columns[0]) != "utf8mb4_bin" {
+		t.Fatal(table.Collation(table.Columns[0]))
+	}
+	table, _ = cat.LookupTable(ast.NameRef{First: "v"})
+	if table.Collation(table.Columns[0]) != "" {
+		t.Fatal(table.Collation(table.Columns[0]))
+	}
+}
+
+func assertApply(t *testing.T, cat *Catalog, sql string) {
+	cmd, err := parser.ParseCommand(sql)
+	if err != nil {
+		t.Fatal(sql, err)
+	}
+	if err = cat.Apply(cmd); err != nil {
+		t.Fatal(sql, err)
+	}
+}
+
+func assertFail(t *testing.T, cat *Catalog, sql string) {
+	cmd, err := parser.ParseCommand(sql)
+	if err != nil {
+		t.Fatal(sql, err)
+	}
+	if err = cat.Apply(cmd); err == nil {
+		t.Fatal("Should be fail:", sql)
+	}
+	t.Log(err)
+}
@@ -0,0 +1,314 @@
+package plan
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/emptyland/akino/sql/ast"
+	"github.com/emptyland/akino/sql/simplify"
+	"github.com/emptyland/akino/sql/token"
+)
+
+// Build the plan tree for a parsed command.
+func Build(cmd ast.Command) (Node, error) {
+	switch node := cmd.(type) {
+	case ast.Query:
+		return buildQuery(node)
+
+	case *ast.Insert:
+		return buildInsert(node)
+
+	case *ast.Update:
+		return buildUpdate(node)
+
+	case *ast.Delete:
+		return buildDelete(node)
+
+	default:
+		return nil, fmt.Errorf("No plan for command: %T", cmd)
+	}
+}
+
+func buildQuery(query ast.Query) (Node, error) {
+	switch cmd := query.(type) {
+	case *ast.Select:
+		return buildSelect(cmd)
+
+	case *ast.Compound:
+		return buildCompound(cmd)
+
+	default:
+		return nil, fmt.Errorf("No plan for query: %T", query)
+	}
+}
+
+//
+// Project -> Select -> Merge -> [Alias ->] Relation
+//
+func buildSelect(cmd *ast.Select) (Node, error) {
+	sel := &Select{
+		Distinct: cmd.Distinct,
+		GroupBy:  cmd.GroupBy,
+		OrderBy:  cmd.OrderBy,
+	}
+
+	if len(cmd.From) > 0 {
+		if from, err := buildFrom(cmd.From); err != nil {
+			return nil, err
+		} else {
+			sel.nodeBase.Children = []Node{from}
+		}
+	}
+	sel.Filter = buildFilter(cmd.Where)
+	sel.Having = buildFilter(cmd.Having)
+
+	var err error
+	if sel.Limit, err = buildLimit(cmd.Limit, cmd.Offset); err != nil {
+		return nil, err
+	}
+
+	return &Project{
+		nodeBase: nodeBase{Children: []Node{sel}},
+		Columns:  buildColumns(cmd.SelColList),
+	}, nil
+}
+
+//
+// Merge (with set operation, ORDER BY and LIMIT of compound) -> [Left, Right]
+//
+func buildCompound(cmd *ast.Compound) (Node, error) {
+	left, err := buildQuery(cmd.Left)
+	if err != nil {
+		return nil, err
+	}
+	right, err := buildQuery(cmd.Right)
+	if err != nil {
+		return nil, err
+	}
+
+	node := &Merge{
+		nodeBase: nodeBase{Children: []Node{left, right}},
+		Op:       cmd.Op,
+		OrderBy:  cmd.OrderBy,
+	}
+	if node.Limit, err = buildLimit(cmd.Limit, cmd.Offset); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func buildFrom(from []ast.Source) (Node, error) {
+	children := make([]Node, 0, len(from))
+	for i := range from {
+		if node, err := buildSource(&from[i]); err != nil {
+			return nil, err
+		} else {
+			children = append(children, node)
+		}
+	}
+	if len(children) == 1 {
+		return children[0], nil
+	}
+	return &Merge{nodeBase: nodeBase{Children: children}, Join: ast.JT_CROSS}, nil
+}
+
+func buildSource(src *ast.Source) (Node, error) {
+	var node Node
+	if ok, join := src.IsJoin(); ok {
+		return buildJoin(join)
+	} else if ok, sub := src.IsSubquery(); ok {
+		var err error
+		if node, err = buildQuery(sub); err != nil {
+			return nil, err
+		}
+	} else {
+		node = buildRelation(src.Table)
+	}
+
+	if src.Alias == "" {
+		return node, nil
+	}
+	return &Alias{
+		nodeBase: nodeBase{Children: []Node{node}},
+		Name:     src.Alias,
+	}, nil
+}
+
+// Join tree: Merge (with join type, USING and ON as filter) -> [Left, Right]
+func buildJoin(join *ast.Join) (Node, error) {
+	left, err := buildSource(&join.Left)
+	if err != nil {
+		return nil, err
+	}
+	right, err := buildSource(&join.Right)
+	if err != nil {
+		return nil, err
+	}
+
+	node := &Merge{
+		nodeBase: nodeBase{Children: []Node{left, right}},
+		Join:     join.Type,
+	}
+	for _, id := range join.Using {
+		_, name := id.Dequote()
+		node.Using = append(node.Using, name)
+	}
+	node.Filter = buildFilter(join.On)
+	return node, nil
+}
+
+func buildRelation(name *ast.NameRef) *Relation {
+	return &Relation{
+		DBName:     name.Database(),
+		SchemaName: name.Table(),
+	}
+}
+
+func buildInsert(cmd *ast.Insert) (Node, error) {
+	node := &Insert{
+		nodeBase:  nodeBase{Children: []Node{buildRelation(&cmd.Dest)}},
+		Returning: buildColumns(cmd.Returning),
+	}
+	if cmd.From != nil {
+		if from, err := buildQuery(cmd.From); err != nil {
+			return nil, err
+		} else {
+			node.nodeBase.Children = append(node.nodeBase.Children, from)
+		}
+	}
+	return node, nil
+}
+
+func buildUpdate(cmd *ast.Update) (Node, error) {
+	node := &Update{
+		Returning: buildColumns(cmd.Returning),
+	}
+
+	var err error
+	if node.nodeBase.Children, err = buildTargets(&cmd.Dest, cmd.Join, cmd.From); err != nil {
+		return nil, err
+	}
+	node.Filter = buildFilter(cmd.Where)
+
+	if node.Limit, err = buildLimit(cmd.Limit, cmd.Offset); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func buildDelete(cmd *ast.Delete) (Node, error) {
+	node := &Delete{
+		Returning: buildColumns(cmd.Returning),
+	}
+
+	var err error
+	if node.nodeBase.Children, err = buildTargets(&cmd.Dest, cmd.Join, cmd.Using); err != nil {
+		return nil, err
+	}
+	node.Filter = buildFilter(cmd.Where)
+
+	if node.Limit, err = buildLimit(cmd.Limit, cmd.Offset); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// The first child is the target of DML, the second one is the other joined
+// tables if they exist.
+func buildTargets(dest *ast.NameRef, join, from []ast.Source) ([]Node, error) {
+	var children []Node
+	if join != nil {
+		if node, err := buildFrom(join); err != nil {
+			return nil, err
+		} else {
+			children = []Node{node}
+		}
+	} else {
+		children = []Node{buildRelation(dest)}
+	}
+
+	if from != nil {
+		if node, err := buildFrom(from); err != nil {
+			return nil, err
+		} else {
+			children = append(children, node)
+		}
+	}
+	return children, nil
+}
+
+func buildColumns(list []ast.SelectColumn) []Column {
+	if list == nil {
+		return nil
+	}
+	columns := make([]Column, 0, len(list))
+	for _, elem := range list {
+		columns = append(columns, Column{
+			Name: columnName(&elem),
+			Expr: simplify.Simplify(elem.SelectExpr),
+		})
+	}
+	return columns
+}
+
+func columnName(col *ast.SelectColumn) string {
+	if col.Alias != "" {
+		return col.Alias
+	}
+
+	switch expr := col.SelectExpr.(type) {
+	case *ast.Identifier:
+		_, name := expr.Dequote()
+		return name
+
+	case *ast.BinaryExpr:
+		if id, ok := expr.Rhs.(*ast.Identifier); ok && expr.Op == token.DOT {
+			_, name := id.Dequote()
+			return name
+		}
+
+	case *ast.Literal:
+		if expr.Kind == token.STAR {
+			return expr.Value
+		}
+	}
+	return ""
+}
+
+// Simplified condition, nil if it is always true.
+func buildFilter(cond ast.Expr) *Filter {
+	if cond == nil {
+		return nil
+	}
+	cond = simplify.SimplifyCond(cond)
+	if simplify.IsTrue(cond) {
+		return nil
+	}
+	return &Filter{Cond: cond}
+}
+
+func buildLimit(limit, offset ast.Expr) (*Limit, error) {
+	if limit == nil {
+		return nil, nil
+	}
+
+	rv := &Limit{}
+	var err error
+	if rv.Limit, err = intValue(limit); err != nil {
+		return nil, err
+	}
+	if offset != nil {
+		if rv.Offset, err = intValue(offset); err != nil {
+			return nil, err
+		}
+	}
+	return rv, nil
+}
+
+func intValue(expr ast.Expr) (int64, error) {
+	lit, ok := expr.(*ast.Literal)
+	if !ok || lit.Kind != token.INT_LITERAL {
+		return 0, fmt.Errorf("[%d] Integer literal expected", expr.Pos())
+	}
+	return strconv.ParseInt(lit.Value, 10, 64)
+}
@@ -0,0 +1,153 @@
+package plan
+
+import (
+	"testing"
+
+	"github.com/emptyland/akino/sql/ast"
+	"github.com/emptyland/akino/sql/parser"
+	"github.com/emptyland/akino/sql/token"
+)
+
+func TestBuildSelect(t *testing.T) {
+	node := assertBuild(t, "SELECT a, t.b AS c FROM db.t AS t WHERE a > 1 LIMIT 10, 5")
+
+	proj, ok := node.(*Project)
+	if !ok {
+		t.Fatalf("%T", node)
+	}
+	if len(proj.Columns) != 2 || proj.Columns[0].Name != "a" || proj.Columns[1].Name != "c" {
+		t.Fatal(proj.Columns)
+	}
+	sel := proj.Children()[0].(*Select)
+	if sel.Filter == nil || sel.Limit == nil || sel.Limit.Limit != 5 || sel.Limit.Offset != 10 {
+		t.Fatal(sel)
+	}
+	alias := sel.Children()[0].(*Alias)
+	if alias.Name != "t" {
+		t.Fatal(alias)
+	}
+	if rel := alias.Children()[0].(*Relation); rel.DBName != "db" || rel.SchemaName != "t" {
+		t.Fatal(rel)
+	}
+}
+
+func TestBuildReturning(t *testing.T) {
+	ins := assertBuild(t, "INSERT INTO t VALUES (1, 2) RETURNING id, n AS num").(*Insert)
+	if len(ins.Returning) != 2 || ins.Returning[0].Name != "id" || ins.Returning[1].Name != "num" {
+		t.Fatal(ins.Returning)
+	}
+
+	upd := assertBuild(t, "UPDATE t SET n = 1 WHERE id = 1 RETURNING *").(*Update)
+	if len(upd.Returning) != 1 || upd.Returning[0].Name != "*" || upd.Filter == nil {
+		t.Fatal(upd.Returning)
+	}
+
+	del := assertBuild(t, "DELETE FROM t WHERE id = 1").(*Delete)
+	if del.Returning != nil {
+		t.Fatal(del.Returning)
+	}
+	if rel := del.Children()[0].(*Relation); rel.SchemaName != "t" {
+		t.Fatal(rel)
+	}
+}
+
+func TestBuildMultiTable(t *testing.T) {
+	upd := assertBuild(t, "UPDATE a JOIN b ON (a.id = b.id) SET a.x = b.y").(*Update)
+	if merge, ok := upd.Children()[0].(*Merge); !ok || len(merge.Children()) != 2 {
+		t.Fatal(upd.Children())
+	}
+
+	del := assertBuild(t, "DELETE FROM a USING b WHERE a.id = b.id").(*Delete)
+	if len(del.Children()) != 2 {
+		t.Fatal(del.Children())
+	}
+	if rel := del.Children()[1].(*Relation); rel.SchemaName != "b" {
+		t.Fatal(rel)
+	}
+}
+
+func assertBuild(t *testing.T, input string) Node {
+	cmd, err := parser.ParseCommand(input)
+	if err != nil {
+		t.Fatalf("%s: %v", input, err)
+	}
+	node, err := Build(cmd)
+	if err != nil {
+		t.Fatalf("%s: %v", input, err)
+	}
+	return node
+}
+
+func TestBuildJoinTree(t *testing.T) {
+	proj := assertBuild(t, "SELECT * FROM (a JOIN b ON a.x = b.x) LEFT JOIN c ON a.y = c.y").(*Project)
+	merge, ok := proj.Children()[0].Children()[0].(*Merge)
+	if !ok || merge.Filter == nil {
+		t.Fatal(proj.Children()[0].Children())
+	}
+	if inner, ok := merge.Children()[0].(*Merge); !ok || len(inner.Children()) != 2 {
+		t.Fatal(merge.Children())
+	}
+	if rel := merge.Children()[1].(*Relation); rel.SchemaName != "c" {
+		t.Fatal(rel)
+	}
+	if merge.Join != ast.JT_LEFT {
+		t.Fatal(merge.Join)
+	}
+	if inner := merge.Children()[0].(*Merge); inner.Join != ast.JT_INNER {
+		t.Fatal(inner.Join)
+	}
+
+	proj = assertBuild(t, "SELECT * FROM a NATURAL JOIN b, c FULL JOIN d USING (x, y)").(*Project)
+	merge = proj.Children()[0].Children()[0].(*Merge)
+	if merge.Join != ast.JT_CROSS || len(merge.Children()) != 2 {
+		t.Fatal(merge.Join, merge.Children())
+	}
+	if natural := merge.Children()[0].(*Merge); natural.Join&ast.JT_NATURAL == 0 {
+		t.Fatal(natural.Join)
+	}
+	full := merge.Children()[1].(*Merge)
+	if full.Join&ast.JT_FULL == 0 || len(full.Using) != 2 || full.Using[1] != "y" {
+		t.Fatal(full.Join, full.Using)
+	}
+}
+
+func TestBuildCompound(t *testing.T) {
+	merge := assertBuild(t, "(SELECT a FROM t) UNION (SELECT a FROM u) LIMIT 3").(*Merge)
+	if merge.Limit == nil || merge.Limit.Limit != 3 || len(merge.Children()) != 2 {
+		t.Fatal(merge)
+	}
+	if _, ok := merge.Children()[1].(*Project); !ok {
+		t.Fatal(merge.Children())
+	}
+	if merge.Op != token.UNION || merge.OrderBy != nil {
+		t.Fatal(merge.Op, merge.OrderBy)
+	}
+
+	merge = assertBuild(t, "SELECT a FROM t EXCEPT SELECT a FROM u INTERSECT SELECT a FROM v "+
+		"ORDER BY a DESC").(*Merge)
+	if merge.Op != token.EXCEPT || len(merge.OrderBy) != 1 || !merge.OrderBy[0].Desc {
+		t.Fatal(merge.Op, merge.OrderBy)
+	}
+	if right := merge.Children()[1].(*Merge); right.Op != token.INTERSECT {
+		t.Fatal(right.Op)
+	}
+	merge = assertBuild(t, "SELECT a FROM t UNION ALL SELECT a FROM u").(*Merge)
+	if merge.Op != token.UNION_ALL {
+		t.Fatal(merge.Op)
+	}
+}
+
+func TestBuildSimplify(t *testing.T) {
+	proj := assertBuild(t, "SELECT 1 + 2 AS n FROM t WHERE a = a OR 1 = 1").(*Project)
+	if lit, ok := proj.Columns[0].Expr.(*ast.Literal); !ok || lit.Value != "3" {
+		t.Fatal(proj.Columns[0].Expr)
+	}
+	if sel := proj.Children()[0].(*Select); sel.Filter != nil {
+		t.Fatal(sel.Filter)
+	}
+
+	del := assertBuild(t, "DELETE FROM t WHERE NOT NOT a AND TRUE").(*Delete)
+	if id, ok := del.Filter.Cond.(*ast.Identifier); !ok || id.Name != "a" {
+		t.Fatal(del.Filter.Cond)
+	}
+}
@@ -0,0 +1,395 @@
+package plan
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/emptyland/akino/sql/ast"
+	"github.com/emptyland/akino/sql/token"
+)
+
+// Explain builds the plan tree of the wrapped command and prints it in the
+// format of EXPLAIN. EXPLAIN QUERY PLAN prints the same tree, EXPLAIN ANALYZE
+// is not supported because commands are not executed.
+func Explain(cmd *ast.Explain) (string, error) {
+	if cmd.Analyze {
+		return "", fmt.Errorf("[%d] EXPLAIN ANALYZE is not supported", cmd.Pos())
+	}
+	node, err := Build(cmd.Cmd)
+	if err != nil {
+		return "", err
+	}
+
+	switch cmd.Format {
+	case token.JSON:
+		return explainJSON(node)
+
+	case token.YAML:
+		return explainYAML(node), nil
+
+	default:
+		return explainText(node), nil
+	}
+}
+
+type explainNode struct {
+	Node     string        `json:"node"`
+	Detail   []string      `json:"detail,omitempty"`
+	Children []explainNode `json:"children,omitempty"`
+}
+
+func makeExplainNode(node Node) explainNode {
+	var rv explainNode
+	var base *nodeBase
+	var clauses []string // Details after filter
+
+	switch n := node.(type) {
+	case *Merge:
+		rv.Node, base = "Merge", &n.nodeBase
+		if n.Op != 0 {
+			rv.Detail = append(rv.Detail, "op: "+n.Op.String())
+		} else {
+			rv.Detail = append(rv.Detail, "join: "+joinName(n.Join))
+		}
+		if n.Using != nil {
+			rv.Detail = append(rv.Detail, "using: "+strings.Join(n.Using, ", "))
+		}
+		if n.OrderBy != nil {
+			rv.Detail = append(rv.Detail, "order by: "+orderByString(n.OrderBy))
+		}
+
+	case *Project:
+		rv.Node, base = "Project", &n.nodeBase
+		rv.Detail = append(rv.Detail, "columns: "+columnNames(n.Columns))
+
+	case *Select:
+		rv.Node, base = "Select", &n.nodeBase
+		if n.Distinct {
+			rv.Detail = append(rv.Detail, "distinct")
+		}
+		if n.GroupBy != nil {
+			clauses = append(clauses, "group by: "+exprListString(n.GroupBy))
+		}
+		if n.Having != nil {
+			clauses = append(clauses, "having: "+exprString(n.Having.Cond))
+		}
+		if n.OrderBy != nil {
+			clauses = append(clauses, "order by: "+orderByString(n.OrderBy))
+		}
+
+	case *Relation:
+		rv.Node, base = "Relation", &n.nodeBase
+		if n.DBName != "" {
+			rv.Detail = append(rv.Detail, "name: "+n.DBName+"."+n.SchemaName)
+		} else {
+			rv.Detail = append(rv.Detail, "name: "+n.SchemaName)
+		}
+
+	case *Alias:
+		rv.Node, base = "Alias", &n.nodeBase
+		rv.Detail = append(rv.Detail, "name: "+n.Name)
+
+	case *Insert:
+		rv.Node, base = "Insert", &n.nodeBase
+		rv.Detail = appendReturning(rv.Detail, n.Returning)
+
+	case *Update:
+		rv.Node, base = "Update", &n.nodeBase
+		rv.Detail = appendReturning(rv.Detail, n.Returning)
+
+	case *Delete:
+		rv.Node, base = "Delete", &n.nodeBase
+		rv.Detail = appendReturning(rv.Detail, n.Returning)
+
+	default:
+		rv.Node = fmt.Sprintf("%T", node)
+		for _, child := range node.Children() {
+			rv.Children = append(rv.Children, makeExplainNode(child))
+		}
+		return rv
+	}
+
+	if base.Filter != nil {
+		rv.Detail = append(rv.Detail, "filter: "+exprString(base.Filter.Cond))
+	}
+	rv.Detail = append(rv.Detail, clauses...)
+	if base.Limit != nil {
+		rv.Detail = append(rv.Detail, "limit: "+strconv.FormatInt(base.Limit.Limit, 10))
+		if base.Limit.Offset != 0 {
+			rv.Detail = append(rv.Detail, "offset: "+strconv.FormatInt(base.Limit.Offset, 10))
+		}
+	}
+	for _, child := range base.Children {
+		rv.Children = append(rv.Children, makeExplainNode(child))
+	}
+	return rv
+}
+
+// Words of join type: LEFT OUTER, NATURAL, CROSS, ...
+func joinName(jt int) string {
+	var words []string
+	for _, t := range []struct {
+		bit  int
+		name string
+	}{
+		{ast.JT_NATURAL, "NATURAL"},
+		{ast.JT_LEFT, "LEFT"},
+		{ast.JT_RIGHT, "RIGHT"},
+		{ast.JT_FULL, "FULL"},
+		{ast.JT_OUTER, "OUTER"},
+		{ast.JT_INNER, "INNER"},
+		{ast.JT_CROSS, "CROSS"},
+		{ast.JT_STRAIGHT, "STRAIGHT_JOIN"},
+	} {
+		if jt&t.bit != 0 {
+			words = append(words, t.name)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+func orderByString(list []ast.OrderByItem) string {
+	items := make([]string, 0, len(list))
+	for _, item := range list {
+		if item.Desc {
+			items = append(items, exprString(item.Item)+" DESC")
+		} else {
+			items = append(items, exprString(item.Item))
+		}
+	}
+	return strings.Join(items, ", ")
+}
+
+func appendReturning(detail []string, returning []Column) []string {
+	if returning == nil {
+		return detail
+	}
+	return append(detail, "returning: "+columnNames(returning))
+}
+
+func columnNames(columns []Column) string {
+	name := make([]string, 0, len(columns))
+	for _, col := range columns {
+		if col.Name == "" {
+			name = append(name, "?")
+		} else {
+			name = append(name, col.Name)
+		}
+	}
+	return strings.Join(name, ", ")
+}
+
+//
+// Project (columns: a, b)
+//   Select (filter: a > 1)
+//     Relation (name: t)
+//
+func explainText(node Node) string {
+	var buf bytes.Buffer
+	writeText(&buf, makeExplainNode(node), 0)
+	return buf.String()
+}
+
+func writeText(buf *bytes.Buffer, node explainNode, depth int) {
+	buf.WriteString(strings.Repeat("  ", depth))
+	buf.WriteString(node.Node)
+	if len(node.Detail) > 0 {
+		buf.WriteString(" (" + strings.Join(node.Detail, ", ") + ")")
+	}
+	buf.WriteString("\n")
+	for _, child := range node.Children {
+		writeText(buf, child, depth+1)
+	}
+}
+
+func explainJSON(node Node) (string, error) {
+	out, err := json.MarshalIndent(makeExplainNode(node), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(out) + "\n", nil
+}
+
+//
+// - node: Project
+//   detail:
+//   - 'columns: a, b'
+//   children:
+//   - node: Select
+//
+func explainYAML(node Node) string {
+	var buf bytes.Buffer
+	writeYAML(&buf, makeExplainNode(node), "")
+	return buf.String()
+}
+
+func writeYAML(buf *bytes.Buffer, node explainNode, indent string) {
+	buf.WriteString(indent + "- node: " + node.Node + "\n")
+	if len(node.Detail) > 0 {
+		buf.WriteString(indent + "  detail:\n")
+		for _, detail := range node.Detail {
+			buf.WriteString(indent + "  - '" + strings.Replace(detail, "'", "''", -1) + "'\n")
+		}
+	}
+	if len(node.Children) > 0 {
+		buf.WriteString(indent + "  children:\n")
+		for _, child := range node.Children {
+			writeYAML(buf, child, indent+"  ")
+		}
+	}
+}
+
+//------------------------------------------------------------------------------
+// Expressions
+//------------------------------------------------------------------------------
+
+// Binding priority of operators, the same as parser. Operands are parenthesized
+// if they bind looser than the operator.
+var exprPrio = map[token.Token]int{
+	token.OR:          1,
+	token.IS_NULL:     1,
+	token.IS_NOT_NULL: 1,
+	token.AND:         2,
+	token.LT:          3,
+	token.LE:          3,
+	token.GT:          3,
+	token.GE:          3,
+	token.EQ:          4,
+	token.NE:          4,
+	token.IN:          5,
+	token.PLUS:        6,
+	token.MINUS:       6,
+	token.STAR:        7,
+	token.SLASH:       7,
+	token.LIKE:        8,
+}
+
+const prefixPrio = 9
+
+// SQL text of expression, like `a > 1 AND b IN (1, 2)'.
+func exprString(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case nil:
+		return ""
+
+	case *ast.Literal:
+		return e.Value
+
+	case *ast.Identifier:
+		return e.Name
+
+	case *ast.PseudoColumn:
+		return e.Row + "." + e.Column.Name
+
+	case *ast.Variable:
+		return "@" + e.Name
+
+	case *ast.UnaryExpr:
+		switch e.Op {
+		case token.IS_NULL, token.IS_NOT_NULL:
+			return operand(e.Operand, exprPrio[e.Op], false) + " " + e.Op.String()
+		case token.MINUS:
+			if s := operand(e.Operand, prefixPrio, false); !strings.HasPrefix(s, "-") {
+				return "-" + s
+			}
+			return "-(" + exprString(e.Operand) + ")" // Not a comment: --1
+		default:
+			return e.Op.String() + " " + operand(e.Operand, prefixPrio, false)
+		}
+
+	case *ast.BinaryExpr:
+		if e.Op == token.DOT {
+			return exprString(e.Lhs) + "." + exprString(e.Rhs)
+		}
+		prio := exprPrio[e.Op]
+		return operand(e.Lhs, prio, false) + " " + e.Op.String() + " " +
+			operand(e.Rhs, prio, true)
+
+	case ast.ExprList:
+		return "(" + exprListString(e) + ")"
+
+	case *ast.CallExpr:
+		if e.Distinct {
+			return e.Func.Name + "(DISTINCT " + exprListString(e.Args) + ")"
+		}
+		return e.Func.Name + "(" + exprListString(e.Args) + ")"
+
+	case *ast.Condition:
+		var buf bytes.Buffer
+		buf.WriteString("CASE")
+		if e.Case != nil {
+			buf.WriteString(" " + caseOperand(e.Case))
+		}
+		for _, block := range e.Blocks {
+			buf.WriteString(" WHEN " + caseOperand(block.When) + " THEN " +
+				caseOperand(block.Then))
+		}
+		if e.Else != nil {
+			buf.WriteString(" ELSE " + caseOperand(e.Else))
+		}
+		return buf.String()
+
+	case *ast.CastExpr:
+		name := e.To.Kind.String()
+		if e.To.Width != nil && e.To.Decimal != nil {
+			name += "(" + e.To.Width.Value + ", " + e.To.Decimal.Value + ")"
+		} else if e.To.Width != nil {
+			name += "(" + e.To.Width.Value + ")"
+		}
+		return "CAST(" + exprString(e.Operand) + " AS " + name + ")"
+
+	case *ast.IntervalExpr:
+		return "INTERVAL " + operand(e.Value, prefixPrio, false) + " " + e.Unit
+
+	case ast.Query:
+		return "(subquery)"
+
+	default:
+		return fmt.Sprintf("%T", expr)
+	}
+}
+
+// CASE has no END, a nested CASE is parenthesized, otherwise it takes the
+// following WHEN and ELSE of the outer one.
+func caseOperand(expr ast.Expr) string {
+	if _, ok := expr.(*ast.Condition); ok {
+		return "(" + exprString(expr) + ")"
+	}
+	return exprString(expr)
+}
+
+// Operand of operator in priority prio, the right operand of the same
+// priority is parenthesized because operators are left associative. CASE is
+// always parenthesized since its last expression takes following operators.
+func operand(expr ast.Expr, prio int, rhs bool) string {
+	inner := prefixPrio + 1
+	switch e := expr.(type) {
+	case *ast.Condition:
+		inner = 0
+	case *ast.BinaryExpr:
+		if e.Op != token.DOT {
+			inner = exprPrio[e.Op]
+		}
+	case *ast.UnaryExpr:
+		if e.Op == token.IS_NULL || e.Op == token.IS_NOT_NULL {
+			inner = exprPrio[e.Op]
+		} else {
+			inner = prefixPrio
+		}
+	}
+	if inner < prio || (rhs && inner == prio) {
+		return "(" + exprString(expr) + ")"
+	}
+	return exprString(expr)
+}
+
+func exprListString(list []ast.Expr) string {
+	items := make([]string, 0, len(list))
+	for _, expr := range list {
+		items = append(items, exprString(expr))
+	}
+	return strings.Join(items, ", ")
+}
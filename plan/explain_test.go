@@ -0,0 +1,157 @@
+package plan
+
+import (
+	"testing"
+
+	"github.com/emptyland/akino/sql/ast"
+	"github.com/emptyland/akino/sql/parser"
+)
+
+func TestExplainText(t *testing.T) {
+	out := assertExplain(t, "EXPLAIN SELECT a, b FROM db.t AS x WHERE a > 1 LIMIT 5")
+	exp := `Project (columns: a, b)
+  Select (filter: a > 1, limit: 5)
+    Alias (name: x)
+      Relation (name: db.t)
+`
+	if out != exp {
+		t.Fatalf("Expected:\n%s\nValue is:\n%s", exp, out)
+	}
+}
+
+func TestExplainSelect(t *testing.T) {
+	out := assertExplain(t, "EXPLAIN SELECT DISTINCT a, COUNT(*) FROM t WHERE a = 1 GROUP BY a "+
+		"HAVING COUNT(*) > 1 ORDER BY a LIMIT 3")
+	exp := `Project (columns: a, ?)
+  Select (distinct, filter: a = 1, group by: a, having: COUNT(*) > 1, order by: a, limit: 3)
+    Relation (name: t)
+`
+	if out != exp {
+		t.Fatalf("Expected:\n%s\nValue is:\n%s", exp, out)
+	}
+}
+
+func TestExplainJSON(t *testing.T) {
+	out := assertExplain(t, "EXPLAIN FORMAT = JSON DELETE FROM t RETURNING id")
+	exp := `{
+  "node": "Delete",
+  "detail": [
+    "returning: id"
+  ],
+  "children": [
+    {
+      "node": "Relation",
+      "detail": [
+        "name: t"
+      ]
+    }
+  ]
+}
+`
+	if out != exp {
+		t.Fatalf("Expected:\n%s\nValue is:\n%s", exp, out)
+	}
+}
+
+func TestExplainYAML(t *testing.T) {
+	out := assertExplain(t, "EXPLAIN FORMAT = YAML INSERT INTO t SELECT * FROM u")
+	exp := `- node: Insert
+  children:
+  - node: Relation
+    detail:
+    - 'name: t'
+  - node: Project
+    detail:
+    - 'columns: *'
+    children:
+    - node: Select
+      children:
+      - node: Relation
+        detail:
+        - 'name: u'
+`
+	if out != exp {
+		t.Fatalf("Expected:\n%s\nValue is:\n%s", exp, out)
+	}
+}
+
+func TestExplainMerge(t *testing.T) {
+	out := assertExplain(t, "EXPLAIN SELECT * FROM a LEFT JOIN b USING (x), c "+
+		"WHERE (a.y = 1 OR -b.y > 2 * (c.z - 1)) AND c.w IS NULL")
+	exp := `Project (columns: *)
+  Select (filter: (a.y = 1 OR -b.y > 2 * (c.z - 1)) AND c.w IS NULL)
+    Merge (join: CROSS)
+      Merge (join: LEFT, using: x)
+        Relation (name: a)
+        Relation (name: b)
+      Relation (name: c)
+`
+	if out != exp {
+		t.Fatalf("Expected:\n%s\nValue is:\n%s", exp, out)
+	}
+
+	out = assertExplain(t, "EXPLAIN (SELECT a FROM t) UNION ALL (SELECT a FROM u) "+
+		"ORDER BY a DESC LIMIT 3")
+	exp = `Merge (op: UNION ALL, order by: a DESC, limit: 3)
+  Project (columns: a)
+    Select
+      Relation (name: t)
+  Project (columns: a)
+    Select
+      Relation (name: u)
+`
+	if out != exp {
+		t.Fatalf("Expected:\n%s\nValue is:\n%s", exp, out)
+	}
+}
+
+func TestExplainExpr(t *testing.T) {
+	for input, expected := range map[string]string{
+		"a - (b - c)":                       "a - (b - c)",
+		"(a - b) - c":                       "a - b - c",
+		"NOT (a = 1)":                       "NOT (a = 1)",
+		"- -1":                              "-(-1)",
+		"COUNT(DISTINCT a) IN (1, @x)":      "COUNT(DISTINCT a) IN (1, @x)",
+		"CASE a WHEN 1 THEN 'x' ELSE NEW.b": "CASE a WHEN 1 THEN 'x' ELSE NEW.b",
+		"(CASE WHEN a THEN 1 ELSE 0) + 1":   "(CASE WHEN a THEN 1 ELSE 0) + 1",
+		"CASE WHEN a THEN (CASE b WHEN 1 THEN 2) ELSE 3": "CASE WHEN a THEN (CASE b WHEN 1 THEN 2) ELSE 3",
+		"CAST(a AS DECIMAL(5, 2)) LIKE 'a%'":             "CAST(a AS DECIMAL(5, 2)) LIKE 'a%'",
+		"d + INTERVAL 1 + 1 DAY":                         "d + INTERVAL (1 + 1) DAY",
+	} {
+		expr, err := parser.ParseExpression(input)
+		if err != nil {
+			t.Fatal(input, err)
+		}
+		if s := exprString(expr); s != expected {
+			t.Fatalf("%s: expected %s, but %s", input, expected, s)
+		}
+		if expr, err = parser.ParseExpression(expected); err != nil || exprString(expr) != expected {
+			t.Fatal(expected, err)
+		}
+	}
+}
+
+func TestExplainError(t *testing.T) {
+	cmd, err := parser.ParseCommand("EXPLAIN ANALYZE SELECT * FROM t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = Explain(cmd.(*ast.Explain)); err == nil {
+		t.Fatal("EXPLAIN ANALYZE should be fail")
+	}
+	if _, err = parser.ParseCommand("EXPLAIN SHOW TABLES"); err == nil {
+		t.Fatal("EXPLAIN SHOW should be fail")
+	}
+}
+
+func assertExplain(t *testing.T, input string) string {
+	cmd, err := parser.ParseCommand(input)
+	if err != nil {
+		t.Fatalf("%s: %v", input, err)
+	}
+	out, err := Explain(cmd.(*ast.Explain))
+	if err != nil {
+		t.Fatalf("%s: %v", input, err)
+	}
+	return out
+}
@@ -1,7 +1,18 @@
 package plan
 
+import (
+	"github.com/emptyland/akino/sql/ast"
+	"github.com/emptyland/akino/sql/token"
+)
+
+// Merge of children, it is a compound select if Op is not 0, otherwise a join
+// of type Join. Tables in FROM list separated by comma are JT_CROSS.
 type Merge struct {
 	nodeBase
+	Op      token.Token       // token.UNION | UNION_ALL | EXCEPT | INTERSECT
+	OrderBy []ast.OrderByItem `yaml:"-"` // Order of the result of compound
+	Join    int               // ast.JT_*
+	Using   []string          // Columns of USING of join
 }
 
 func (self *Merge) Children() []Node {
@@ -15,7 +26,7 @@ func (self *Merge) Accept(v Visitor, f *bool) {
 
 type Project struct {
 	nodeBase
-	Columns []Node
+	Columns []Column
 }
 
 func (self *Project) Children() []Node {
@@ -27,8 +38,14 @@ func (self *Project) Accept(v Visitor, f *bool) {
 	visitChildrenIfNeed(self, v, f)
 }
 
+// Select of rows, they are grouped by GroupBy and filtered by Having if it is
+// not empty, then sorted by OrderBy.
 type Select struct {
 	nodeBase
+	Distinct bool
+	GroupBy  []ast.Expr        `yaml:"-"`
+	Having   *Filter           `yaml:"-"`
+	OrderBy  []ast.OrderByItem `yaml:"-"`
 }
 
 func (self *Select) Children() []Node {
@@ -71,6 +88,54 @@ func (self *Alias) Accept(v Visitor, f *bool) {
 	visitChildrenIfNeed(self, v, f)
 }
 
+type Insert struct {
+	nodeBase  `yaml:"Insert"`
+	Returning []Column `yaml:"returning"`
+}
+
+func (self *Insert) Children() []Node {
+	return self.nodeBase.Children
+}
+
+func (self *Insert) Accept(v Visitor, f *bool) {
+	v.OnInsert(self, f)
+	visitChildrenIfNeed(self, v, f)
+}
+
+type Update struct {
+	nodeBase  `yaml:"Update"`
+	Returning []Column `yaml:"returning"`
+}
+
+func (self *Update) Children() []Node {
+	return self.nodeBase.Children
+}
+
+func (self *Update) Accept(v Visitor, f *bool) {
+	v.OnUpdate(self, f)
+	visitChildrenIfNeed(self, v, f)
+}
+
+type Delete struct {
+	nodeBase  `yaml:"Delete"`
+	Returning []Column `yaml:"returning"`
+}
+
+func (self *Delete) Children() []Node {
+	return self.nodeBase.Children
+}
+
+func (self *Delete) Accept(v Visitor, f *bool) {
+	v.OnDelete(self, f)
+	visitChildrenIfNeed(self, v, f)
+}
+
+// Column of result set, Name is empty if the expression has no name.
+type Column struct {
+	Name string
+	Expr ast.Expr `yaml:"-"`
+}
+
 type nodeBase struct {
 	Limit    *Limit
 	Filter   *Filter
@@ -88,6 +153,7 @@ type Limit struct {
 }
 
 type Filter struct {
+	Cond ast.Expr `yaml:"-"`
 }
 
 type Visitor interface {
@@ -96,6 +162,9 @@ type Visitor interface {
 	OnSelect(node *Select, f *bool)
 	OnRelation(node *Relation, f *bool)
 	OnAlias(node *Alias, f *bool)
+	OnInsert(node *Insert, f *bool)
+	OnUpdate(node *Update, f *bool)
+	OnDelete(node *Delete, f *bool)
 }
 
 func visitChildrenIfNeed(node Node, visitor Visitor, f *bool) {
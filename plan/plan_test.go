@@ -69,3 +69,12 @@ func (self *testVisitor) OnRelation(node *Relation, f *bool) {
 func (self *testVisitor) OnAlias(node *Alias, f *bool) {
 	self.t.Log(node)
 }
+func (self *testVisitor) OnInsert(node *Insert, f *bool) {
+	self.t.Log(node)
+}
+func (self *testVisitor) OnUpdate(node *Update, f *bool) {
+	self.t.Log(node)
+}
+func (self *testVisitor) OnDelete(node *Delete, f *bool) {
+	self.t.Log(node)
+}
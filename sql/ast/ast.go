@@ -49,12 +49,26 @@ func (self *NameRef) Full() string {
 	}
 }
 
+// Qualify an unqualified name with the current database.
+func (self *NameRef) Qualify(db string) NameRef {
+	if self.Second != "" || db == "" {
+		return *self
+	} else {
+		return NameRef{First: db, Second: self.First}
+	}
+}
+
+//------------------------------------------------------------------------------
+// Query is a single SELECT or a compound SELECT.
+type Query interface {
+	Command
+	query()
+}
+
 //------------------------------------------------------------------------------
 type Select struct {
 	SelectPos  int
 	SelectEnd  int
-	Op         token.Token
-	Prior      *Select
 	Distinct   bool
 	Limit      Expr
 	Offset     Expr
@@ -74,6 +88,30 @@ func (self *Select) End() int {
 	return self.SelectEnd
 }
 
+func (self *Select) query() {}
+
+//------------------------------------------------------------------------------
+// Compound SELECT: Left Op Right ORDER BY ... LIMIT ...
+type Compound struct {
+	CompoundEnd int
+	Op          token.Token // UNION, UNION_ALL, EXCEPT or INTERSECT
+	Left        Query
+	Right       Query
+	OrderBy     []OrderByItem // For the result of compound
+	Limit       Expr
+	Offset      Expr
+}
+
+func (self *Compound) Pos() int {
+	return self.Left.Pos()
+}
+
+func (self *Compound) End() int {
+	return self.CompoundEnd
+}
+
+func (self *Compound) query() {}
+
 type SelectColumn struct {
 	SelectExpr Expr
 	Alias      string
@@ -88,13 +126,11 @@ type OrderByItem struct {
 type Source struct {
 	SourcePos int
 	SourceEnd int
-	JoinType  int
 	Table     *NameRef
-	Subquery  *Select
+	Subquery  Query
+	Join      *Join
 	Alias     string
 	Indexed   string
-	On        Expr
-	Using     []Identifier
 }
 
 func (self *Source) Pos() int {
@@ -105,7 +141,7 @@ func (self *Source) End() int {
 	return self.SourceEnd
 }
 
-func (self *Source) IsSubquery() (bool, *Select) {
+func (self *Source) IsSubquery() (bool, Query) {
 	return self.Subquery != nil, self.Subquery
 }
 
@@ -113,6 +149,27 @@ func (self *Source) IsTable() (bool, *NameRef) {
 	return self.Table != nil, self.Table
 }
 
+func (self *Source) IsJoin() (bool, *Join) {
+	return self.Join != nil, self.Join
+}
+
+// The first table or subquery in a join tree.
+func (self *Source) Leftmost() *Source {
+	src := self
+	for src.Join != nil {
+		src = &src.Join.Left
+	}
+	return src
+}
+
+type Join struct {
+	Type  int // JT_*
+	Left  Source
+	Right Source
+	On    Expr
+	Using []Identifier
+}
+
 const (
 	JT_INNER = (1 << iota)
 	JT_CROSS
@@ -120,13 +177,19 @@ const (
 	JT_LEFT
 	JT_RIGHT
 	JT_OUTER
+	JT_FULL
+	JT_STRAIGHT
 )
 
 //------------------------------------------------------------------------------
 type Transaction struct {
 	TransactionPos int
-	Op             token.Token // token.BEGIN | START | COMMIT | ROLLBACK
+	TransactionEnd int
+	Op             token.Token // token.BEGIN | START | COMMIT | END | ROLLBACK | SAVEPOINT | RELEASE
 	Type           token.Token // token.DEFERREF | IMMEDIATE | EXCLUSIVE
+	Savepoint      string      // SAVEPOINT name | RELEASE name | ROLLBACK TO name
+	Isolation      int         // ISOLATION_*
+	ReadOnly       bool
 }
 
 func (self *Transaction) Pos() int {
@@ -134,13 +197,27 @@ func (self *Transaction) Pos() int {
 }
 
 func (self *Transaction) End() int {
-	return self.Pos()
+	return self.TransactionEnd
 }
 
+const (
+	ISOLATION_DEFAULT = iota
+	ISOLATION_READ_UNCOMMITTED
+	ISOLATION_READ_COMMITTED
+	ISOLATION_REPEATABLE_READ
+	ISOLATION_SERIALIZABLE
+)
+
 //------------------------------------------------------------------------------
 type Show struct {
-	ShowPos int
-	Dest    token.Token // token.TABLES | DATABASES
+	ShowPos  int
+	ShowEnd  int
+	Dest     token.Token // token.TABLES | DATABASES | COLUMNS | INDEX | CREATE
+	Full     bool
+	Target   NameRef  // Table of COLUMNS, INDEX and CREATE TABLE
+	Database string   // SHOW TABLES FROM Database
+	Like     *Literal // SHOW ... LIKE 'pattern'
+	Where    Expr     // SHOW ... WHERE Expr
 }
 
 func (self *Show) Pos() int {
@@ -148,7 +225,246 @@ func (self *Show) Pos() int {
 }
 
 func (self *Show) End() int {
-	return self.Pos()
+	return self.ShowEnd
+}
+
+//------------------------------------------------------------------------------
+type Explain struct {
+	ExplainPos int
+	QueryPlan  bool
+	Analyze    bool
+	Format     token.Token // token.TEXT | JSON | YAML
+	Cmd        Command
+}
+
+func (self *Explain) Pos() int {
+	return self.ExplainPos
+}
+
+func (self *Explain) End() int {
+	return self.Cmd.End()
+}
+
+//------------------------------------------------------------------------------
+type CreateDatabase struct {
+	CreatePos   int
+	CreateEnd   int
+	IfNotExists bool
+	Name        string
+	Options     TableOptions // CHARACTER SET and COLLATE
+}
+
+func (self *CreateDatabase) Pos() int {
+	return self.CreatePos
+}
+
+func (self *CreateDatabase) End() int {
+	return self.CreateEnd
+}
+
+//------------------------------------------------------------------------------
+type DropDatabase struct {
+	DropPos  int
+	DropEnd  int
+	IfExists bool
+	Name     string
+}
+
+func (self *DropDatabase) Pos() int {
+	return self.DropPos
+}
+
+func (self *DropDatabase) End() int {
+	return self.DropEnd
+}
+
+//------------------------------------------------------------------------------
+type Use struct {
+	UsePos int
+	UseEnd int
+	Name   string
+}
+
+func (self *Use) Pos() int {
+	return self.UsePos
+}
+
+func (self *Use) End() int {
+	return self.UseEnd
+}
+
+//------------------------------------------------------------------------------
+type Attach struct {
+	AttachPos int
+	AttachEnd int
+	File      Expr
+	Name      string
+}
+
+func (self *Attach) Pos() int {
+	return self.AttachPos
+}
+
+func (self *Attach) End() int {
+	return self.AttachEnd
+}
+
+//------------------------------------------------------------------------------
+type Detach struct {
+	DetachPos int
+	DetachEnd int
+	Name      string
+}
+
+func (self *Detach) Pos() int {
+	return self.DetachPos
+}
+
+func (self *Detach) End() int {
+	return self.DetachEnd
+}
+
+//------------------------------------------------------------------------------
+type Pragma struct {
+	PragmaPos int
+	PragmaEnd int
+	Name      NameRef
+	Value     Expr // PRAGMA Name = Value or PRAGMA Name(Value)
+}
+
+func (self *Pragma) Pos() int {
+	return self.PragmaPos
+}
+
+func (self *Pragma) End() int {
+	return self.PragmaEnd
+}
+
+//------------------------------------------------------------------------------
+type Analyze struct {
+	AnalyzePos int
+	AnalyzeEnd int
+	Target     NameRef // Empty for all tables
+}
+
+func (self *Analyze) Pos() int {
+	return self.AnalyzePos
+}
+
+func (self *Analyze) End() int {
+	return self.AnalyzeEnd
+}
+
+//------------------------------------------------------------------------------
+type Vacuum struct {
+	VacuumPos int
+	VacuumEnd int
+	Database  string // Empty for the main database
+}
+
+func (self *Vacuum) Pos() int {
+	return self.VacuumPos
+}
+
+func (self *Vacuum) End() int {
+	return self.VacuumEnd
+}
+
+//------------------------------------------------------------------------------
+type Reindex struct {
+	ReindexPos int
+	ReindexEnd int
+	Target     NameRef // Table or index name, empty for all indexes
+}
+
+func (self *Reindex) Pos() int {
+	return self.ReindexPos
+}
+
+func (self *Reindex) End() int {
+	return self.ReindexEnd
+}
+
+//------------------------------------------------------------------------------
+type Truncate struct {
+	TruncatePos int
+	TruncateEnd int
+	Table       NameRef
+}
+
+func (self *Truncate) Pos() int {
+	return self.TruncatePos
+}
+
+func (self *Truncate) End() int {
+	return self.TruncateEnd
+}
+
+//------------------------------------------------------------------------------
+type Prepare struct {
+	PreparePos int
+	PrepareEnd int
+	Name       string
+	From       Expr // String literal or user variable
+}
+
+func (self *Prepare) Pos() int {
+	return self.PreparePos
+}
+
+func (self *Prepare) End() int {
+	return self.PrepareEnd
+}
+
+//------------------------------------------------------------------------------
+type Execute struct {
+	ExecutePos int
+	ExecuteEnd int
+	Name       string
+	Using      []Variable
+}
+
+func (self *Execute) Pos() int {
+	return self.ExecutePos
+}
+
+func (self *Execute) End() int {
+	return self.ExecuteEnd
+}
+
+//------------------------------------------------------------------------------
+type Deallocate struct {
+	DeallocatePos int
+	DeallocateEnd int
+	Name          string
+}
+
+func (self *Deallocate) Pos() int {
+	return self.DeallocatePos
+}
+
+func (self *Deallocate) End() int {
+	return self.DeallocateEnd
+}
+
+//------------------------------------------------------------------------------
+type SetVariable struct {
+	SetPos int
+	SetEnd int
+	Set    []VariableDefine
+}
+
+func (self *SetVariable) Pos() int {
+	return self.SetPos
+}
+
+func (self *SetVariable) End() int {
+	return self.SetEnd
+}
+
+type VariableDefine struct {
+	Name  Variable
+	Value Expr
 }
 
 //------------------------------------------------------------------------------
@@ -195,8 +511,11 @@ type CreateTable struct {
 	IfNotExists     bool
 	Table           NameRef
 	Scheme          []ColumnDefine
-	Template        *Select
+	Template        Query
 	CheckConstraint []Expr
+	ForeignKey      []ForeignKey
+	Constraint      []Constraint
+	Options         TableOptions
 }
 
 func (self *CreateTable) Pos() int {
@@ -229,6 +548,47 @@ type ColumnDefine struct {
 	UniqueOn       token.Token
 	AutoIncr       bool
 	Collate        string
+	ForeignKey     *ForeignKey
+	Comment        string
+	OnUpdate       Expr
+}
+
+/*
+ * On Delete/On Update:
+ *	token.NO_ACTION
+ *	token.RESTRICT
+ *	token.CASCADE
+ *	token.SET_NULL
+ *	token.SET_DEFAULT
+ */
+type ForeignKey struct {
+	Name              string
+	Column            []string
+	Table             NameRef
+	RefColumn         []string
+	OnDelete          token.Token
+	OnUpdate          token.Token
+	Deferrable        bool
+	InitiallyDeferred bool
+}
+
+// MySQL table options: ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 ...
+type TableOptions struct {
+	Engine   string
+	Charset  string
+	Collate  string
+	Comment  string
+	AutoIncr *Literal
+	Extra    map[string]string // Other options, the key is upper case
+}
+
+// Named constraint: `CONSTRAINT' Identifier ..., or table constraint whose Name
+// is empty if it is unnamed.
+type Constraint struct {
+	Name   string
+	Kind   token.Token // token.PRIMARY | UNIQUE | CHECK | FOREIGN | NOT | DEFAULT | COLLATE
+	Column []string
+	Check  Expr
 }
 
 //------------------------------------------------------------------------------
@@ -256,6 +616,46 @@ type IndexDefine struct {
 	Desc    bool
 }
 
+//------------------------------------------------------------------------------
+type CreateTrigger struct {
+	CreatePos   int
+	CreateEnd   int
+	Temp        bool
+	IfNotExists bool
+	Name        NameRef
+	Time        token.Token // BEFORE, AFTER or INSTEAD (OF)
+	Event       token.Token // INSERT, UPDATE or DELETE
+	Column      []string    // UPDATE OF Column
+	Table       NameRef
+	ForEachRow  bool
+	When        Expr
+	Body        []Command
+}
+
+func (self *CreateTrigger) Pos() int {
+	return self.CreatePos
+}
+
+func (self *CreateTrigger) End() int {
+	return self.CreateEnd
+}
+
+//------------------------------------------------------------------------------
+type DropTrigger struct {
+	DropPos  int
+	DropEnd  int
+	IfExists bool
+	Name     NameRef
+}
+
+func (self *DropTrigger) Pos() int {
+	return self.DropPos
+}
+
+func (self *DropTrigger) End() int {
+	return self.DropEnd
+}
+
 //------------------------------------------------------------------------------
 type Insert struct {
 	InsertPos int
@@ -263,8 +663,10 @@ type Insert struct {
 	Op        token.Token
 	Dest      NameRef
 	Column    []Identifier
-	Item      []Expr
-	From      *Select
+	Rows      [][]Expr
+	From      Query
+	Upsert    *Upsert
+	Returning []SelectColumn
 }
 
 func (self *Insert) Pos() int {
@@ -276,7 +678,22 @@ func (self *Insert) End() int {
 }
 
 func (self *Insert) DefaultValues() bool {
-	return len(self.Item) == 0 && self.From == nil
+	return len(self.Rows) == 0 && self.From == nil
+}
+
+/*
+ * Conflict:
+ *	token.DUPLICATE: ON DUPLICATE KEY UPDATE
+ *	token.CONFLICT:  ON CONFLICT Target DO UPDATE | ON CONFLICT Target DO NOTHING
+ */
+type Upsert struct {
+	UpsertPos   int
+	Conflict    token.Token
+	Target      []Identifier
+	TargetWhere Expr
+	DoNothing   bool
+	Set         []SetDefine
+	Where       Expr
 }
 
 //------------------------------------------------------------------------------
@@ -284,13 +701,16 @@ type Update struct {
 	UpdatePos int
 	UpdateEnd int
 	Op        token.Token
-	Dest      NameRef
+	Dest      NameRef // The first table to update
 	Indexed   string
+	Join      []Source // Table references for multi-table or aliased update
 	Set       []SetDefine
+	From      []Source // UPDATE ... SET ... FROM
 	Where     Expr
 	OrderBy   []OrderByItem
 	Limit     Expr
 	Offset    Expr
+	Returning []SelectColumn
 }
 
 func (self *Update) Pos() int {
@@ -302,6 +722,8 @@ func (self *Update) End() int {
 }
 
 type SetDefine struct {
+	SetPos int    // Position of the target column
+	Table  string // Qualifier of column, could be empty
 	Column string
 	Value  Expr
 }
@@ -310,12 +732,16 @@ type SetDefine struct {
 type Delete struct {
 	DeletePos int
 	DeleteEnd int
-	Dest      NameRef
+	Dest      NameRef // The first table to delete
 	Indexed   string
+	Target    []NameRef // All tables to delete for multi-table delete
+	Join      []Source  // DELETE Target FROM Join
+	Using     []Source  // DELETE FROM Target USING Using
 	Where     Expr
 	OrderBy   []OrderByItem
 	Limit     Expr
 	Offset    Expr
+	Returning []SelectColumn
 }
 
 func (self *Delete) Pos() int {
@@ -333,6 +759,9 @@ type Type struct {
 	Width    *Literal
 	Decimal  *Literal
 	Unsigned bool
+	Zerofill bool
+	Charset  string
+	Values   []string // Allowed values of ENUM and SET
 }
 
 func (self *Type) Pos() int {
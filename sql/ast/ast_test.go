@@ -53,6 +53,21 @@ func TestDequotedIdentifier(t *testing.T) {
 	}
 }
 
+func TestBoolConstIdentifier(t *testing.T) {
+	for name, expected := range map[string]bool{"TRUE": true, "false": false} {
+		id := &Identifier{NamePos: 0, Name: name}
+		if value, ok := id.BoolConst(); !ok || value != expected {
+			t.Fatal(name)
+		}
+	}
+	for _, name := range []string{"name", "`TRUE`"} {
+		id := &Identifier{NamePos: 0, Name: name}
+		if _, ok := id.BoolConst(); ok {
+			t.Fatal(name)
+		}
+	}
+}
+
 func TestUnaryExpr(t *testing.T) {
 	// 3.14 IS NULL
 	expr := &UnaryExpr{
@@ -101,6 +116,60 @@ func TestWhereInExpr(t *testing.T) {
 	}
 }
 
+func TestNameRefQualify(t *testing.T) {
+	name := NameRef{First: "t"}
+	if full := name.Qualify("db"); full.Database() != "db" || full.Table() != "t" {
+		t.Fatal("fail")
+	}
+	if full := name.Qualify(""); full.Full() != "t" {
+		t.Fatal("fail")
+	}
+
+	name = NameRef{First: "aux", Second: "t"}
+	if full := name.Qualify("db"); full.Full() != "aux.t" {
+		t.Fatal("fail")
+	}
+}
+
+func TestWalk(t *testing.T) {
+	// SELECT a + 1 FROM t WHERE f(b)
+	cmd := &Select{
+		SelColList: []SelectColumn{
+			{SelectExpr: &BinaryExpr{
+				Op:  token.PLUS,
+				Lhs: &Identifier{Name: "a"},
+				Rhs: &Literal{Value: "1", Kind: token.INT_LITERAL},
+			}},
+		},
+		From: []Source{{Table: &NameRef{First: "t"}}},
+		Where: &CallExpr{
+			Func: Identifier{Name: "f"},
+			Args: []Expr{&Identifier{Name: "b"}},
+		},
+	}
+
+	var name []string
+	Walk(cmd, func(node Node) bool {
+		if id, ok := node.(*Identifier); ok {
+			name = append(name, id.Name)
+		}
+		return true
+	})
+	if len(name) != 2 || name[0] != "a" || name[1] != "b" {
+		t.Fatal(name)
+	}
+
+	count := 0
+	Walk(cmd, func(node Node) bool {
+		count++
+		_, ok := node.(*CallExpr)
+		return !ok
+	})
+	if count != 6 {
+		t.Fatal(count)
+	}
+}
+
 func TestAstDump(t *testing.T) {
 	expr := &UnaryExpr{
 		OpPos:   8,
@@ -48,6 +48,50 @@ func (self *Identifier) Dequote() (bool, string) {
 	}
 }
 
+// Unquoted TRUE and FALSE are parsed as identifiers, but they are constants 1
+// and 0 like MySQL, not columns. ok is false for other identifiers.
+func (self *Identifier) BoolConst() (value, ok bool) {
+	switch strings.ToUpper(self.Name) {
+	case "TRUE":
+		return true, true
+	case "FALSE":
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+//------------------------------------------------------------------------------
+// NEW.column or OLD.column in the body of trigger.
+type PseudoColumn struct {
+	NamePos int
+	Row     string // NEW or OLD
+	Column  Identifier
+}
+
+func (self *PseudoColumn) Pos() int {
+	return self.NamePos
+}
+
+func (self *PseudoColumn) End() int {
+	return self.Column.End()
+}
+
+//------------------------------------------------------------------------------
+// User variable: @name
+type Variable struct {
+	NamePos int
+	Name    string // Without `@'
+}
+
+func (self *Variable) Pos() int {
+	return self.NamePos
+}
+
+func (self *Variable) End() int {
+	return self.Pos() + len(self.Name) + 1
+}
+
 //------------------------------------------------------------------------------
 type Literal struct {
 	ValuePos int
@@ -106,6 +150,9 @@ func (self *CallExpr) Pos() int {
 }
 
 func (self *CallExpr) End() int {
+	if len(self.Args) == 0 {
+		return self.Func.End()
+	}
 	return self.Args[len(self.Args)-1].End()
 }
 
@@ -148,3 +195,20 @@ func (self *CastExpr) Pos() int {
 func (self *CastExpr) End() int {
 	return self.To.End()
 }
+
+//------------------------------------------------------------------------------
+// INTERVAL Value Unit in date arithmetic: d + INTERVAL 1 DAY
+type IntervalExpr struct {
+	OpPos   int
+	Value   Expr
+	UnitPos int
+	Unit    string // Upper case: DAY, HOUR_MINUTE, ...
+}
+
+func (self *IntervalExpr) Pos() int {
+	return self.OpPos
+}
+
+func (self *IntervalExpr) End() int {
+	return self.UnitPos + len(self.Unit)
+}
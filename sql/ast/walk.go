@@ -0,0 +1,173 @@
+package ast
+
+// Walk traverses the tree of node in depth-first order. It calls fn for each
+// node, the children of node will be skipped if fn returns false.
+//
+// Source and Join are not expressions, but subqueries and expressions in them
+// will be visited.
+func Walk(node Node, fn func(Node) bool) {
+	if node == nil || !fn(node) {
+		return
+	}
+
+	switch n := node.(type) {
+	//--------------------------------------------------------------------------
+	// Expressions
+	//--------------------------------------------------------------------------
+	case ExprList:
+		walkList(n, fn)
+
+	case *UnaryExpr:
+		Walk(n.Operand, fn)
+
+	case *BinaryExpr:
+		Walk(n.Lhs, fn)
+		Walk(n.Rhs, fn)
+
+	case *CallExpr:
+		walkList(n.Args, fn)
+
+	case *Condition:
+		Walk(n.Case, fn)
+		for _, block := range n.Blocks {
+			Walk(block.When, fn)
+			Walk(block.Then, fn)
+		}
+		Walk(n.Else, fn)
+
+	case *CastExpr:
+		Walk(n.Operand, fn)
+
+	case *IntervalExpr:
+		Walk(n.Value, fn)
+
+	//--------------------------------------------------------------------------
+	// Queries
+	//--------------------------------------------------------------------------
+	case *Select:
+		walkColumns(n.SelColList, fn)
+		walkSources(n.From, fn)
+		Walk(n.Where, fn)
+		walkList(n.GroupBy, fn)
+		Walk(n.Having, fn)
+		walkOrderBy(n.OrderBy, fn)
+		Walk(n.Limit, fn)
+		Walk(n.Offset, fn)
+
+	case *Compound:
+		Walk(n.Left, fn)
+		Walk(n.Right, fn)
+		walkOrderBy(n.OrderBy, fn)
+		Walk(n.Limit, fn)
+		Walk(n.Offset, fn)
+
+	case *Source:
+		Walk(n.Subquery, fn)
+		if n.Join != nil {
+			Walk(&n.Join.Left, fn)
+			Walk(&n.Join.Right, fn)
+			Walk(n.Join.On, fn)
+		}
+
+	//--------------------------------------------------------------------------
+	// Commands
+	//--------------------------------------------------------------------------
+	case *Insert:
+		for _, row := range n.Rows {
+			walkList(row, fn)
+		}
+		Walk(n.From, fn)
+		if n.Upsert != nil {
+			Walk(n.Upsert.TargetWhere, fn)
+			walkSet(n.Upsert.Set, fn)
+			Walk(n.Upsert.Where, fn)
+		}
+		walkColumns(n.Returning, fn)
+
+	case *Update:
+		walkSources(n.Join, fn)
+		walkSet(n.Set, fn)
+		walkSources(n.From, fn)
+		Walk(n.Where, fn)
+		walkOrderBy(n.OrderBy, fn)
+		Walk(n.Limit, fn)
+		Walk(n.Offset, fn)
+		walkColumns(n.Returning, fn)
+
+	case *Delete:
+		walkSources(n.Join, fn)
+		walkSources(n.Using, fn)
+		Walk(n.Where, fn)
+		walkOrderBy(n.OrderBy, fn)
+		Walk(n.Limit, fn)
+		Walk(n.Offset, fn)
+		walkColumns(n.Returning, fn)
+
+	case *CreateTable:
+		for _, def := range n.Scheme {
+			Walk(def.Default, fn)
+			Walk(def.OnUpdate, fn)
+		}
+		walkList(n.CheckConstraint, fn)
+		for _, c := range n.Constraint {
+			Walk(c.Check, fn)
+		}
+		Walk(n.Template, fn)
+
+	case *CreateTrigger:
+		Walk(n.When, fn)
+		for _, cmd := range n.Body {
+			Walk(cmd, fn)
+		}
+
+	case *Explain:
+		Walk(n.Cmd, fn)
+
+	case *Show:
+		Walk(n.Where, fn)
+
+	case *Pragma:
+		Walk(n.Value, fn)
+
+	case *Attach:
+		Walk(n.File, fn)
+
+	case *Prepare:
+		Walk(n.From, fn)
+
+	case *SetVariable:
+		for _, def := range n.Set {
+			Walk(def.Value, fn)
+		}
+	}
+}
+
+func walkList(list []Expr, fn func(Node) bool) {
+	for _, expr := range list {
+		Walk(expr, fn)
+	}
+}
+
+func walkColumns(list []SelectColumn, fn func(Node) bool) {
+	for _, col := range list {
+		Walk(col.SelectExpr, fn)
+	}
+}
+
+func walkOrderBy(list []OrderByItem, fn func(Node) bool) {
+	for _, item := range list {
+		Walk(item.Item, fn)
+	}
+}
+
+func walkSet(list []SetDefine, fn func(Node) bool) {
+	for _, set := range list {
+		Walk(set.Value, fn)
+	}
+}
+
+func walkSources(list []Source, fn func(Node) bool) {
+	for i := range list {
+		Walk(&list[i], fn)
+	}
+}
@@ -0,0 +1,148 @@
+package collate
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Collation defines order and equality of strings. Key encodes string to bytes
+// which have the same order, so it can be used in index, GROUP BY and DISTINCT.
+type Collation struct {
+	Name    string
+	key     func(s string) []byte
+	compare func(a, b string) int
+}
+
+// New collation, compare can be nil if comparing keys is fast enough.
+func New(name string, key func(s string) []byte, compare func(a, b string) int) *Collation {
+	return &Collation{Name: name, key: key, compare: compare}
+}
+
+func (self *Collation) Key(s string) []byte {
+	return self.key(s)
+}
+
+func (self *Collation) Compare(a, b string) int {
+	if self.compare != nil {
+		return self.compare(a, b)
+	}
+	return strings.Compare(string(self.key(a)), string(self.key(b)))
+}
+
+func (self *Collation) Equal(a, b string) bool {
+	return self.Compare(a, b) == 0
+}
+
+// Letters are case-insensitive in the collation, e.g. NOCASE.
+func (self *Collation) Fold() bool {
+	return self.Equal("a", "A")
+}
+
+//------------------------------------------------------------------------------
+// Registry
+//------------------------------------------------------------------------------
+const DEFAULT = "BINARY"
+
+type Registry struct {
+	coll map[string]*Collation
+}
+
+func NewRegistry() *Registry {
+	return &Registry{coll: make(map[string]*Collation)}
+}
+
+// New registry with all built-in collations, collations registered in it will
+// not affect others.
+func Builtin() *Registry {
+	reg := NewRegistry()
+	for _, coll := range builtin {
+		reg.coll[strings.ToUpper(coll.Name)] = coll
+	}
+	return reg
+}
+
+func (self *Registry) Register(coll *Collation) error {
+	name := strings.ToUpper(coll.Name)
+	if _, found := self.coll[name]; found {
+		return fmt.Errorf("Collation %s already exists", coll.Name)
+	}
+	if coll.key == nil {
+		return fmt.Errorf("No key function of collation %s", coll.Name)
+	}
+	self.coll[name] = coll
+	return nil
+}
+
+// Name of collation is case-insensitive, empty name is the DEFAULT.
+func (self *Registry) Lookup(name string) (*Collation, bool) {
+	if name == "" {
+		name = DEFAULT
+	}
+	coll, found := self.coll[strings.ToUpper(name)]
+	return coll, found
+}
+
+//------------------------------------------------------------------------------
+// Built-in
+//------------------------------------------------------------------------------
+var (
+	Binary = New("BINARY", func(s string) []byte {
+		return []byte(s)
+	}, strings.Compare)
+
+	// Like SQLite, only ASCII letters are case-insensitive.
+	NoCase = New("NOCASE", func(s string) []byte {
+		b := []byte(s)
+		for i, c := range b {
+			if c >= 'A' && c <= 'Z' {
+				b[i] = c + 'a' - 'A'
+			}
+		}
+		return b
+	}, nil)
+
+	// Like SQLite, trailing spaces are ignored.
+	RTrim = New("RTRIM", func(s string) []byte {
+		return []byte(strings.TrimRight(s, " "))
+	}, nil)
+
+	// Like MySQL utf8mb4_general_ci without accent folding: case-insensitive in
+	// Unicode, trailing spaces are ignored.
+	GeneralCI = New("UTF8MB4_GENERAL_CI", generalKey, nil)
+)
+
+var builtin = []*Collation{
+	Binary,
+	NoCase,
+	RTrim,
+	GeneralCI,
+	New("UTF8_GENERAL_CI", generalKey, nil),
+	New("UTF8MB4_BIN", Binary.key, Binary.compare),
+	New("UTF8_BIN", Binary.key, Binary.compare),
+}
+
+func generalKey(s string) []byte {
+	s = strings.TrimRight(s, " ")
+	b := make([]byte, 0, len(s))
+	for _, r := range s {
+		b = append(b, string(fold(r))...)
+	}
+	return b
+}
+
+// The least rune in the case folding orbit of r, it is the upper case for ASCII
+// letters like weight of MySQL.
+func fold(r rune) rune {
+	if r == utf8.RuneError {
+		return r
+	}
+	min := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if f < min {
+			min = f
+		}
+	}
+	return min
+}
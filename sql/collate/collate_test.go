@@ -0,0 +1,83 @@
+package collate
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+)
+
+func TestBuiltin(t *testing.T) {
+	reg := Builtin()
+	assertCompare(t, reg, "binary", "abc", "ABC", 1)
+	assertCompare(t, reg, "", "a", "b", -1)
+	assertCompare(t, reg, "nocase", "abc", "ABC", 0)
+	assertCompare(t, reg, "NOCASE", "Ä", "ä", -1) // ASCII only
+	assertCompare(t, reg, "rtrim", "abc  ", "abc", 0)
+	assertCompare(t, reg, "rtrim", " abc", "abc", -1)
+	assertCompare(t, reg, "utf8mb4_general_ci", "Straße  ", "STRASSE", 1)
+	assertCompare(t, reg, "utf8mb4_general_ci", "Ä ", "ä", 0)
+	assertCompare(t, reg, "utf8_general_ci", "a", "[", -1) // Weight of `a' is `A'
+	assertCompare(t, reg, "utf8mb4_bin", "a", "A", 1)
+
+	if !NoCase.Fold() || !GeneralCI.Fold() || Binary.Fold() || RTrim.Fold() {
+		t.Fatal("fail")
+	}
+
+	if _, found := reg.Lookup("latin1_swedish_ci"); found {
+		t.Fatal("fail")
+	}
+}
+
+func TestKey(t *testing.T) {
+	words := []string{"b", "B ", "a", "C", "A", "ab", "Ab"}
+	for _, coll := range []*Collation{Binary, NoCase, RTrim, GeneralCI} {
+		sorted := append([]string(nil), words...)
+		sort.Slice(sorted, func(i, j int) bool {
+			return coll.Compare(sorted[i], sorted[j]) < 0
+		})
+		for i := 1; i < len(sorted); i++ {
+			c := bytes.Compare(coll.Key(sorted[i-1]), coll.Key(sorted[i]))
+			if c > 0 || (c == 0) != coll.Equal(sorted[i-1], sorted[i]) {
+				t.Fatalf("%s: bad key of %q and %q", coll.Name, sorted[i-1], sorted[i])
+			}
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	reg := Builtin()
+	reverse := New("reverse", func(s string) []byte {
+		b := []byte(s)
+		for i := range b {
+			b[i] = ^b[i]
+		}
+		return append(b, 0xff)
+	}, nil)
+	if err := reg.Register(reverse); err != nil {
+		t.Fatal(err)
+	}
+	assertCompare(t, reg, "REVERSE", "a", "b", 1)
+	assertCompare(t, reg, "REVERSE", "ab", "a", -1)
+	if _, found := Builtin().Lookup("reverse"); found {
+		t.Fatal("fail")
+	}
+
+	err := reg.Register(New("nocase", NoCase.key, nil))
+	if err == nil || err.Error() != "Collation nocase already exists" {
+		t.Fatal(err)
+	}
+	err = reg.Register(New("none", nil, nil))
+	if err == nil || err.Error() != "No key function of collation none" {
+		t.Fatal(err)
+	}
+}
+
+func assertCompare(t *testing.T, reg *Registry, name, a, b string, expected int) {
+	coll, found := reg.Lookup(name)
+	if !found {
+		t.Fatal("Unknown collation", name)
+	}
+	if c := coll.Compare(a, b); c != expected {
+		t.Fatalf("%s: compare %q and %q, expected %d, but %d", name, a, b, expected, c)
+	}
+}
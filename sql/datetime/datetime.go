@@ -0,0 +1,652 @@
+package datetime
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Handling of zero dates like sql_mode of MySQL.
+type ZeroMode int
+
+const (
+	ZERO_ALLOW ZeroMode = iota // '0000-00-00' and zero in date like '2020-00-01'
+	ZERO_DATE                  // Only '0000-00-00', like NO_ZERO_IN_DATE
+	ZERO_NONE                  // No zero date, like NO_ZERO_DATE and NO_ZERO_IN_DATE
+)
+
+var zeroModeNames = []string{
+	"ALLOW",
+	"DATE",
+	"NONE",
+}
+
+func (self ZeroMode) String() string {
+	return zeroModeNames[self]
+}
+
+// Maximum digits of fractional seconds: DATETIME(6) and TIME(6).
+const MAX_FSP = 6
+
+const (
+	SECOND = 1000000 // In microseconds
+	MINUTE = 60 * SECOND
+	HOUR   = 60 * MINUTE
+	DAY    = 24 * HOUR
+)
+
+// Fractional seconds precision of DATETIME(fsp), TIMESTAMP(fsp) and TIME(fsp).
+func CheckFsp(fsp int) error {
+	if fsp < 0 || fsp > MAX_FSP {
+		return fmt.Errorf("Too big precision %d, maximum is %d", fsp, MAX_FSP)
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
+// DateTime
+//------------------------------------------------------------------------------
+
+// DateTime is date and time without time zone, month and day can be 0 in zero
+// dates. The zero value is '0000-00-00 00:00:00'.
+type DateTime struct {
+	year, month, day     int
+	hour, minute, second int
+	micro                int
+}
+
+func New(year, month, day, hour, minute, second, micro int) DateTime {
+	return DateTime{year, month, day, hour, minute, second, micro}
+}
+
+func Date(year, month, day int) DateTime {
+	return DateTime{year: year, month: month, day: day}
+}
+
+// Wall clock of t in its location.
+func FromTime(t time.Time) DateTime {
+	return New(t.Year(), int(t.Month()), t.Day(), t.Hour(), t.Minute(), t.Second(),
+		t.Nanosecond()/1000)
+}
+
+func (self DateTime) Year() int        { return self.year }
+func (self DateTime) Month() int       { return self.month }
+func (self DateTime) Day() int         { return self.day }
+func (self DateTime) Hour() int        { return self.hour }
+func (self DateTime) Minute() int      { return self.minute }
+func (self DateTime) Second() int      { return self.second }
+func (self DateTime) Microsecond() int { return self.micro }
+
+// '0000-00-00 00:00:00'
+func (self DateTime) IsZero() bool {
+	return self == DateTime{}
+}
+
+// Month or day is 0, but it is not the zero date.
+func (self DateTime) HasZeroInDate() bool {
+	return !self.IsZero() && (self.month == 0 || self.day == 0)
+}
+
+// Days in month, month is from 1 to 12.
+func DaysIn(year, month int) int {
+	return time.Date(year, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// Returns error if date is invalid or zero date is not allowed in mode.
+func (self DateTime) Check(mode ZeroMode) error {
+	ok := true
+	switch {
+	case self.IsZero():
+		ok = mode != ZERO_NONE
+	case self.year < 0 || self.year > 9999 || self.month < 0 || self.month > 12 ||
+		self.day < 0 || self.day > 31:
+		ok = false
+	case self.hour < 0 || self.hour > 23 || self.minute < 0 || self.minute > 59 ||
+		self.second < 0 || self.second > 59 || self.micro < 0 || self.micro >= SECOND:
+		ok = false
+	case self.HasZeroInDate():
+		ok = mode == ZERO_ALLOW
+	default:
+		ok = self.day <= DaysIn(self.year, self.month)
+	}
+	if !ok {
+		return fmt.Errorf("Incorrect datetime value: '%s'", self)
+	}
+	return nil
+}
+
+// Time part is truncated.
+func (self DateTime) Date() DateTime {
+	return Date(self.year, self.month, self.day)
+}
+
+// Time part as TIME.
+func (self DateTime) Duration() Duration {
+	return NewDuration(self.hour, self.minute, self.second, self.micro)
+}
+
+// Time in UTC, returns false if there is zero in date.
+func (self DateTime) Time() (time.Time, bool) {
+	if self.month == 0 || self.day == 0 {
+		return time.Time{}, false
+	}
+	return time.Date(self.year, time.Month(self.month), self.day, self.hour, self.minute,
+		self.second, self.micro*1000, time.UTC), true
+}
+
+// Days since 1970-01-01, returns false if there is zero in date.
+func (self DateTime) DayNumber() (int64, bool) {
+	t, ok := self.Date().Time()
+	if !ok {
+		return 0, false
+	}
+	return t.Unix() / 86400, true
+}
+
+func fromDayNumber(days int64) DateTime {
+	t := time.Unix(days*86400, 0).UTC()
+	return Date(t.Year(), int(t.Month()), t.Day())
+}
+
+func (self DateTime) Compare(other DateTime) int {
+	a := []int{self.year, self.month, self.day, self.hour, self.minute, self.second, self.micro}
+	b := []int{other.year, other.month, other.day, other.hour, other.minute, other.second,
+		other.micro}
+	for i := range a {
+		switch {
+		case a[i] < b[i]:
+			return -1
+		case a[i] > b[i]:
+			return 1
+		}
+	}
+	return 0
+}
+
+// Number like 20200102030405, microseconds are not included.
+func (self DateTime) Int64() int64 {
+	return int64(self.year)*10000000000 + int64(self.month)*100000000 +
+		int64(self.day)*1000000 + int64(self.hour)*10000 + int64(self.minute)*100 +
+		int64(self.second)
+}
+
+// '2020-01-02'
+func (self DateTime) DateString() string {
+	return fmt.Sprintf("%04d-%02d-%02d", self.year, self.month, self.day)
+}
+
+// '2020-01-02 03:04:05', microseconds are in 6 digits if it is not 0.
+func (self DateTime) String() string {
+	s := fmt.Sprintf("%s %02d:%02d:%02d", self.DateString(), self.hour, self.minute,
+		self.second)
+	if self.micro != 0 {
+		s += fmt.Sprintf(".%06d", self.micro)
+	}
+	return s
+}
+
+// Round microseconds to fsp digits, half away from zero. Microseconds of
+// invalid date are truncated.
+func (self DateTime) Round(fsp int) DateTime {
+	unit := pow10(MAX_FSP - fsp)
+	r := (self.micro + unit/2) / unit * unit
+	if r == self.micro {
+		return self
+	}
+	if rv, err := self.Add(Interval{Micros: int64(r - self.micro)}); err == nil {
+		return rv
+	}
+	self.micro -= self.micro % unit
+	return self
+}
+
+func pow10(n int) int {
+	r := 1
+	for i := 0; i < n; i++ {
+		r *= 10
+	}
+	return r
+}
+
+// Add interval, months are added first and day is the last day of month if it
+// is out of range. Returns error if date is invalid or zero, or result is out
+// of range of '0001-01-01' to '9999-12-31'.
+func (self DateTime) Add(iv Interval) (DateTime, error) {
+	if err := self.Check(ZERO_NONE); err != nil {
+		return DateTime{}, err
+	}
+	overflow := fmt.Errorf("Datetime value is out of range in '%s' + %s", self, iv)
+
+	r := self
+	if iv.Months != 0 {
+		m := int64(r.year)*12 + int64(r.month-1) + iv.Months
+		if iv.Months < -10000*12 || iv.Months > 10000*12 || m < 12 || m >= 10000*12 {
+			return DateTime{}, overflow
+		}
+		r.year, r.month = int(m/12), int(m%12)+1
+		if n := DaysIn(r.year, r.month); r.day > n {
+			r.day = n
+		}
+	}
+
+	if iv.Micros != 0 {
+		days := iv.Micros / DAY
+		clock := int64(r.Duration()) + iv.Micros%DAY
+		switch {
+		case clock < 0:
+			days, clock = days-1, clock+DAY
+		case clock >= DAY:
+			days, clock = days+1, clock-DAY
+		}
+		n, _ := r.DayNumber()
+		if days < -4000000 || days > 4000000 {
+			return DateTime{}, overflow
+		}
+		date := fromDayNumber(n + days)
+		if date.year < 1 || date.year > 9999 {
+			return DateTime{}, overflow
+		}
+		d := Duration(clock)
+		r = New(date.year, date.month, date.day, d.Hour(), d.Minute(), d.Second(),
+			d.Microsecond())
+	}
+	return r, nil
+}
+
+//------------------------------------------------------------------------------
+// Parsing
+//------------------------------------------------------------------------------
+
+// Parse date or datetime like '2020-01-02', '2020/1/2 03:04:05.678',
+// '2020-01-02T03:04' or compact digits '20200102' and '200102030405'. Two
+// digits year 00-69 is 2000-2069 and 70-99 is 1970-1999. date is true if s has
+// no time part.
+func Parse(s string, mode ZeroMode) (d DateTime, date bool, err error) {
+	input := s
+	bad := fmt.Errorf("Incorrect datetime value: '%s'", input)
+	s = strings.TrimSpace(s)
+
+	var ok bool
+	if strings.IndexAny(s, "-/:") >= 0 {
+		d, date, ok = parseDelimited(s)
+	} else {
+		d, date, ok = parseDigits(s)
+	}
+	if !ok {
+		return DateTime{}, false, bad
+	}
+	if err = d.Check(mode); err != nil {
+		return DateTime{}, false, bad
+	}
+	return d, date, nil
+}
+
+// Date or datetime of number, like MySQL 6 or 8 digits are dates and 12 or 14
+// digits are datetimes, shorter ones are padded with 0: 101 is 2000-01-01.
+func ParseNumber(s string, mode ZeroMode) (DateTime, bool, error) {
+	digits, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		digits, frac = s[:i], s[i:]
+	}
+	if digits == "0" && strings.Trim(frac, ".0") == "" {
+		return DateTime{}, true, DateTime{}.Check(mode)
+	}
+	switch n := len(digits); {
+	case n < 6:
+		digits = strings.Repeat("0", 6-n) + digits
+	case n == 7:
+		digits = "0" + digits
+	case n > 8 && n < 12:
+		digits = strings.Repeat("0", 12-n) + digits
+	case n == 13:
+		digits = "0" + digits
+	}
+	d, date, err := Parse(digits+frac, mode)
+	if err != nil {
+		return DateTime{}, false, fmt.Errorf("Incorrect datetime value: %s", s)
+	}
+	return d, date, nil
+}
+
+func atoi(s string) (int, bool) {
+	if s == "" || strings.Trim(s, "0123456789") != "" {
+		return 0, false
+	}
+	n, err := strconv.Atoi(s)
+	return n, err == nil
+}
+
+func fullYear(s string, year int) int {
+	if len(s) <= 2 {
+		if year < 70 {
+			return year + 2000
+		}
+		return year + 1900
+	}
+	return year
+}
+
+// Microseconds of fractional part without `.', digits after 6 are truncated.
+func parseFraction(s string) (int, bool) {
+	if s == "" {
+		return 0, true
+	}
+	if len(s) > MAX_FSP {
+		s = s[:MAX_FSP]
+	}
+	n, ok := atoi(s)
+	return n * pow10(MAX_FSP-len(s)), ok
+}
+
+func parseDelimited(s string) (DateTime, bool, bool) {
+	datePart, timePart := s, ""
+	if i := strings.IndexAny(s, " T"); i >= 0 {
+		datePart, timePart = s[:i], strings.TrimSpace(s[i+1:])
+	}
+
+	fields := strings.FieldsFunc(datePart, func(r rune) bool {
+		return r == '-' || r == '/'
+	})
+	if len(fields) != 3 || strings.IndexByte(datePart, ':') >= 0 {
+		return DateTime{}, false, false
+	}
+	var parts [3]int
+	for i, field := range fields {
+		n, ok := atoi(field)
+		if !ok {
+			return DateTime{}, false, false
+		}
+		parts[i] = n
+	}
+	d := Date(fullYear(fields[0], parts[0]), parts[1], parts[2])
+	if timePart == "" {
+		return d, true, true
+	}
+
+	clock, ok := parseClock(timePart)
+	if !ok || clock >= DAY {
+		return DateTime{}, false, false
+	}
+	return New(d.year, d.month, d.day, clock.Hour(), clock.Minute(), clock.Second(),
+		clock.Microsecond()), false, true
+}
+
+// 'hh:mm:ss.ffffff' or 'hh:mm'
+func parseClock(s string) (Duration, bool) {
+	frac := ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		s, frac = s[:i], s[i+1:]
+	}
+	fields := strings.Split(s, ":")
+	if len(fields) < 2 || len(fields) > 3 {
+		return 0, false
+	}
+	var parts [3]int
+	for i, field := range fields {
+		n, ok := atoi(field)
+		if !ok || (i > 0 && n > 59) {
+			return 0, false
+		}
+		parts[i] = n
+	}
+	micro, ok := parseFraction(frac)
+	if !ok || (frac != "" && len(fields) != 3) {
+		return 0, false
+	}
+	return NewDuration(parts[0], parts[1], parts[2], micro), true
+}
+
+func parseDigits(s string) (DateTime, bool, bool) {
+	frac := ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		s, frac = s[:i], s[i+1:]
+	}
+	yearDigits := 4
+	switch len(s) {
+	case 6, 12:
+		yearDigits = 2
+	case 8, 14:
+	default:
+		return DateTime{}, false, false
+	}
+	if _, ok := atoi(s); !ok {
+		return DateTime{}, false, false
+	}
+	micro, ok := parseFraction(frac)
+	if !ok || (frac != "" && len(s) <= 8) {
+		return DateTime{}, false, false
+	}
+
+	n := func(i, j int) int {
+		r, _ := strconv.Atoi(s[i:j])
+		return r
+	}
+	y := yearDigits
+	d := Date(fullYear(s[:y], n(0, y)), n(y, y+2), n(y+2, y+4))
+	if len(s) <= 8 {
+		return d, true, true
+	}
+	return New(d.year, d.month, d.day, n(y+4, y+6), n(y+6, y+8), n(y+8, y+10), micro),
+		false, true
+}
+
+//------------------------------------------------------------------------------
+// Formatting
+//------------------------------------------------------------------------------
+var monthNames = []string{
+	"January", "February", "March", "April", "May", "June", "July", "August",
+	"September", "October", "November", "December",
+}
+
+// Format like DATE_FORMAT of MySQL, supported specifiers are:
+//
+// %Y %y %m %c %M %b %d %e %j %W %a %w %H %k %h %I %l %i %s %S %f %p %T %r %%
+//
+// Others are the character after `%'. Names of weekday are empty if there is
+// zero in date.
+func (self DateTime) Format(layout string) string {
+	var buf strings.Builder
+	t, valid := self.Time()
+	hour12 := self.hour % 12
+	if hour12 == 0 {
+		hour12 = 12
+	}
+	ampm := "AM"
+	if self.hour >= 12 {
+		ampm = "PM"
+	}
+	month := ""
+	if self.month >= 1 && self.month <= 12 {
+		month = monthNames[self.month-1]
+	}
+
+	for i := 0; i < len(layout); i++ {
+		c := layout[i]
+		if c != '%' || i+1 == len(layout) {
+			buf.WriteByte(c)
+			continue
+		}
+		i++
+		switch layout[i] {
+		case 'Y':
+			fmt.Fprintf(&buf, "%04d", self.year)
+		case 'y':
+			fmt.Fprintf(&buf, "%02d", self.year%100)
+		case 'm':
+			fmt.Fprintf(&buf, "%02d", self.month)
+		case 'c':
+			fmt.Fprintf(&buf, "%d", self.month)
+		case 'M':
+			buf.WriteString(month)
+		case 'b':
+			if month != "" {
+				buf.WriteString(month[:3])
+			}
+		case 'd':
+			fmt.Fprintf(&buf, "%02d", self.day)
+		case 'e':
+			fmt.Fprintf(&buf, "%d", self.day)
+		case 'j':
+			if valid {
+				fmt.Fprintf(&buf, "%03d", t.YearDay())
+			}
+		case 'W':
+			if valid {
+				buf.WriteString(t.Weekday().String())
+			}
+		case 'a':
+			if valid {
+				buf.WriteString(t.Weekday().String()[:3])
+			}
+		case 'w':
+			if valid {
+				fmt.Fprintf(&buf, "%d", t.Weekday())
+			}
+		case 'H':
+			fmt.Fprintf(&buf, "%02d", self.hour)
+		case 'k':
+			fmt.Fprintf(&buf, "%d", self.hour)
+		case 'h', 'I':
+			fmt.Fprintf(&buf, "%02d", hour12)
+		case 'l':
+			fmt.Fprintf(&buf, "%d", hour12)
+		case 'i':
+			fmt.Fprintf(&buf, "%02d", self.minute)
+		case 's', 'S':
+			fmt.Fprintf(&buf, "%02d", self.second)
+		case 'f':
+			fmt.Fprintf(&buf, "%06d", self.micro)
+		case 'p':
+			buf.WriteString(ampm)
+		case 'T':
+			fmt.Fprintf(&buf, "%02d:%02d:%02d", self.hour, self.minute, self.second)
+		case 'r':
+			fmt.Fprintf(&buf, "%02d:%02d:%02d %s", hour12, self.minute, self.second, ampm)
+		default:
+			buf.WriteByte(layout[i])
+		}
+	}
+	return buf.String()
+}
+
+//------------------------------------------------------------------------------
+// Duration
+//------------------------------------------------------------------------------
+
+// Duration is TIME value in microseconds, it can be negative or more than 24
+// hours like MySQL: '-838:59:59' to '838:59:59'.
+type Duration int64
+
+const MAX_DURATION Duration = 838*HOUR + 59*MINUTE + 59*SECOND
+
+func NewDuration(hour, minute, second, micro int) Duration {
+	return Duration(int64(hour)*HOUR + int64(minute)*MINUTE + int64(second)*SECOND +
+		int64(micro))
+}
+
+func (self Duration) abs() int64 {
+	if self < 0 {
+		return -int64(self)
+	}
+	return int64(self)
+}
+
+// Parts of absolute value, hours can be more than 24.
+func (self Duration) Hour() int        { return int(self.abs() / HOUR) }
+func (self Duration) Minute() int      { return int(self.abs() % HOUR / MINUTE) }
+func (self Duration) Second() int      { return int(self.abs() % MINUTE / SECOND) }
+func (self Duration) Microsecond() int { return int(self.abs() % SECOND) }
+
+// Number like -120304 for '-12:03:04', microseconds are not included.
+func (self Duration) Int64() int64 {
+	n := int64(self.Hour())*10000 + int64(self.Minute())*100 + int64(self.Second())
+	if self < 0 {
+		return -n
+	}
+	return n
+}
+
+// '-12:03:04', microseconds are in 6 digits if it is not 0.
+func (self Duration) String() string {
+	sign := ""
+	if self < 0 {
+		sign = "-"
+	}
+	s := fmt.Sprintf("%s%02d:%02d:%02d", sign, self.Hour(), self.Minute(), self.Second())
+	if micro := self.Microsecond(); micro != 0 {
+		s += fmt.Sprintf(".%06d", micro)
+	}
+	return s
+}
+
+// Round microseconds to fsp digits, half away from zero.
+func (self Duration) Round(fsp int) Duration {
+	unit := int64(pow10(MAX_FSP - fsp))
+	r := (self.abs() + unit/2) / unit * unit
+	if r > int64(MAX_DURATION) {
+		r = int64(MAX_DURATION)
+	}
+	if self < 0 {
+		return Duration(-r)
+	}
+	return Duration(r)
+}
+
+// Parse TIME like '12:03:04.5', '-1 02:03' (days and hours), '12:03' or
+// compact digits '120304.5', '0304' and '04'.
+func ParseDuration(s string) (Duration, error) {
+	input := s
+	bad := fmt.Errorf("Incorrect time value: '%s'", input)
+	s = strings.TrimSpace(s)
+
+	negative := strings.HasPrefix(s, "-")
+	if negative {
+		s = s[1:]
+	}
+
+	var d Duration
+	var ok bool
+	switch {
+	case strings.IndexByte(s, ' ') >= 0:
+		i := strings.IndexByte(s, ' ')
+		days, valid := atoi(s[:i])
+		rest := strings.TrimSpace(s[i+1:])
+		if !strings.Contains(rest, ":") {
+			rest += ":00"
+		}
+		d, ok = parseClock(rest)
+		ok = ok && valid && days <= 34
+		d += Duration(days) * DAY
+
+	case strings.IndexByte(s, ':') >= 0:
+		d, ok = parseClock(s)
+
+	default:
+		frac := ""
+		if i := strings.IndexByte(s, '.'); i >= 0 {
+			s, frac = s[:i], s[i+1:]
+		}
+		if len(s) > 0 && len(s) < 7 {
+			s = strings.Repeat("0", 6-len(s)) + s
+		}
+		var micro, hour, minute, second int
+		var ok1, ok2, ok3, ok4 bool
+		micro, ok1 = parseFraction(frac)
+		if len(s) >= 6 {
+			hour, ok2 = atoi(s[:len(s)-4])
+			minute, ok3 = atoi(s[len(s)-4 : len(s)-2])
+			second, ok4 = atoi(s[len(s)-2:])
+		}
+		ok = ok1 && ok2 && ok3 && ok4 && minute < 60 && second < 60
+		d = NewDuration(hour, minute, second, micro)
+	}
+
+	if !ok || d > MAX_DURATION {
+		return 0, bad
+	}
+	if negative {
+		d = -d
+	}
+	return d, nil
+}
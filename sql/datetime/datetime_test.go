@@ -0,0 +1,237 @@
+package datetime
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	for input, expected := range map[string]string{
+		"2020-01-02":                 "2020-01-02 00:00:00",
+		" 2020/1/2 ":                 "2020-01-02 00:00:00",
+		"20-01-02":                   "2020-01-02 00:00:00",
+		"99-12-31 23:59:59":          "1999-12-31 23:59:59",
+		"2020-01-02T03:04":           "2020-01-02 03:04:00",
+		"2020-01-02 03:04:05.5":      "2020-01-02 03:04:05.500000",
+		"2020-01-02 03:04:05.123456": "2020-01-02 03:04:05.123456",
+		"20200102":                   "2020-01-02 00:00:00",
+		"200102030405":               "2020-01-02 03:04:05",
+		"20200102030405.25":          "2020-01-02 03:04:05.250000",
+		"2020-02-29":                 "2020-02-29 00:00:00",
+		"0000-00-00":                 "0000-00-00 00:00:00",
+		"2020-00-01":                 "2020-00-01 00:00:00",
+	} {
+		d, _, err := Parse(input, ZERO_ALLOW)
+		if err != nil {
+			t.Fatal(input, err)
+		}
+		if d.String() != expected {
+			t.Fatalf("%s: expected %s, but %s", input, expected, d)
+		}
+	}
+
+	for _, bad := range []string{"", "2020", "2020-01", "2019-02-29", "2020-13-01",
+		"2020-01-32", "2020-01-02 24:00:00", "2020-01-02 03:60", "abc", "2020-01-0x",
+		"2020010", "12:30:00"} {
+		if _, _, err := Parse(bad, ZERO_ALLOW); err == nil {
+			t.Fatal(bad)
+		}
+	}
+
+	if _, date, _ := Parse("2020-01-02", ZERO_ALLOW); !date {
+		t.Fatal("fail")
+	}
+	if _, date, _ := Parse("2020-01-02 00:00:00", ZERO_ALLOW); date {
+		t.Fatal("fail")
+	}
+
+	for input, expected := range map[string]string{
+		"101":                 "2000-01-01 00:00:00",
+		"991231":              "1999-12-31 00:00:00",
+		"20200102":            "2020-01-02 00:00:00",
+		"200102030405":        "2020-01-02 03:04:05",
+		"20200102030405.5000": "2020-01-02 03:04:05.500000",
+	} {
+		d, _, err := ParseNumber(input, ZERO_ALLOW)
+		if err != nil || d.String() != expected {
+			t.Fatalf("%s: expected %s, but %s %v", input, expected, d, err)
+		}
+	}
+}
+
+func TestZeroMode(t *testing.T) {
+	for _, c := range []struct {
+		input string
+		mode  ZeroMode
+		ok    bool
+	}{
+		{"0000-00-00", ZERO_ALLOW, true},
+		{"0000-00-00", ZERO_DATE, true},
+		{"0000-00-00", ZERO_NONE, false},
+		{"2020-00-01", ZERO_ALLOW, true},
+		{"2020-01-00", ZERO_DATE, false},
+		{"2020-00-00", ZERO_NONE, false},
+		{"0000-01-01", ZERO_NONE, true},
+	} {
+		if _, _, err := Parse(c.input, c.mode); (err == nil) != c.ok {
+			t.Fatalf("%s in %s: %v", c.input, c.mode, err)
+		}
+	}
+	if _, _, err := ParseNumber("0", ZERO_NONE); err == nil {
+		t.Fatal("fail")
+	}
+}
+
+func TestDuration(t *testing.T) {
+	for input, expected := range map[string]string{
+		"12:03:04":     "12:03:04",
+		"-12:03:04.5":  "-12:03:04.500000",
+		"12:03":        "12:03:00",
+		"838:59:59":    "838:59:59",
+		"1 02:03":      "26:03:00",
+		"-2 3":         "-51:00:00",
+		"120304":       "12:03:04",
+		"0304":         "00:03:04",
+		"4":            "00:00:04",
+		"1000304.25":   "100:03:04.250000",
+		"00:00:00.001": "00:00:00.001000",
+	} {
+		d, err := ParseDuration(input)
+		if err != nil {
+			t.Fatal(input, err)
+		}
+		if d.String() != expected {
+			t.Fatalf("%s: expected %s, but %s", input, expected, d)
+		}
+	}
+	for _, bad := range []string{"", "839:00:00", "12:60:00", "0360", "abc", "1:2:3:4"} {
+		if _, err := ParseDuration(bad); err == nil {
+			t.Fatal(bad)
+		}
+	}
+
+	d := NewDuration(1, 2, 3, 500000)
+	if d.Int64() != 10203 || (-d).Int64() != -10203 || d.Round(0).String() != "01:02:04" {
+		t.Fatal(d)
+	}
+	if MAX_DURATION.Round(0) != MAX_DURATION || (MAX_DURATION+600000).Round(0) != MAX_DURATION {
+		t.Fatal("fail")
+	}
+}
+
+func TestInterval(t *testing.T) {
+	for _, c := range []struct {
+		value, unit    string
+		months, micros int64
+	}{
+		{"1", "DAY", 0, DAY},
+		{"-2", "week", 0, -14 * DAY},
+		{"1.5", "SECOND", 0, 1500000},
+		{"1.5", "HOUR", 0, 2 * HOUR},
+		{"3", "QUARTER", 9, 0},
+		{"1-6", "YEAR_MONTH", 18, 0},
+		{"1 02:03:04", "DAY_SECOND", 0, DAY + 2*HOUR + 3*MINUTE + 4*SECOND},
+		{"1:02", "DAY_SECOND", 0, MINUTE + 2*SECOND},
+		{"-1:30", "HOUR_MINUTE", 0, -(HOUR + 30*MINUTE)},
+		{"5.000010", "SECOND_MICROSECOND", 0, 5*SECOND + 10},
+	} {
+		iv, err := ParseInterval(c.value, c.unit)
+		if err != nil {
+			t.Fatal(c.value, c.unit, err)
+		}
+		if iv.Months != c.months || iv.Micros != c.micros {
+			t.Fatalf("INTERVAL '%s' %s: unexpected %s", c.value, c.unit, iv)
+		}
+	}
+
+	if _, err := ParseInterval("1", "FORTNIGHT"); err == nil {
+		t.Fatal("fail")
+	}
+	for _, bad := range []string{"", "abc", "1 2 3 4 5"} {
+		if _, err := ParseInterval(bad, "DAY_SECOND"); err == nil {
+			t.Fatal(bad)
+		}
+	}
+	if _, err := ParseInterval("99999999999999999", "DAY"); err == nil {
+		t.Fatal("fail")
+	}
+	if !IsUnit("day_hour") || IsUnit("DAYS") {
+		t.Fatal("fail")
+	}
+}
+
+func TestAdd(t *testing.T) {
+	for _, c := range []struct {
+		input    string
+		iv       Interval
+		expected string
+	}{
+		{"2020-01-31", Interval{Months: 1}, "2020-02-29 00:00:00"},
+		{"2020-01-31", Interval{Months: -2}, "2019-11-30 00:00:00"},
+		{"2020-12-31 23:59:59", Interval{Micros: SECOND}, "2021-01-01 00:00:00"},
+		{"2020-03-01", Interval{Micros: -1}, "2020-02-29 23:59:59.999999"},
+		{"2020-03-01 12:00:00", Interval{Micros: -3*DAY - 13*HOUR}, "2020-02-26 23:00:00"},
+		{"2020-01-01", Interval{Months: 12, Micros: 10 * DAY}, "2021-01-11 00:00:00"},
+	} {
+		d, _, _ := Parse(c.input, ZERO_ALLOW)
+		r, err := d.Add(c.iv)
+		if err != nil || r.String() != c.expected {
+			t.Fatalf("%s + %s: expected %s, but %s %v", c.input, c.iv, c.expected, r, err)
+		}
+	}
+
+	for _, c := range []struct {
+		input string
+		iv    Interval
+	}{
+		{"0000-00-00", Interval{Micros: DAY}},
+		{"2020-00-01", Interval{Months: 1}},
+		{"9999-12-31", Interval{Micros: DAY}},
+		{"0001-01-01", Interval{Months: -1}},
+		{"2020-01-01", Interval{Micros: 1 << 62}},
+	} {
+		d, _, _ := Parse(c.input, ZERO_ALLOW)
+		if _, err := d.Add(c.iv); err == nil {
+			t.Fatal(c.input, c.iv)
+		}
+	}
+
+	d, _, _ := Parse("2020-12-31 23:59:59.9999996", ZERO_ALLOW)
+	if d.Round(MAX_FSP).String() != "2020-12-31 23:59:59.999999" {
+		t.Fatal(d)
+	}
+	d, _, _ = Parse("2020-12-31 23:59:59.5", ZERO_ALLOW)
+	if d.Round(0).String() != "2021-01-01 00:00:00" {
+		t.Fatal(d.Round(0))
+	}
+
+	a, _, _ := Parse("2020-03-01", ZERO_ALLOW)
+	b, _, _ := Parse("2020-02-01 23:00:00", ZERO_ALLOW)
+	x, _ := a.DayNumber()
+	y, _ := b.DayNumber()
+	if x-y != 29 || a.Compare(b) != 1 || b.Compare(a) != -1 || a.Compare(a.Date()) != 0 {
+		t.Fatal(x, y)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	d := New(2020, 1, 2, 15, 4, 5, 600)
+	layout := "%Y-%m-%d %H:%i:%s.%f|%y %c/%e %k %h %l %p|%M %b %W %a %w %j|%T %r|%% %x"
+	expected := "2020-01-02 15:04:05.000600|20 1/2 15 03 3 PM|" +
+		"January Jan Thursday Thu 4 002|15:04:05 03:04:05 PM|% x"
+	if s := d.Format(layout); s != expected {
+		t.Fatal(s)
+	}
+	if s := (DateTime{}).Format("%Y-%m-%d %W%M"); s != "0000-00-00 " {
+		t.Fatal(s)
+	}
+
+	now := time.Date(2020, 1, 2, 3, 4, 5, 6000, time.UTC)
+	d = FromTime(now)
+	if d.String() != "2020-01-02 03:04:05.000006" || d.Int64() != 20200102030405 {
+		t.Fatal(d)
+	}
+	if tm, ok := FromTime(now).Time(); !ok || !tm.Equal(now) {
+		t.Fatal(tm)
+	}
+}
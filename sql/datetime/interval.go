@@ -0,0 +1,148 @@
+package datetime
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/emptyland/akino/sql/decimal"
+)
+
+// Interval of date arithmetic: INTERVAL expr unit. Months and microseconds are
+// added separately, so '2020-01-31' + INTERVAL 1 MONTH is '2020-02-29'.
+type Interval struct {
+	Months int64
+	Micros int64
+}
+
+func (self Interval) Neg() Interval {
+	return Interval{Months: -self.Months, Micros: -self.Micros}
+}
+
+// Interval has hours, minutes, seconds or microseconds.
+func (self Interval) HasTime() bool {
+	return self.Micros%DAY != 0
+}
+
+func (self Interval) String() string {
+	return fmt.Sprintf("INTERVAL %d MONTH %d MICROSECOND", self.Months, self.Micros)
+}
+
+// Field of interval unit in months or microseconds.
+type field struct {
+	months bool
+	scale  int64
+}
+
+var (
+	fieldMicro   = field{scale: 1}
+	fieldSecond  = field{scale: SECOND}
+	fieldMinute  = field{scale: MINUTE}
+	fieldHour    = field{scale: HOUR}
+	fieldDay     = field{scale: DAY}
+	fieldWeek    = field{scale: 7 * DAY}
+	fieldMonth   = field{months: true, scale: 1}
+	fieldQuarter = field{months: true, scale: 3}
+	fieldYear    = field{months: true, scale: 12}
+)
+
+// Units like MySQL, value of composite unit is like '1 02:03:04' for
+// DAY_SECOND.
+var units = map[string][]field{
+	"MICROSECOND": {fieldMicro},
+	"SECOND":      {fieldSecond},
+	"MINUTE":      {fieldMinute},
+	"HOUR":        {fieldHour},
+	"DAY":         {fieldDay},
+	"WEEK":        {fieldWeek},
+	"MONTH":       {fieldMonth},
+	"QUARTER":     {fieldQuarter},
+	"YEAR":        {fieldYear},
+
+	"SECOND_MICROSECOND": {fieldSecond, fieldMicro},
+	"MINUTE_MICROSECOND": {fieldMinute, fieldSecond, fieldMicro},
+	"MINUTE_SECOND":      {fieldMinute, fieldSecond},
+	"HOUR_MICROSECOND":   {fieldHour, fieldMinute, fieldSecond, fieldMicro},
+	"HOUR_SECOND":        {fieldHour, fieldMinute, fieldSecond},
+	"HOUR_MINUTE":        {fieldHour, fieldMinute},
+	"DAY_MICROSECOND":    {fieldDay, fieldHour, fieldMinute, fieldSecond, fieldMicro},
+	"DAY_SECOND":         {fieldDay, fieldHour, fieldMinute, fieldSecond},
+	"DAY_MINUTE":         {fieldDay, fieldHour, fieldMinute},
+	"DAY_HOUR":           {fieldDay, fieldHour},
+	"YEAR_MONTH":         {fieldYear, fieldMonth},
+}
+
+// Name of unit is case-insensitive.
+func IsUnit(name string) bool {
+	_, found := units[strings.ToUpper(name)]
+	return found
+}
+
+// Interval of value in unit. Value of simple unit is a number, it is rounded
+// half away from zero except SECOND which can have microseconds. Value of
+// composite unit is numbers separated by any non-digits, missing leading
+// fields are 0: '1:02' in DAY_SECOND is 1 minute and 2 seconds.
+func ParseInterval(value, unit string) (Interval, error) {
+	fields, found := units[strings.ToUpper(unit)]
+	if !found {
+		return Interval{}, fmt.Errorf("Unknown INTERVAL unit %s", unit)
+	}
+	bad := fmt.Errorf("Incorrect INTERVAL value: '%s'", value)
+
+	var iv Interval
+	if len(fields) == 1 {
+		d, err := decimal.Parse(value)
+		if err != nil {
+			return Interval{}, bad
+		}
+		if fields[0] == fieldSecond {
+			d = d.Mul(decimal.FromInt(SECOND))
+			fields = []field{fieldMicro}
+		}
+		n, ok := d.Int64()
+		if !ok || !iv.add(fields[0], n) {
+			return Interval{}, bad
+		}
+		return iv, nil
+	}
+
+	s := strings.TrimSpace(value)
+	negative := strings.HasPrefix(s, "-")
+	if negative {
+		s = s[1:]
+	}
+	numbers := strings.FieldsFunc(s, func(r rune) bool {
+		return r < '0' || r > '9'
+	})
+	if len(numbers) == 0 || len(numbers) > len(fields) {
+		return Interval{}, bad
+	}
+	fields = fields[len(fields)-len(numbers):]
+	for i, number := range numbers {
+		n, ok := atoi(number)
+		if !ok || !iv.add(fields[i], int64(n)) {
+			return Interval{}, bad
+		}
+	}
+	if negative {
+		iv = iv.Neg()
+	}
+	return iv, nil
+}
+
+// Returns false if overflow.
+func (self *Interval) add(f field, n int64) bool {
+	if n > math.MaxInt64/f.scale || n < math.MinInt64/f.scale {
+		return false
+	}
+	p := &self.Micros
+	if f.months {
+		p = &self.Months
+	}
+	r := *p + n*f.scale
+	if (n > 0 && r < *p) || (n < 0 && r > *p) {
+		return false
+	}
+	*p = r
+	return true
+}
@@ -0,0 +1,336 @@
+package decimal
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Limits of DECIMAL(M, D) like MySQL.
+const (
+	MAX_PRECISION     = 65
+	MAX_SCALE         = 30
+	DEFAULT_PRECISION = 10
+)
+
+type RoundingMode int
+
+const (
+	ROUND_HALF_UP   RoundingMode = iota // Half away from zero, like MySQL
+	ROUND_HALF_EVEN                     // Half to even, banker's rounding
+	ROUND_HALF_DOWN                     // Half toward zero
+	ROUND_UP                            // Away from zero
+	ROUND_DOWN                          // Toward zero, truncate
+	ROUND_CEILING                       // Toward positive infinity
+	ROUND_FLOOR                         // Toward negative infinity
+)
+
+var modeNames = []string{
+	"HALF_UP",
+	"HALF_EVEN",
+	"HALF_DOWN",
+	"UP",
+	"DOWN",
+	"CEILING",
+	"FLOOR",
+}
+
+func (self RoundingMode) String() string {
+	return modeNames[self]
+}
+
+// Decimal is unscaled * 10^-scale, the zero value is 0. It is immutable, all
+// operations return new one.
+type Decimal struct {
+	unscaled *big.Int
+	scale    int
+}
+
+var ten = big.NewInt(10)
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(ten, big.NewInt(int64(n)), nil)
+}
+
+func New(unscaled int64, scale int) Decimal {
+	return Decimal{unscaled: big.NewInt(unscaled), scale: scale}
+}
+
+func FromInt(i int64) Decimal {
+	return New(i, 0)
+}
+
+// Shortest decimal which is converted to the same float, so 0.1 is 0.1.
+func FromFloat(f float64) (Decimal, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Decimal{}, fmt.Errorf("Bad decimal value: %v", f)
+	}
+	return Parse(strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+// Rational rounded to scale.
+func FromRat(r *big.Rat, scale int, mode RoundingMode) Decimal {
+	num := new(big.Int).Mul(r.Num(), pow10(scale))
+	return Decimal{unscaled: divRound(num, r.Denom(), mode), scale: scale}
+}
+
+// Parse decimal like "-12.340" or "1.5e3", scale is digits after decimal point.
+func Parse(s string) (Decimal, error) {
+	input := s
+	s = strings.TrimSpace(s)
+	exp := 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		n, err := strconv.Atoi(s[i+1:])
+		if err != nil || n > 1000 || n < -1000 {
+			return Decimal{}, fmt.Errorf("Bad decimal value: %s", input)
+		}
+		s, exp = s[:i], n
+	}
+
+	digits := s
+	if len(digits) > 0 && (digits[0] == '-' || digits[0] == '+') {
+		digits = digits[1:]
+	}
+	scale := 0
+	if i := strings.IndexByte(digits, '.'); i >= 0 {
+		scale = len(digits) - i - 1
+		digits = digits[:i] + digits[i+1:]
+	}
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return Decimal{}, fmt.Errorf("Bad decimal value: %s", input)
+	}
+
+	unscaled, _ := new(big.Int).SetString(digits, 10)
+	if s[0] == '-' {
+		unscaled.Neg(unscaled)
+	}
+	scale -= exp
+	if scale < 0 {
+		unscaled.Mul(unscaled, pow10(-scale))
+		scale = 0
+	}
+	return Decimal{unscaled: unscaled, scale: scale}, nil
+}
+
+// Check precision and scale of DECIMAL(M, D).
+func CheckType(precision, scale int) error {
+	switch {
+	case precision > MAX_PRECISION:
+		return fmt.Errorf("Too big precision %d, maximum is %d", precision, MAX_PRECISION)
+	case scale > MAX_SCALE:
+		return fmt.Errorf("Too big scale %d, maximum is %d", scale, MAX_SCALE)
+	case scale > precision:
+		return fmt.Errorf("Scale %d is bigger than precision %d", scale, precision)
+	case precision < 1:
+		return fmt.Errorf("Bad precision %d", precision)
+	default:
+		return nil
+	}
+}
+
+//------------------------------------------------------------------------------
+// Properties
+//------------------------------------------------------------------------------
+func (self Decimal) int() *big.Int {
+	if self.unscaled == nil {
+		return new(big.Int)
+	}
+	return self.unscaled
+}
+
+func (self Decimal) Scale() int {
+	return self.scale
+}
+
+func (self Decimal) Sign() int {
+	return self.int().Sign()
+}
+
+func (self Decimal) IsZero() bool {
+	return self.Sign() == 0
+}
+
+// Digits before decimal point, 0 for 0.5.
+func (self Decimal) IntDigits() int {
+	n := len(new(big.Int).Abs(self.int()).String()) - self.scale
+	if n < 0 || (n == 1 && self.IsZero()) {
+		return 0
+	}
+	return n
+}
+
+// Digits before and after decimal point.
+func (self Decimal) Precision() int {
+	return self.IntDigits() + self.scale
+}
+
+func (self Decimal) String() string {
+	s := new(big.Int).Abs(self.int()).String()
+	if self.scale > 0 {
+		if len(s) <= self.scale {
+			s = strings.Repeat("0", self.scale-len(s)+1) + s
+		}
+		s = s[:len(s)-self.scale] + "." + s[len(s)-self.scale:]
+	}
+	if self.Sign() < 0 {
+		return "-" + s
+	}
+	return s
+}
+
+func (self Decimal) Rat() *big.Rat {
+	return new(big.Rat).SetFrac(self.int(), pow10(self.scale))
+}
+
+func (self Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(self.String(), 64)
+	return f
+}
+
+// Rounded half away from zero, returns false if out of range.
+func (self Decimal) Int64() (int64, bool) {
+	i := self.Round(0, ROUND_HALF_UP).int()
+	if !i.IsInt64() {
+		return 0, false
+	}
+	return i.Int64(), true
+}
+
+// The same value without trailing zeros after decimal point, 1.50 is 1.5.
+func (self Decimal) Normalize() Decimal {
+	u, scale := new(big.Int).Set(self.int()), self.scale
+	r := new(big.Int)
+	for scale > 0 {
+		q, _ := new(big.Int).QuoRem(u, ten, r)
+		if r.Sign() != 0 {
+			break
+		}
+		u, scale = q, scale-1
+	}
+	return Decimal{unscaled: u, scale: scale}
+}
+
+//------------------------------------------------------------------------------
+// Arithmetic
+//------------------------------------------------------------------------------
+
+// Unscaled values of both in the same scale.
+func align(a, b Decimal) (*big.Int, *big.Int, int) {
+	switch {
+	case a.scale < b.scale:
+		return new(big.Int).Mul(a.int(), pow10(b.scale-a.scale)), b.int(), b.scale
+	case a.scale > b.scale:
+		return a.int(), new(big.Int).Mul(b.int(), pow10(a.scale-b.scale)), a.scale
+	default:
+		return a.int(), b.int(), a.scale
+	}
+}
+
+func (self Decimal) Cmp(other Decimal) int {
+	a, b, _ := align(self, other)
+	return a.Cmp(b)
+}
+
+func (self Decimal) Neg() Decimal {
+	return Decimal{unscaled: new(big.Int).Neg(self.int()), scale: self.scale}
+}
+
+func (self Decimal) Abs() Decimal {
+	return Decimal{unscaled: new(big.Int).Abs(self.int()), scale: self.scale}
+}
+
+// Scale of result is the larger one.
+func (self Decimal) Add(other Decimal) Decimal {
+	a, b, scale := align(self, other)
+	return Decimal{unscaled: new(big.Int).Add(a, b), scale: scale}
+}
+
+func (self Decimal) Sub(other Decimal) Decimal {
+	a, b, scale := align(self, other)
+	return Decimal{unscaled: new(big.Int).Sub(a, b), scale: scale}
+}
+
+// Scale of result is the sum of scales.
+func (self Decimal) Mul(other Decimal) Decimal {
+	return Decimal{
+		unscaled: new(big.Int).Mul(self.int(), other.int()),
+		scale:    self.scale + other.scale,
+	}
+}
+
+// Quotient rounded to scale, returns error if other is zero.
+func (self Decimal) Quo(other Decimal, scale int, mode RoundingMode) (Decimal, error) {
+	if other.IsZero() {
+		return Decimal{}, fmt.Errorf("Division by 0")
+	}
+	// self / other = (a * 10^-sa) / (b * 10^-sb), result is r * 10^-scale
+	num, den := self.int(), other.int()
+	if n := scale + other.scale - self.scale; n >= 0 {
+		num = new(big.Int).Mul(num, pow10(n))
+	} else {
+		den = new(big.Int).Mul(den, pow10(-n))
+	}
+	return Decimal{unscaled: divRound(num, den, mode), scale: scale}, nil
+}
+
+// Round to digits after decimal point, negative scale rounds digits before
+// decimal point and scale of result is 0.
+func (self Decimal) Round(scale int, mode RoundingMode) Decimal {
+	switch {
+	case scale >= self.scale:
+		return Decimal{
+			unscaled: new(big.Int).Mul(self.int(), pow10(scale-self.scale)),
+			scale:    scale,
+		}
+	case scale >= 0:
+		return Decimal{unscaled: divRound(self.int(), pow10(self.scale-scale), mode), scale: scale}
+	default:
+		u := divRound(self.int(), pow10(self.scale-scale), mode)
+		return Decimal{unscaled: u.Mul(u, pow10(-scale)), scale: 0}
+	}
+}
+
+// Round to DECIMAL(precision, scale), returns error if the integer part has too
+// many digits.
+func (self Decimal) Fit(precision, scale int, mode RoundingMode) (Decimal, error) {
+	r := self.Round(scale, mode)
+	if r.IntDigits() > precision-scale {
+		return Decimal{}, fmt.Errorf("Out of range value %s for DECIMAL(%d, %d)", self,
+			precision, scale)
+	}
+	return r, nil
+}
+
+// Rounding num / den to integer.
+func divRound(num, den *big.Int, mode RoundingMode) *big.Int {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+	sign := num.Sign() * den.Sign()
+	half := new(big.Int).Lsh(new(big.Int).Abs(r), 1).Cmp(new(big.Int).Abs(den))
+
+	var away bool
+	switch mode {
+	case ROUND_HALF_UP:
+		away = half >= 0
+	case ROUND_HALF_EVEN:
+		away = half > 0 || (half == 0 && q.Bit(0) == 1)
+	case ROUND_HALF_DOWN:
+		away = half > 0
+	case ROUND_UP:
+		away = true
+	case ROUND_DOWN:
+		away = false
+	case ROUND_CEILING:
+		away = sign > 0
+	case ROUND_FLOOR:
+		away = sign < 0
+	}
+	if away {
+		q.Add(q, big.NewInt(int64(sign)))
+	}
+	return q
+}
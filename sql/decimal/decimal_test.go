@@ -0,0 +1,178 @@
+package decimal
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	for input, expected := range map[string]string{
+		"0":                                  "0",
+		"-12.340":                            "-12.340",
+		"+.5":                                "0.5",
+		"-0.05":                              "-0.05",
+		"1.5e3":                              "1500",
+		"1.25E-2":                            "0.0125",
+		" 007 ":                              "7",
+		"123456789012345678901234567890.123": "123456789012345678901234567890.123",
+	} {
+		d, err := Parse(input)
+		if err != nil {
+			t.Fatal(input, err)
+		}
+		if d.String() != expected {
+			t.Fatalf("%s: expected %s, but %s", input, expected, d)
+		}
+	}
+	for _, bad := range []string{"", "-", ".", "1.2.3", "abc", "1e", "1/2", "0x10"} {
+		if _, err := Parse(bad); err == nil {
+			t.Fatal(bad)
+		}
+	}
+
+	if d, _ := FromFloat(0.1); d.String() != "0.1" {
+		t.Fatal(d)
+	}
+	if d := FromRat(big.NewRat(2, 3), 4, ROUND_HALF_UP); d.String() != "0.6667" {
+		t.Fatal(d)
+	}
+	var zero Decimal
+	if zero.String() != "0" || !zero.IsZero() || zero.Cmp(New(0, 2)) != 0 {
+		t.Fatal(zero)
+	}
+}
+
+func TestProperties(t *testing.T) {
+	assertDigits(t, "12.340", 2, 5)
+	assertDigits(t, "0.05", 0, 2)
+	assertDigits(t, "-100", 3, 3)
+	assertDigits(t, "0", 0, 0)
+
+	if d := mustParse(t, "1.500").Normalize(); d.String() != "1.5" || d.Scale() != 1 {
+		t.Fatal(d)
+	}
+	if d := mustParse(t, "100").Normalize(); d.String() != "100" {
+		t.Fatal(d)
+	}
+	if i, ok := mustParse(t, "-2.5").Int64(); !ok || i != -3 {
+		t.Fatal(i, ok)
+	}
+	if _, ok := mustParse(t, "9223372036854775808").Int64(); ok {
+		t.Fatal("fail")
+	}
+	if f := mustParse(t, "0.1").Float64(); f != 0.1 {
+		t.Fatal(f)
+	}
+	if r := mustParse(t, "-0.25").Rat(); r.Cmp(big.NewRat(-1, 4)) != 0 {
+		t.Fatal(r)
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	a, b := mustParse(t, "0.1"), mustParse(t, "0.20")
+	assertDecimal(t, a.Add(b), "0.30")
+	assertDecimal(t, a.Sub(b), "-0.10")
+	assertDecimal(t, a.Mul(b), "0.020")
+	assertDecimal(t, b.Neg().Abs(), "0.20")
+	if a.Cmp(b) >= 0 || b.Cmp(mustParse(t, "0.2")) != 0 || a.Neg().Sign() != -1 {
+		t.Fatal("fail")
+	}
+
+	q, err := mustParse(t, "1").Quo(mustParse(t, "3"), 4, ROUND_HALF_UP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertDecimal(t, q, "0.3333")
+	q, _ = mustParse(t, "2.00").Quo(mustParse(t, "0.003"), 2, ROUND_HALF_UP)
+	assertDecimal(t, q, "666.67")
+	q, _ = mustParse(t, "-1").Quo(mustParse(t, "8"), 2, ROUND_HALF_EVEN)
+	assertDecimal(t, q, "-0.12")
+	if _, err = a.Quo(Decimal{}, 2, ROUND_HALF_UP); err == nil {
+		t.Fatal("fail")
+	}
+
+	// Sum of ten 0.1 is exactly 1 unlike float64
+	sum := FromInt(0)
+	for i := 0; i < 10; i++ {
+		sum = sum.Add(a)
+	}
+	if sum.Cmp(FromInt(1)) != 0 {
+		t.Fatal(sum)
+	}
+}
+
+func TestRound(t *testing.T) {
+	for _, c := range []struct {
+		input    string
+		scale    int
+		mode     RoundingMode
+		expected string
+	}{
+		{"2.345", 2, ROUND_HALF_UP, "2.35"},
+		{"-2.345", 2, ROUND_HALF_UP, "-2.35"},
+		{"2.345", 2, ROUND_HALF_EVEN, "2.34"},
+		{"2.355", 2, ROUND_HALF_EVEN, "2.36"},
+		{"2.345", 2, ROUND_HALF_DOWN, "2.34"},
+		{"2.3451", 2, ROUND_HALF_DOWN, "2.35"},
+		{"2.341", 2, ROUND_UP, "2.35"},
+		{"-2.349", 2, ROUND_DOWN, "-2.34"},
+		{"-2.341", 2, ROUND_CEILING, "-2.34"},
+		{"-2.341", 2, ROUND_FLOOR, "-2.35"},
+		{"2.5", 4, ROUND_HALF_UP, "2.5000"},
+		{"1250", -2, ROUND_HALF_UP, "1300"},
+		{"1249.9", -2, ROUND_HALF_UP, "1200"},
+		{"-1250", -2, ROUND_HALF_EVEN, "-1200"},
+	} {
+		if d := mustParse(t, c.input).Round(c.scale, c.mode); d.String() != c.expected {
+			t.Fatalf("ROUND(%s, %d) %s: expected %s, but %s", c.input, c.scale, c.mode,
+				c.expected, d)
+		}
+	}
+}
+
+func TestFit(t *testing.T) {
+	d, err := mustParse(t, "999.994").Fit(5, 2, ROUND_HALF_UP)
+	if err != nil || d.String() != "999.99" {
+		t.Fatal(d, err)
+	}
+	if _, err = mustParse(t, "999.995").Fit(5, 2, ROUND_HALF_UP); err == nil ||
+		!strings.Contains(err.Error(), "Out of range value 999.995 for DECIMAL(5, 2)") {
+		t.Fatal(err)
+	}
+	d, err = mustParse(t, "-0.5").Fit(1, 1, ROUND_HALF_UP)
+	if err != nil || d.String() != "-0.5" {
+		t.Fatal(d, err)
+	}
+
+	for _, c := range [][2]int{{66, 2}, {40, 31}, {5, 6}, {0, 0}} {
+		if err = CheckType(c[0], c[1]); err == nil {
+			t.Fatal(c)
+		}
+	}
+	if err = CheckType(MAX_PRECISION, MAX_SCALE); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func assertDigits(t *testing.T, input string, intDigits, precision int) {
+	d := mustParse(t, input)
+	if d.IntDigits() != intDigits || d.Precision() != precision {
+		t.Fatalf("%s: unexpected digits %d and precision %d", input, d.IntDigits(),
+			d.Precision())
+	}
+}
+
+func assertDecimal(t *testing.T, d Decimal, expected string) {
+	if d.String() != expected {
+		t.Fatalf("expected %s, but %s", expected, d)
+	}
+}
+
+func mustParse(t *testing.T, input string) Decimal {
+	d, err := Parse(input)
+	if err != nil {
+		t.Fatal(input, err)
+	}
+	return d
+}
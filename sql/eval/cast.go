@@ -0,0 +1,219 @@
+package eval
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/emptyland/akino/sql/ast"
+	"github.com/emptyland/akino/sql/datetime"
+	"github.com/emptyland/akino/sql/decimal"
+	"github.com/emptyland/akino/sql/token"
+)
+
+// Range of integer types: [signed min, signed max, unsigned max]
+var intRanges = map[token.Token][3]int64{
+	token.TINYINT:   {math.MinInt8, math.MaxInt8, math.MaxUint8},
+	token.SMALLINT:  {math.MinInt16, math.MaxInt16, math.MaxUint16},
+	token.MEDIUMINT: {-1 << 23, 1<<23 - 1, 1<<24 - 1},
+	token.INT:       {math.MinInt32, math.MaxInt32, math.MaxUint32},
+	token.INTEGER:   {math.MinInt32, math.MaxInt32, math.MaxUint32},
+	token.BIGINT:    {math.MinInt64, math.MaxInt64, math.MaxInt64},
+}
+
+// Range of TIMESTAMP: '1970-01-01 00:00:01' to '2038-01-19 03:14:07' UTC.
+var (
+	minTimestamp = datetime.New(1970, 1, 1, 0, 0, 1, 0)
+	maxTimestamp = datetime.New(2038, 1, 19, 3, 14, 7, 999999)
+)
+
+// Cast value to the type, NULL is always NULL. Returns error if value is out
+// of range of the type or can not be converted. Zero dates are allowed.
+func Cast(v Value, t *ast.Type) (Value, error) {
+	return castIn(v, t, datetime.ZERO_ALLOW)
+}
+
+// Cast value to the type in zero date mode of evaluator.
+func (self *Evaluator) Cast(v Value, t *ast.Type) (Value, error) {
+	return castIn(v, t, self.ZeroDate)
+}
+
+func castIn(v Value, t *ast.Type, mode datetime.ZeroMode) (Value, error) {
+	if v.IsNull() {
+		return v, nil
+	}
+
+	switch t.Kind {
+	case token.BOOL, token.BOOLEAN:
+		b, _ := v.Truth()
+		return Bool(b), nil
+
+	case token.TINYINT, token.SMALLINT, token.MEDIUMINT, token.INT, token.INTEGER, token.BIGINT:
+		i, ok := v.AsInt()
+		r := intRanges[t.Kind]
+		if !ok || (t.Unsigned && (i < 0 || i > r[2])) || (!t.Unsigned && (i < r[0] || i > r[1])) {
+			return Null(), fmt.Errorf("Out of range value %s for %s", v, typeName(t))
+		}
+		return Int(i), nil
+
+	case token.BIT:
+		i, ok := v.AsInt()
+		width := intWidth(t, 1)
+		if !ok || i < 0 || (width < 64 && i >= 1<<uint(width)) {
+			return Null(), fmt.Errorf("Out of range value %s for %s", v, typeName(t))
+		}
+		return Int(i), nil
+
+	case token.YEAR:
+		i, ok := v.AsInt()
+		switch {
+		case !ok:
+		case i > 0 && i < 70:
+			i += 2000 // Two digits year: 1-69 is 2001-2069
+		case i >= 70 && i < 100:
+			i += 1900 // 70-99 is 1970-1999
+		case i == 0 && v.Kind == KIND_STRING:
+			i = 2000 // '0' and '00' is 2000, but 0 is 0000
+		}
+		if !ok || (i != 0 && (i < 1901 || i > 2155)) {
+			return Null(), fmt.Errorf("Out of range value %s for YEAR", v)
+		}
+		return Int(i), nil
+
+	case token.FLOAT:
+		return Float(float64(float32(v.AsFloat()))), nil
+
+	case token.DOUBLE:
+		return Float(v.AsFloat()), nil
+
+	case token.DECIMAL:
+		precision, scale := DecimalType(t)
+		if err := decimal.CheckType(precision, scale); err != nil {
+			return Null(), err
+		}
+		d, err := v.AsDecimal().Fit(precision, scale, decimal.ROUND_HALF_UP)
+		if err != nil {
+			return Null(), fmt.Errorf("Out of range value %s for %s", v, typeName(t))
+		}
+		if t.Unsigned && d.Sign() < 0 {
+			return Null(), fmt.Errorf("Out of range value %s for %s", v, typeName(t))
+		}
+		return Decimal(d), nil
+
+	case token.DATE:
+		d, _, err := dateTimeOf(v, mode)
+		if err != nil {
+			return Null(), err
+		}
+		return Date(d), nil
+
+	case token.DATETIME, token.TIMESTAMP:
+		fsp := intWidth(t, 0)
+		if err := datetime.CheckFsp(fsp); err != nil {
+			return Null(), err
+		}
+		d, _, err := dateTimeOf(v, mode)
+		if err != nil {
+			return Null(), err
+		}
+		d = d.Round(fsp)
+		if t.Kind == token.TIMESTAMP && !d.IsZero() &&
+			(d.Compare(minTimestamp) < 0 || d.Compare(maxTimestamp) > 0) {
+			return Null(), fmt.Errorf("Out of range value %s for TIMESTAMP", v)
+		}
+		return DateTime(d), nil
+
+	case token.TIME:
+		fsp := intWidth(t, 0)
+		if err := datetime.CheckFsp(fsp); err != nil {
+			return Null(), err
+		}
+		d, err := durationOf(v)
+		if err != nil {
+			return Null(), err
+		}
+		return Time(d.Round(fsp)), nil
+
+	case token.CHAR, token.VARCHAR:
+		s := v.AsString()
+		if n := intWidth(t, -1); n >= 0 && utf8.RuneCountInString(s) > n {
+			s = string([]rune(s)[:n])
+		}
+		return String(s), nil
+
+	case token.TINYTEXT, token.TEXT, token.MEDIUMTEXT, token.LONGTEXT, token.JSON, token.SET:
+		return String(v.AsString()), nil
+
+	case token.ENUM:
+		s := v.AsString()
+		for _, value := range t.Values {
+			if strings.EqualFold(Unquote(value), s) {
+				return String(Unquote(value)), nil
+			}
+		}
+		return Null(), fmt.Errorf("Bad value %s for ENUM", v)
+
+	case token.BINARY:
+		b := []byte(v.AsString())
+		n := intWidth(t, 1)
+		if len(b) > n {
+			b = b[:n]
+		}
+		for len(b) < n {
+			b = append(b, 0) // Padded with 0x00 like MySQL
+		}
+		return Bytes(b), nil
+
+	case token.VARBINARY:
+		b := []byte(v.AsString())
+		if n := intWidth(t, -1); n >= 0 && len(b) > n {
+			b = b[:n]
+		}
+		return Bytes(b), nil
+
+	case token.TINYBLOB, token.BLOB, token.MEDIUMBLOB, token.LONGBLOB:
+		return Bytes([]byte(v.AsString())), nil
+
+	default:
+		return Null(), fmt.Errorf("Can not cast to %s", typeName(t))
+	}
+}
+
+// Precision and scale of DECIMAL(M, D), the default is DECIMAL(10, 0).
+func DecimalType(t *ast.Type) (int, int) {
+	precision := intWidth(t, decimal.DEFAULT_PRECISION)
+	scale := 0
+	if t.Decimal != nil {
+		scale, _ = strconv.Atoi(t.Decimal.Value)
+	}
+	return precision, scale
+}
+
+// Width of type, returns def if it is not declared.
+func intWidth(t *ast.Type, def int) int {
+	if t.Width == nil {
+		return def
+	}
+	n, err := strconv.Atoi(t.Width.Value)
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+func typeName(t *ast.Type) string {
+	name := t.Kind.String()
+	if t.Width != nil {
+		if t.Decimal != nil {
+			name = fmt.Sprintf("%s(%s, %s)", name, t.Width.Value, t.Decimal.Value)
+		} else {
+			name = fmt.Sprintf("%s(%s)", name, t.Width.Value)
+		}
+	}
+	if t.Unsigned {
+		name += " UNSIGNED"
+	}
+	return name
+}
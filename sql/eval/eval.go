@@ -0,0 +1,655 @@
+package eval
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/emptyland/akino/sql/ast"
+	"github.com/emptyland/akino/sql/collate"
+	"github.com/emptyland/akino/sql/datetime"
+	"github.com/emptyland/akino/sql/decimal"
+	"github.com/emptyland/akino/sql/pattern"
+	"github.com/emptyland/akino/sql/simplify"
+	"github.com/emptyland/akino/sql/token"
+)
+
+// Row provides values of column references: Identifier, `table.column' and
+// NEW.column or OLD.column in trigger.
+type Row interface {
+	Column(expr ast.Expr) (Value, error)
+}
+
+// Values of columns by name: "column", "table.column" or "NEW.column".
+type MapRow map[string]Value
+
+func (self MapRow) Column(expr ast.Expr) (Value, error) {
+	var name string
+	switch node := expr.(type) {
+	case *ast.Identifier:
+		_, name = node.Dequote()
+
+	case *ast.BinaryExpr:
+		lhs, _ := node.Lhs.(*ast.Identifier)
+		rhs, _ := node.Rhs.(*ast.Identifier)
+		if lhs == nil || rhs == nil || node.Op != token.DOT {
+			return Null(), fmt.Errorf("[%d] Bad column reference", expr.Pos())
+		}
+		_, table := lhs.Dequote()
+		_, column := rhs.Dequote()
+		name = table + "." + column
+
+	case *ast.PseudoColumn:
+		_, column := node.Column.Dequote()
+		name = node.Row + "." + column
+	}
+
+	if v, found := self[name]; found {
+		return v, nil
+	}
+	for key, v := range self {
+		if strings.EqualFold(key, name) {
+			return v, nil
+		}
+	}
+	return Null(), fmt.Errorf("[%d] Unknown column %s", expr.Pos(), name)
+}
+
+type Evaluator struct {
+	Funcs      *Functions
+	Vars       map[string]Value // User variables without `@'
+	Collations *collate.Registry
+
+	// Collation name of expression, strings are compared in binary if it is nil
+	// or returns empty. It can be Bound.Collation of binder.
+	Collation func(expr ast.Expr) string
+
+	// Run subquery of IN and scalar subquery, returns the first column of all
+	// rows. Subqueries are not supported if it is nil.
+	Subquery func(query ast.Query, row Row) ([]Value, error)
+
+	// Zero dates accepted by CAST and date/time literals, like NO_ZERO_DATE
+	// and NO_ZERO_IN_DATE of MySQL sql_mode.
+	ZeroDate datetime.ZeroMode
+
+	patterns map[likeKey]*pattern.Pattern // Compiled LIKE patterns
+}
+
+// New evaluator with built-in functions.
+func NewEvaluator() *Evaluator {
+	return &Evaluator{
+		Funcs:      Builtin(),
+		Vars:       make(map[string]Value),
+		Collations: collate.Builtin(),
+	}
+}
+
+// Evaluate expression for row, row can be nil for constant expressions.
+func (self *Evaluator) Eval(expr ast.Expr, row Row) (Value, error) {
+	switch node := expr.(type) {
+	case *ast.Literal:
+		return self.literal(node)
+
+	case *ast.Identifier:
+		if b, ok := node.BoolConst(); ok {
+			return Bool(b), nil
+		}
+		return self.column(node, row)
+
+	case *ast.PseudoColumn:
+		return self.column(node, row)
+
+	case *ast.Variable:
+		return self.Vars[strings.ToLower(node.Name)], nil
+
+	case *ast.UnaryExpr:
+		return self.evalUnary(node, row)
+
+	case *ast.BinaryExpr:
+		if node.Op == token.DOT {
+			return self.column(node, row)
+		}
+		return self.evalBinary(node, row)
+
+	case *ast.Condition:
+		return self.evalCondition(node, row)
+
+	case *ast.CastExpr:
+		v, err := self.Eval(node.Operand, row)
+		if err != nil {
+			return Null(), err
+		}
+		if v, err = self.Cast(v, &node.To); err != nil {
+			return Null(), fmt.Errorf("[%d] %v", node.Pos(), err)
+		}
+		return v, nil
+
+	case *ast.IntervalExpr:
+		v, err := self.Eval(node.Value, row)
+		if err != nil || v.IsNull() {
+			return v, err
+		}
+		iv, err := datetime.ParseInterval(v.AsString(), node.Unit)
+		if err != nil {
+			return Null(), fmt.Errorf("[%d] %v", node.Pos(), err)
+		}
+		return Interval(iv), nil
+
+	case *ast.CallExpr:
+		return self.evalCall(node, row)
+
+	case ast.Query:
+		values, err := self.subquery(node, row)
+		if err != nil {
+			return Null(), err
+		}
+		switch len(values) {
+		case 0:
+			return Null(), nil
+		case 1:
+			return values[0], nil
+		default:
+			return Null(), fmt.Errorf("[%d] Subquery returns more than 1 row", node.Pos())
+		}
+
+	default:
+		return Null(), fmt.Errorf("[%d] Can not evaluate expression: %T", expr.Pos(), expr)
+	}
+}
+
+func (self *Evaluator) column(expr ast.Expr, row Row) (Value, error) {
+	if row == nil {
+		return Null(), fmt.Errorf("[%d] Column reference without row", expr.Pos())
+	}
+	return row.Column(expr)
+}
+
+func (self *Evaluator) subquery(query ast.Query, row Row) ([]Value, error) {
+	if self.Subquery == nil {
+		return nil, fmt.Errorf("[%d] Subquery is not supported", query.Pos())
+	}
+	return self.Subquery(query, row)
+}
+
+// Date/time literal like DATE '2020-01-02' is checked in zero date mode.
+func (self *Evaluator) literal(lit *ast.Literal) (Value, error) {
+	t := &ast.Type{Kind: lit.Kind, Width: &ast.Literal{Value: "6"}} // Keep microseconds
+	switch lit.Kind {
+	case token.DATE, token.TIME:
+	case token.TIMESTAMP:
+		t.Kind = token.DATETIME
+	default:
+		return literalValue(lit)
+	}
+	v, err := self.Cast(String(Unquote(lit.Value)), t)
+	if err != nil {
+		return Null(), fmt.Errorf("[%d] %v", lit.Pos(), err)
+	}
+	return v, nil
+}
+
+// Value of string literal is quoted.
+func literalValue(lit *ast.Literal) (Value, error) {
+	switch lit.Kind {
+	case token.NULL:
+		return Null(), nil
+
+	case token.INT_LITERAL:
+		if i, err := strconv.ParseInt(lit.Value, 10, 64); err == nil {
+			return Int(i), nil
+		}
+		d, err := decimal.Parse(lit.Value) // Too big for BIGINT
+		if err != nil {
+			return Null(), fmt.Errorf("[%d] Bad integer literal %s", lit.Pos(), lit.Value)
+		}
+		return Decimal(d), nil
+
+	case token.FLOAT_LITERAL:
+		v, err := ParseDecimal(lit.Value)
+		if err != nil {
+			return Null(), fmt.Errorf("[%d] %v", lit.Pos(), err)
+		}
+		return v, nil
+
+	case token.STRING_LITERAL:
+		return String(Unquote(lit.Value)), nil
+
+	default:
+		return Null(), fmt.Errorf("[%d] Unexpected literal %s", lit.Pos(), lit.Value)
+	}
+}
+
+// Remove quotes of string literal: 'abc' or "abc".
+func Unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// The first declared collation of operands, nil for the binary collation.
+func (self *Evaluator) collation(operands ...ast.Expr) (*collate.Collation, error) {
+	if self.Collation == nil {
+		return nil, nil
+	}
+	for _, expr := range operands {
+		if _, ok := expr.(ast.ExprList); ok {
+			continue // Not hashable
+		}
+		if name := self.Collation(expr); name != "" {
+			coll, found := self.Collations.Lookup(name)
+			if !found {
+				return nil, fmt.Errorf("[%d] Unknown collation: '%s'", expr.Pos(), name)
+			}
+			return coll, nil
+		}
+	}
+	return nil, nil
+}
+
+//------------------------------------------------------------------------------
+// Operators
+//------------------------------------------------------------------------------
+func (self *Evaluator) evalUnary(expr *ast.UnaryExpr, row Row) (Value, error) {
+	v, err := self.Eval(expr.Operand, row)
+	if err != nil {
+		return Null(), err
+	}
+
+	switch expr.Op {
+	case token.IS_NULL:
+		return Bool(v.IsNull()), nil
+
+	case token.IS_NOT_NULL:
+		return Bool(!v.IsNull()), nil
+
+	case token.NOT:
+		b, null := v.Truth()
+		if null {
+			return Null(), nil
+		}
+		return Bool(!b), nil
+
+	case token.MINUS:
+		v, err = Negate(v)
+		if err != nil {
+			return Null(), fmt.Errorf("[%d] %v", expr.Pos(), err)
+		}
+		return v, nil
+
+	default:
+		return Null(), fmt.Errorf("[%d] Unexpected unary operator %s", expr.Pos(), expr.Op)
+	}
+}
+
+func (self *Evaluator) evalBinary(expr *ast.BinaryExpr, row Row) (Value, error) {
+	switch expr.Op {
+	case token.AND, token.OR:
+		return self.evalLogic(expr, row)
+
+	case token.IN:
+		return self.evalIn(expr, row)
+	}
+
+	lhs, err := self.Eval(expr.Lhs, row)
+	if err != nil {
+		return Null(), err
+	}
+	rhs, err := self.Eval(expr.Rhs, row)
+	if err != nil {
+		return Null(), err
+	}
+	if lhs.IsNull() || rhs.IsNull() {
+		return Null(), nil
+	}
+
+	var v Value
+	switch expr.Op {
+	case token.PLUS, token.MINUS, token.STAR, token.SLASH:
+		v, err = Arithmetic(expr.Op, lhs, rhs)
+
+	case token.EQ, token.NE, token.LT, token.LE, token.GT, token.GE:
+		var coll *collate.Collation
+		var c int
+		if coll, err = self.collation(expr.Lhs, expr.Rhs); err == nil {
+			if c, err = CompareCollate(lhs, rhs, coll); err == nil {
+				v = Bool(compareResult(expr.Op, c))
+			}
+		}
+
+	case token.LIKE:
+		var coll *collate.Collation
+		var pat *pattern.Pattern
+		if coll, err = self.collation(expr.Lhs, expr.Rhs); err == nil {
+			if pat, err = self.like(rhs.AsString(), coll == nil || coll.Fold()); err == nil {
+				v = Bool(pat.Match(lhs.AsString()))
+			}
+		}
+
+	default:
+		err = fmt.Errorf("Unexpected binary operator %s", expr.Op)
+	}
+	if err != nil {
+		return Null(), fmt.Errorf("[%d] %v", expr.Pos(), err)
+	}
+	return v, nil
+}
+
+func compareResult(op token.Token, c int) bool {
+	switch op {
+	case token.EQ:
+		return c == 0
+	case token.NE:
+		return c != 0
+	case token.LT:
+		return c < 0
+	case token.LE:
+		return c <= 0
+	case token.GT:
+		return c > 0
+	default:
+		return c >= 0
+	}
+}
+
+// Three-valued logic: FALSE AND NULL is FALSE, TRUE OR NULL is TRUE, others
+// with NULL are NULL.
+func (self *Evaluator) evalLogic(expr *ast.BinaryExpr, row Row) (Value, error) {
+	absorb := expr.Op == token.OR // FALSE for AND, TRUE for OR
+
+	lhs, err := self.Eval(expr.Lhs, row)
+	if err != nil {
+		return Null(), err
+	}
+	a, anull := lhs.Truth()
+	if !anull && a == absorb {
+		return Bool(absorb), nil
+	}
+
+	rhs, err := self.Eval(expr.Rhs, row)
+	if err != nil {
+		return Null(), err
+	}
+	b, bnull := rhs.Truth()
+	if !bnull && b == absorb {
+		return Bool(absorb), nil
+	}
+	if anull || bnull {
+		return Null(), nil
+	}
+	return Bool(!absorb), nil
+}
+
+// x IN (a, b, ...) is TRUE if any one equals x, NULL if no one equals x but
+// NULL is in the list, otherwise FALSE.
+func (self *Evaluator) evalIn(expr *ast.BinaryExpr, row Row) (Value, error) {
+	lhs, err := self.Eval(expr.Lhs, row)
+	if err != nil {
+		return Null(), err
+	}
+	if lhs.IsNull() {
+		return Null(), nil
+	}
+
+	// Collation of every value, the collation of left side is used first.
+	var values []Value
+	var colls []*collate.Collation
+	switch rhs := expr.Rhs.(type) {
+	case ast.ExprList:
+		for _, elem := range rhs {
+			v, err := self.Eval(elem, row)
+			if err != nil {
+				return Null(), err
+			}
+			coll, err := self.collation(expr.Lhs, elem)
+			if err != nil {
+				return Null(), err
+			}
+			values, colls = append(values, v), append(colls, coll)
+		}
+
+	case ast.Query:
+		coll, err := self.collation(expr.Lhs, rhs)
+		if err != nil {
+			return Null(), err
+		}
+		if values, err = self.subquery(rhs, row); err != nil {
+			return Null(), err
+		}
+		for range values {
+			colls = append(colls, coll)
+		}
+
+	default:
+		return Null(), fmt.Errorf("[%d] Bad operand of IN", expr.Pos())
+	}
+
+	null := false
+	for i, v := range values {
+		if v.IsNull() {
+			null = true
+			continue
+		}
+		c, err := CompareCollate(lhs, v, colls[i])
+		if err != nil {
+			return Null(), fmt.Errorf("[%d] %v", expr.Pos(), err)
+		}
+		if c == 0 {
+			return Int(1), nil
+		}
+	}
+	if null {
+		return Null(), nil
+	}
+	return Int(0), nil
+}
+
+func (self *Evaluator) evalCondition(expr *ast.Condition, row Row) (Value, error) {
+	var test Value
+	var err error
+	if expr.Case != nil {
+		if test, err = self.Eval(expr.Case, row); err != nil {
+			return Null(), err
+		}
+	}
+
+	for _, block := range expr.Blocks {
+		when, err := self.Eval(block.When, row)
+		if err != nil {
+			return Null(), err
+		}
+
+		matched := false
+		if expr.Case == nil {
+			b, null := when.Truth()
+			matched = b && !null
+		} else if !test.IsNull() && !when.IsNull() {
+			coll, err := self.collation(expr.Case, block.When)
+			if err != nil {
+				return Null(), err
+			}
+			c, err := CompareCollate(test, when, coll)
+			if err != nil {
+				return Null(), fmt.Errorf("[%d] %v", block.When.Pos(), err)
+			}
+			matched = c == 0
+		}
+		if matched {
+			return self.Eval(block.Then, row)
+		}
+	}
+
+	if expr.Else != nil {
+		return self.Eval(expr.Else, row)
+	}
+	return Null(), nil
+}
+
+func (self *Evaluator) evalCall(call *ast.CallExpr, row Row) (Value, error) {
+	_, name := call.Func.Dequote()
+	fn, found := self.Funcs.Lookup(name)
+	if !found {
+		return Null(), fmt.Errorf("[%d] Unknown function %s()", call.Pos(), strings.ToUpper(name))
+	}
+	if !fn.CheckArity(len(call.Args)) {
+		return Null(), fmt.Errorf("[%d] Function %s() takes %s argument(s), but %d given",
+			call.Pos(), fn.Name, fn.Arity(), len(call.Args))
+	}
+
+	args := make([]Value, len(call.Args))
+	for i, arg := range call.Args {
+		v, err := self.Eval(arg, row)
+		if err != nil {
+			return Null(), err
+		}
+		args[i] = v
+	}
+
+	v, err := fn.Call(args)
+	if err != nil {
+		return Null(), fmt.Errorf("[%d] %s(): %v", call.Pos(), fn.Name, err)
+	}
+	return v, nil
+}
+
+//------------------------------------------------------------------------------
+// Arithmetic
+//------------------------------------------------------------------------------
+
+// Like MySQL div_precision_increment.
+const DIV_PRECISION_INCREMENT = simplify.DIV_PRECISION_INCREMENT
+
+func Negate(v Value) (Value, error) {
+	switch v.Kind {
+	case KIND_NULL:
+		return v, nil
+	case KIND_INT:
+		if v.i == math.MinInt64 {
+			return Null(), fmt.Errorf("BIGINT value is out of range in -(%d)", v.i)
+		}
+		return Int(-v.i), nil
+	case KIND_DECIMAL:
+		return Decimal(v.d.Neg()), nil
+	case KIND_DATE, KIND_DATETIME, KIND_TIME, KIND_INTERVAL, KIND_BYTES:
+		return Null(), fmt.Errorf("Incompatible operand for -: %s", v.Kind)
+	default:
+		return Float(-v.AsFloat()), nil
+	}
+}
+
+// Arithmetic of non-NULL numbers: INT op INT is INT except `/', DECIMAL is
+// exact, FLOAT or strings make result FLOAT. Division by zero is NULL. Date
+// and INTERVAL are added like DATE_ADD().
+func Arithmetic(op token.Token, a, b Value) (Value, error) {
+	if a.IsNull() || b.IsNull() {
+		return Null(), nil
+	}
+	if a.Kind == KIND_INTERVAL || b.Kind == KIND_INTERVAL {
+		return intervalArithmetic(op, a, b)
+	}
+	for _, v := range []Value{a, b} {
+		if v.IsTemporal() || v.Kind == KIND_BYTES {
+			return Null(), fmt.Errorf("Incompatible operands for %s: %s and %s", op, a.Kind,
+				b.Kind)
+		}
+	}
+
+	switch {
+	case a.Kind == KIND_INT && b.Kind == KIND_INT && op != token.SLASH:
+		return intArithmetic(op, a.i, b.i)
+
+	case a.Kind == KIND_FLOAT || b.Kind == KIND_FLOAT || !a.IsNumeric() || !b.IsNumeric():
+		x, y := a.AsFloat(), b.AsFloat()
+		switch op {
+		case token.PLUS:
+			return Float(x + y), nil
+		case token.MINUS:
+			return Float(x - y), nil
+		case token.STAR:
+			return Float(x * y), nil
+		default:
+			if y == 0 {
+				return Null(), nil
+			}
+			return Float(x / y), nil
+		}
+
+	default:
+		x, y := a.AsDecimal(), b.AsDecimal()
+		var r decimal.Decimal
+		switch op {
+		case token.PLUS:
+			r = x.Add(y)
+		case token.MINUS:
+			r = x.Sub(y)
+		case token.STAR:
+			if r = x.Mul(y); r.Scale() > decimal.MAX_SCALE {
+				r = r.Round(decimal.MAX_SCALE, decimal.ROUND_HALF_UP)
+			}
+		default:
+			if y.IsZero() {
+				return Null(), nil
+			}
+			scale := x.Scale() + DIV_PRECISION_INCREMENT
+			if scale > decimal.MAX_SCALE {
+				scale = decimal.MAX_SCALE
+			}
+			r, _ = x.Quo(y, scale, decimal.ROUND_HALF_UP)
+		}
+		if r.Precision() > decimal.MAX_PRECISION {
+			return Null(), fmt.Errorf("DECIMAL value is out of range in (%s %s %s)", x, op, y)
+		}
+		return Decimal(r), nil
+	}
+}
+
+func intArithmetic(op token.Token, x, y int64) (Value, error) {
+	var r int64
+	overflow := false
+	switch op {
+	case token.PLUS:
+		r = x + y
+		overflow = (x > 0 && y > 0 && r < 0) || (x < 0 && y < 0 && r >= 0)
+	case token.MINUS:
+		r = x - y
+		overflow = (x >= 0 && y < 0 && r < 0) || (x < 0 && y > 0 && r >= 0)
+	case token.STAR:
+		r = x * y
+		overflow = x != 0 && (r/x != y || (x == -1 && y == math.MinInt64))
+	}
+	if overflow {
+		return Null(), fmt.Errorf("BIGINT value is out of range in (%d %s %d)", x, op, y)
+	}
+	return Int(r), nil
+}
+
+//------------------------------------------------------------------------------
+// LIKE
+//------------------------------------------------------------------------------
+
+// Escape character of LIKE pattern like MySQL.
+const LIKE_ESCAPE = '\\'
+
+type likeKey struct {
+	pattern string
+	fold    bool
+}
+
+// LIKE is case-insensitive unless the collation of operands is not, e.g.
+// BINARY or RTRIM. Patterns are compiled once.
+func (self *Evaluator) like(s string, fold bool) (*pattern.Pattern, error) {
+	key := likeKey{pattern: s, fold: fold}
+	if pat, found := self.patterns[key]; found {
+		return pat, nil
+	}
+	pat, err := pattern.CompileLike(s, LIKE_ESCAPE, fold)
+	if err != nil {
+		return nil, err
+	}
+	if self.patterns == nil {
+		self.patterns = make(map[likeKey]*pattern.Pattern)
+	}
+	self.patterns[key] = pat
+	return pat, nil
+}
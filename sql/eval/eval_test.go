@@ -0,0 +1,459 @@
+package eval
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emptyland/akino/binder"
+	"github.com/emptyland/akino/catalog"
+	"github.com/emptyland/akino/sql/ast"
+	"github.com/emptyland/akino/sql/collate"
+	"github.com/emptyland/akino/sql/datetime"
+	"github.com/emptyland/akino/sql/decimal"
+	"github.com/emptyland/akino/sql/function"
+	"github.com/emptyland/akino/sql/parser"
+	"github.com/emptyland/akino/sql/token"
+)
+
+func TestEvalArithmetic(t *testing.T) {
+	assertEval(t, "1 + 2 * 3", nil, "7")
+	assertEval(t, "7 / 2", nil, "3.5000")
+	assertEval(t, "1.5 * 2.25", nil, "3.375")
+	assertEval(t, "0.1 + 0.2", nil, "0.3")
+	assertEval(t, "CAST(1 AS DOUBLE) / 4", nil, "0.25")
+	assertEval(t, "'12abc' + 1", nil, "13")
+	assertEval(t, "-(2 - 5)", nil, "3")
+	assertEval(t, "1 / 0", nil, "NULL")
+	assertEval(t, "NULL + 1", nil, "NULL")
+	assertEval(t, "a * 2", MapRow{"a": Int(21)}, "42")
+	assertEval(t, "t.a - b", MapRow{"t.a": Int(1), "B": Float(0.5)}, "0.5")
+	assertEvalFail(t, "9223372036854775807 + 1", "BIGINT value is out of range")
+	assertEvalFail(t, "a + 1", "Column reference without row")
+	if _, err := NewEvaluator().Eval(mustParse(t, "t.b"), MapRow{"b": Int(1)}); err == nil ||
+		!strings.Contains(err.Error(), "Unknown column t.b") {
+		t.Fatal(err)
+	}
+}
+
+func TestEvalDecimal(t *testing.T) {
+	assertEval(t, "0.1 + 0.2 = 0.3", nil, "1")
+	assertEval(t, "1.10 + 2.205", nil, "3.305")
+	assertEval(t, "1.5 * 1.5", nil, "2.25")
+	assertEval(t, "10.00 / 3", nil, "3.333333")
+	assertEval(t, "1 / 3", nil, "0.3333")
+	assertEval(t, "1.50 = 1.5", nil, "1")
+	assertEval(t, "9223372036854775808 - 1", nil, "9223372036854775807")
+	assertEval(t, "0.1 + CAST(0.2 AS DOUBLE)", nil, "0.30000000000000004")
+	assertEval(t, "ROUND(-2.5)", nil, "-3")
+	assertEval(t, "FLOOR(-2.5) + CEIL(2.1)", nil, "0")
+	assertEval(t, "MOD(7.5, 2)", nil, "1.5")
+	assertEval(t, "MOD(-7.5, 2)", nil, "-1.5")
+	assertEval(t, "ABS(-0.50)", nil, "0.50")
+	assertEval(t, "-(-1.0)", nil, "1.0")
+
+	price := MapRow{"price": Decimal(mustDecimal(t, "19.99")), "qty": Int(3)}
+	assertEval(t, "price * qty", price, "59.97")
+	assertEval(t, "price * qty = 59.97", price, "1")
+
+	big := "99999999999999999999999999999999999999999999999999999999999999999"
+	assertEvalFail(t, big+" + 1", "DECIMAL value is out of range")
+	assertEvalFail(t, big+" * 10", "DECIMAL value is out of range")
+}
+
+func TestEvalLogic(t *testing.T) {
+	assertEval(t, "NULL AND 0", nil, "0")
+	assertEval(t, "NULL AND 1", nil, "NULL")
+	assertEval(t, "NULL OR 1", nil, "1")
+	assertEval(t, "NULL OR 0", nil, "NULL")
+	assertEval(t, "NOT NULL", nil, "NULL")
+	assertEval(t, "NOT 0", nil, "1")
+	assertEval(t, "TRUE AND NOT FALSE", nil, "1")
+	assertEval(t, "NULL = NULL", nil, "NULL")
+	assertEval(t, "NULL IS NULL", nil, "1")
+	assertEval(t, "a IS NOT NULL", MapRow{"a": Null()}, "0")
+	assertEval(t, "'abc' < 'abd'", nil, "1")
+	assertEval(t, "'10' = 10.0", nil, "1")
+	assertEval(t, "'2015-01-02' > CAST('2015-01-01 23:00:00' AS DATETIME)", nil, "1")
+
+	// Right side is not evaluated if left side decides result.
+	assertEval(t, "0 AND a", nil, "0")
+	assertEval(t, "1 OR a", nil, "1")
+}
+
+func TestEvalIn(t *testing.T) {
+	assertEval(t, "2 IN (1, 2, 3)", nil, "1")
+	assertEval(t, "4 IN (1, 2, 3)", nil, "0")
+	assertEval(t, "4 IN (1, NULL)", nil, "NULL")
+	assertEval(t, "1 IN (1, NULL)", nil, "1")
+	assertEval(t, "NULL IN (1, 2)", nil, "NULL")
+	assertEval(t, "NOT 4 IN (1, NULL)", nil, "NULL")
+
+	ev := NewEvaluator()
+	ev.Subquery = func(query ast.Query, row Row) ([]Value, error) {
+		return []Value{Int(1), Int(5)}, nil
+	}
+	v, err := ev.Eval(mustParse(t, "5 IN (SELECT a FROM t)"), nil)
+	if err != nil || v.String() != "1" {
+		t.Fatal(v, err)
+	}
+	assertEvalFail(t, "5 IN (SELECT a FROM t)", "Subquery is not supported")
+}
+
+func TestEvalLike(t *testing.T) {
+	assertEval(t, "'hello' LIKE 'h%o'", nil, "1")
+	assertEval(t, "'Hello' LIKE 'h_llo'", nil, "1")
+	assertEval(t, "'hello' LIKE 'h_o'", nil, "0")
+	assertEval(t, "'' LIKE '%'", nil, "1")
+	assertEval(t, "NULL LIKE '%'", nil, "NULL")
+	assertEval(t, "'10%' LIKE '10\\%'", nil, "1")
+	assertEval(t, "'100' LIKE '10\\%'", nil, "0")
+	assertEvalFail(t, "'a' LIKE 'a\\'", "escape character at end")
+}
+
+func TestEvalCase(t *testing.T) {
+	assertEval(t, "CASE WHEN NULL THEN 1 WHEN 2 > 1 THEN 2 ELSE 3", nil, "2")
+	assertEval(t, "CASE WHEN 0 THEN 1", nil, "NULL")
+	assertEval(t, "CASE a WHEN 1 THEN 'x' WHEN 2 THEN 'y' ELSE 'z'", MapRow{"a": Int(2)}, "'y'")
+	assertEval(t, "CASE a WHEN NULL THEN 'x' ELSE 'z'", MapRow{"a": Null()}, "'z'")
+}
+
+func TestEvalCast(t *testing.T) {
+	assertEval(t, "CAST('12' AS INT)", nil, "12")
+	assertEval(t, "CAST(2.5 AS INT)", nil, "3")
+	assertEval(t, "CAST(-2.5 AS BIGINT)", nil, "-3")
+	assertEval(t, "CAST(255 AS TINYINT UNSIGNED)", nil, "255")
+	assertEval(t, "CAST(1.005 AS DECIMAL(10, 2))", nil, "1.01")
+	assertEval(t, "CAST(-1.005 AS DECIMAL(10, 2))", nil, "-1.01")
+	assertEval(t, "CAST(1.5 AS DECIMAL)", nil, "2")
+	assertEval(t, "CAST('12.345abc' AS DECIMAL(5, 1))", nil, "12.3")
+	assertEval(t, "CAST(CAST(0.1 AS DOUBLE) AS DECIMAL(5, 3))", nil, "0.100")
+	assertEvalFail(t, "CAST(1000 AS DECIMAL(5, 2))", "Out of range value 1000 for DECIMAL(5, 2)")
+	assertEvalFail(t, "CAST(-1 AS DECIMAL(5, 2) UNSIGNED)", "Out of range value -1")
+	assertEvalFail(t, "CAST(1 AS DECIMAL(70, 2))", "Too big precision 70")
+	assertEval(t, "CAST(12345 AS CHAR(3))", nil, "'123'")
+	assertEval(t, "CAST('ab' AS BINARY(3))", nil, "x'616200'")
+	assertEval(t, "CAST(15 AS YEAR)", nil, "2015")
+	assertEval(t, "CAST('2015-01-02 03:04:05' AS DATE)", nil, "'2015-01-02'")
+	assertEval(t, "CAST('b' AS ENUM('a', 'b'))", nil, "'b'")
+	assertEval(t, "CAST(NULL AS INT)", nil, "NULL")
+	assertEvalFail(t, "CAST(128 AS TINYINT)", "Out of range value 128 for TINYINT")
+	assertEvalFail(t, "CAST(-1 AS INT UNSIGNED)", "Out of range value -1 for INT UNSIGNED")
+	assertEvalFail(t, "CAST('x' AS DATETIME)", "Incorrect datetime value: 'x'")
+	assertEvalFail(t, "CAST('c' AS ENUM('a', 'b'))", "Bad value 'c' for ENUM")
+}
+
+func TestEvalFunction(t *testing.T) {
+	assertEval(t, "ABS(-3)", nil, "3")
+	assertEval(t, "ROUND(2.345, 2)", nil, "2.35")
+	assertEval(t, "ROUND(1250, -2)", nil, "1300")
+	assertEval(t, "FLOOR(-1.5)", nil, "-2")
+	assertEval(t, "CEIL(1.2)", nil, "2")
+	assertEval(t, "MOD(-7, 3)", nil, "-1")
+	assertEval(t, "MOD(7, 0)", nil, "NULL")
+	assertEval(t, "SQRT(-1)", nil, "NULL")
+	assertEval(t, "GREATEST(1, 3, 2)", nil, "3")
+	assertEval(t, "LEAST(1, NULL)", nil, "NULL")
+	assertEval(t, "LENGTH('abc')", nil, "3")
+	assertEval(t, "UPPER('abc')", nil, "'ABC'")
+	assertEval(t, "SUBSTR('hello', 2, 3)", nil, "'ell'")
+	assertEval(t, "SUBSTRING('hello', -3)", nil, "'llo'")
+	assertEval(t, "CONCAT('a', 1, 'b')", nil, "'a1b'")
+	assertEval(t, "CONCAT('a', NULL)", nil, "NULL")
+	assertEval(t, "CONCAT_WS(',', 'a', NULL, 'b')", nil, "'a,b'")
+	assertEval(t, "LPAD('hi', 5, 'ab')", nil, "'abahi'")
+	assertEval(t, "REPLACE('abcb', 'b', 'x')", nil, "'axcx'")
+	assertEval(t, "HEX(255)", nil, "'FF'")
+	assertEval(t, "RAND(7) = RAND(7)", nil, "1")
+	assertEval(t, "RAND() >= 0 AND RAND() < 1", nil, "1")
+	assertEval(t, "RANDOM() IS NULL", nil, "0")
+	assertEval(t, "COALESCE(NULL, NULL, 2)", nil, "2")
+	assertEval(t, "NULLIF(1, 1)", nil, "NULL")
+	assertEval(t, "IIF(NULL, 1, 2)", nil, "2")
+	assertEval(t, "TYPEOF(1.5)", nil, "'decimal'")
+	assertEvalFail(t, "NOPE(1)", "Unknown function NOPE()")
+	assertEvalFail(t, "ABS(1, 2)", "Function ABS() takes 1 argument(s), but 2 given")
+	assertEvalFail(t, "COUNT(1)", "Unknown function COUNT()")
+}
+
+func TestEvalDateTime(t *testing.T) {
+	assertEval(t, "DATE '2020-01-02'", nil, "'2020-01-02'")
+	assertEval(t, "TIMESTAMP '2020-01-02 03:04:05.5'", nil, "'2020-01-02 03:04:05.500000'")
+	assertEval(t, "TIME '-1 02:03'", nil, "'-26:03:00'")
+	assertEval(t, "CAST('2020-01-02 03:04:05.5' AS DATETIME)", nil, "'2020-01-02 03:04:06'")
+	assertEval(t, "CAST(20200102030405 AS DATETIME(2))", nil, "'2020-01-02 03:04:05'")
+	assertEval(t, "CAST('12:30' AS TIME)", nil, "'12:30:00'")
+	assertEval(t, "CAST(DATE '2020-01-02' AS BIGINT)", nil, "20200102")
+	assertEvalFail(t, "TIMESTAMP '2020-01-02 03:04:05' + 0", "Incompatible operands for +")
+	assertEvalFail(t, "CAST('2020-01-02' AS DATETIME(7))", "Too big precision 7")
+	assertEvalFail(t, "CAST('1969-12-31' AS TIMESTAMP)", "Out of range value")
+	assertEvalFail(t, "DATE '2019-02-29'", "Incorrect datetime value: '2019-02-29'")
+	assertEvalFail(t, "TIME '12:60'", "Incorrect time value")
+
+	assertEval(t, "DATE '2020-01-02' = '2020-01-02 00:00:00'", nil, "1")
+	assertEval(t, "DATE '2020-01-02' < TIMESTAMP '2020-01-02 00:00:01'", nil, "1")
+	assertEval(t, "DATE '2020-01-02' = 20200102", nil, "1")
+	assertEval(t, "TIME '10:00' > '9:00'", nil, "1")
+	assertEval(t, "'2020-1-2' > DATE '2020-01-01' AND '2020-1-2' < DATE '2020-01-31'", nil, "1")
+	assertEvalFail(t, "TIME '10:00' = DATE '2020-01-02'", "Can not compare time with date")
+
+	assertEval(t, "DATE '2020-01-31' + INTERVAL 1 MONTH", nil, "'2020-02-29'")
+	assertEval(t, "INTERVAL 1 DAY + '2020-12-31'", nil, "'2021-01-01'")
+	assertEval(t, "DATE '2020-01-01' - INTERVAL 1 SECOND", nil, "'2019-12-31 23:59:59'")
+	assertEval(t, "DATE '2020-01-01' + INTERVAL '1 12' DAY_HOUR", nil, "'2020-01-02 12:00:00'")
+	assertEval(t, "TIME '10:00' + INTERVAL 30 MINUTE", nil, "'10:30:00'")
+	assertEval(t, "DATE_ADD('2020-01-01', INTERVAL 1.5 SECOND)", nil, "'2020-01-01 00:00:01.500000'")
+	assertEval(t, "DATE_SUB(DATE '2020-03-31', INTERVAL 1 MONTH)", nil, "'2020-02-29'")
+	assertEval(t, "DATE '9999-12-31' + INTERVAL 1 DAY", nil, "NULL")
+	assertEval(t, "'2020-00-01' + INTERVAL 1 DAY", nil, "NULL")
+	assertEval(t, "NULL + INTERVAL 1 DAY", nil, "NULL")
+	assertEval(t, "DATE '2020-01-01' + INTERVAL NULL DAY", nil, "NULL")
+	assertEvalFail(t, "INTERVAL 1 DAY - DATE '2020-01-01'", "Incompatible operands")
+	assertEvalFail(t, "DATE '2020-01-01' + INTERVAL 'x' DAY", "Incorrect INTERVAL value")
+	assertEvalFail(t, "-DATE '2020-01-01'", "Incompatible operand for -: date")
+}
+
+func TestEvalZeroDate(t *testing.T) {
+	for _, c := range []struct {
+		input string
+		mode  datetime.ZeroMode
+		ok    bool
+	}{
+		{"CAST('0000-00-00' AS DATE)", datetime.ZERO_ALLOW, true},
+		{"CAST('2020-00-01' AS DATE)", datetime.ZERO_ALLOW, true},
+		{"CAST('0000-00-00' AS DATE)", datetime.ZERO_DATE, true},
+		{"CAST('2020-00-01' AS DATE)", datetime.ZERO_DATE, false},
+		{"DATE '0000-00-00'", datetime.ZERO_NONE, false},
+		{"CAST(0 AS DATETIME)", datetime.ZERO_NONE, false},
+		{"CAST('0000-00-00' AS TIMESTAMP)", datetime.ZERO_ALLOW, true},
+	} {
+		ev := NewEvaluator()
+		ev.ZeroDate = c.mode
+		if _, err := ev.Eval(mustParse(t, c.input), nil); (err == nil) != c.ok {
+			t.Fatalf("%s in %s: %v", c.input, c.mode, err)
+		}
+	}
+	if v, _ := Cast(String("0000-00-00"), &ast.Type{Kind: token.DATE}); v.String() != "'0000-00-00'" {
+		t.Fatal(v)
+	}
+}
+
+func TestEvalDateFunction(t *testing.T) {
+	ev := NewEvaluator()
+	ev.Funcs.Now = func() time.Time {
+		return time.Date(2020, 1, 2, 3, 4, 5, 600000000, time.FixedZone("", 8*3600))
+	}
+	for input, expected := range map[string]string{
+		"NOW()":                "'2020-01-01 19:04:06'",
+		"CURRENT_TIMESTAMP":    "'2020-01-01 19:04:06'",
+		"CURRENT_TIMESTAMP(1)": "'2020-01-01 19:04:05.600000'",
+		"CURDATE()":            "'2020-01-01'",
+		"CURRENT_DATE":         "'2020-01-01'",
+		"CURRENT_TIME":         "'19:04:06'",
+		"UNIX_TIMESTAMP()":     "1577905445",
+		"TYPEOF(NOW())":        "'datetime'",
+		"TYPEOF(CURDATE())":    "'date'",
+		"TYPEOF(CURTIME())":    "'time'",
+
+		"DATE('2020-01-02 03:04:05')":                       "'2020-01-02'",
+		"TIME('2020-01-02 03:04:05')":                       "'03:04:05'",
+		"TIMESTAMP('2020-01-02')":                           "'2020-01-02 00:00:00'",
+		"YEAR('2020-01-02')":                                "2020",
+		"MONTH(20200102)":                                   "1",
+		"DAY(DATE '2020-01-02')":                            "2",
+		"HOUR('2020-01-02 03:04:05')":                       "3",
+		"MINUTE(TIME '100:30:00')":                          "30",
+		"SECOND('03:04:05')":                                "5",
+		"DAYOFWEEK('2020-01-05')":                           "1",
+		"DAYOFYEAR('2020-12-31')":                           "366",
+		"LAST_DAY('2020-02-10 12:00:00')":                   "'2020-02-29'",
+		"DATEDIFF('2020-03-01', '2020-02-01')":              "29",
+		"DATEDIFF('2020-01-01 23:59', DATE '2020-01-02')":   "-1",
+		"DATE_FORMAT('2020-01-02 15:04:05', '%Y/%m/%d %r')": "'2020/01/02 03:04:05 PM'",
+		"UNIX_TIMESTAMP('1970-01-02')":                      "86400",
+		"UNIX_TIMESTAMP('1960-01-01')":                      "0",
+		"FROM_UNIXTIME(86400.5)":                            "'1970-01-02 00:00:00.500000'",
+		"FROM_UNIXTIME(0, '%Y')":                            "'1970'",
+		"FROM_UNIXTIME(-1)":                                 "NULL",
+		"YEAR('x')":                                         "NULL",
+		"DAYOFWEEK('2020-00-00')":                           "NULL",
+		"DATEDIFF(NULL, '2020-01-01')":                      "NULL",
+	} {
+		v, err := ev.Eval(mustParse(t, input), nil)
+		if err != nil {
+			t.Fatal(input, err)
+		}
+		if v.String() != expected {
+			t.Fatalf("%s: expected %s, but %s", input, expected, v)
+		}
+	}
+	if _, err := ev.Eval(mustParse(t, "NOW(7)"), nil); err == nil {
+		t.Fatal("fail")
+	}
+}
+
+func TestEvalCollation(t *testing.T) {
+	cat := catalog.New()
+	cmd, _ := parser.ParseCommand("CREATE TABLE t (a TEXT COLLATE nocase, b TEXT COLLATE rtrim)")
+	if err := cat.Apply(cmd); err != nil {
+		t.Fatal(err)
+	}
+	row := MapRow{"a": String("abc"), "b": String("x  ")}
+	for input, expected := range map[string]string{
+		"a = 'ABC'":                       "1",
+		"'ABC' = a":                       "1",
+		"b = 'x'":                         "1",
+		"b = 'X'":                         "0",
+		"a IN ('x', 'ABC')":               "1",
+		"'ABC' IN ('x', a)":               "1",
+		"CASE a WHEN 'ABC' THEN 1 ELSE 0": "1",
+		"a > 'ABD' OR a < 'ABB'":          "0",
+		"a LIKE 'A%'":                     "1",
+		"b LIKE 'X%'":                     "0",
+		"b LIKE 'x%'":                     "1",
+		"'X' LIKE 'x'":                    "1",
+		"CASE WHEN UPPER(a) = 'abc' THEN 1 ELSE 0": "1",
+	} {
+		cmd, err := parser.ParseCommand("SELECT " + input + " FROM t")
+		if err != nil {
+			t.Fatal(input, err)
+		}
+		bound, err := binder.Bind(cat, cmd)
+		if err == nil {
+			err = binder.Check(bound, function.Builtin())
+		}
+		if err != nil {
+			t.Fatal(input, err)
+		}
+		ev := NewEvaluator()
+		ev.Collation = bound.Collation
+		v, err := ev.Eval(cmd.(*ast.Select).SelColList[0].SelectExpr, row)
+		if err != nil || v.String() != expected {
+			t.Fatalf("%s: expected %s, but %v %v", input, expected, v, err)
+		}
+	}
+
+	ev := NewEvaluator()
+	ev.Collation = func(expr ast.Expr) string {
+		return "latin1_swedish_ci"
+	}
+	_, err := ev.Eval(mustParse(t, "'a' = 'b'"), nil)
+	if err == nil || !strings.Contains(err.Error(), "Unknown collation: 'latin1_swedish_ci'") {
+		t.Fatal(err)
+	}
+}
+
+func TestSortCompare(t *testing.T) {
+	values := []Value{String("b"), Null(), Int(2), String("A"), Float(1.5), Bytes([]byte{1}),
+		String("a"), Time(datetime.HOUR), Date(datetime.Date(2020, 1, 2)),
+		DateTime(datetime.New(2020, 1, 1, 12, 0, 0, 0))}
+	sort.SliceStable(values, func(i, j int) bool {
+		return SortCompare(values[i], values[j], collate.NoCase) < 0
+	})
+	var list []string
+	for _, v := range values {
+		list = append(list, v.String())
+	}
+	expected := "NULL,1.5,2,'2020-01-01 12:00:00','2020-01-02','01:00:00','A','a','b',x'01'"
+	if strings.Join(list, ",") != expected {
+		t.Fatal(list)
+	}
+}
+
+func TestGroupKey(t *testing.T) {
+	dec, _ := ParseDecimal("1.50")
+	groups := map[string]int{}
+	for _, row := range [][]Value{
+		{Int(1), String("abc")},
+		{Float(1), String("ABC")},
+		{Int(1), String("abc ")},
+		{dec, String("x")},
+		{Float(1.5), String("X")},
+		{Decimal(mustDecimal(t, "0.10")), Null()},
+		{Float(0.1), Null()},
+		{Null(), Null()},
+		{Null(), Null()},
+		{String("1"), String("abc")},
+	} {
+		groups[GroupKey(row, []*collate.Collation{nil, collate.NoCase})]++
+	}
+	if len(groups) != 6 {
+		t.Fatal(groups)
+	}
+	d := datetime.Date(2020, 1, 2)
+	if GroupKey([]Value{Date(d)}, nil) != GroupKey([]Value{DateTime(d)}, nil) ||
+		GroupKey([]Value{DateTime(d)}, nil) == GroupKey([]Value{String(d.String())}, nil) ||
+		GroupKey([]Value{Time(0)}, nil) == GroupKey([]Value{DateTime(datetime.DateTime{})}, nil) {
+		t.Fatal("fail")
+	}
+	if GroupKey([]Value{String("a:"), String("b")}, nil) ==
+		GroupKey([]Value{String("a"), String(":b")}, nil) {
+		t.Fatal("fail")
+	}
+}
+
+func TestRegisterFunction(t *testing.T) {
+	ev := NewEvaluator()
+	err := ev.Funcs.Register(&function.Function{Name: "twice", MinArgs: 1, MaxArgs: 1},
+		strict(func(args []Value) (Value, error) {
+			return Arithmetic(token.STAR, args[0], Int(2))
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := ev.Eval(mustParse(t, "TWICE(21)"), nil)
+	if err != nil || v.String() != "42" {
+		t.Fatal(v, err)
+	}
+	if _, found := Builtin().Lookup("twice"); found {
+		t.Fatal("fail")
+	}
+
+	err = ev.Funcs.Register(&function.Function{Name: "abs", MinArgs: 1, MaxArgs: 1}, nil)
+	if err == nil || err.Error() != "Function ABS() already exists" {
+		t.Fatal(err)
+	}
+}
+
+func TestVariable(t *testing.T) {
+	ev := NewEvaluator()
+	ev.Vars["x"] = Int(2)
+	v, err := ev.Eval(mustParse(t, "@x + 1"), nil)
+	if err != nil || v.String() != "3" {
+		t.Fatal(v, err)
+	}
+}
+
+func assertEval(t *testing.T, input string, row Row, expected string) {
+	v, err := NewEvaluator().Eval(mustParse(t, input), row)
+	if err != nil {
+		t.Fatal(input, err)
+	}
+	if v.String() != expected {
+		t.Fatalf("%s: expected %s, but %s", input, expected, v)
+	}
+}
+
+func assertEvalFail(t *testing.T, input, expected string) {
+	_, err := NewEvaluator().Eval(mustParse(t, input), nil)
+	if err == nil || !strings.Contains(err.Error(), expected) {
+		t.Fatalf("%s: unexpected error %v", input, err)
+	}
+}
+
+func mustDecimal(t *testing.T, s string) decimal.Decimal {
+	d, err := decimal.Parse(s)
+	if err != nil {
+		t.Fatal(s, err)
+	}
+	return d
+}
+
+func mustParse(t *testing.T, input string) ast.Expr {
+	expr, err := parser.ParseExpression(input)
+	if err != nil {
+		t.Fatal(input, err)
+	}
+	return expr
+}
@@ -0,0 +1,700 @@
+package eval
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/emptyland/akino/sql/datetime"
+	"github.com/emptyland/akino/sql/decimal"
+	"github.com/emptyland/akino/sql/function"
+)
+
+// Scalar function which can be called by evaluator, arity and types are
+// declared by function.Function.
+type Func struct {
+	*function.Function
+	Call func(args []Value) (Value, error)
+}
+
+type Functions struct {
+	fn map[string]*Func
+
+	// Clock of NOW() and CURRENT_TIMESTAMP, time zone of session is UTC.
+	Now func() time.Time
+}
+
+func NewFunctions() *Functions {
+	return &Functions{fn: make(map[string]*Func), Now: time.Now}
+}
+
+// New table with all built-in scalar functions, functions registered in it will
+// not affect others.
+func Builtin() *Functions {
+	decls := function.Builtin()
+	funcs := NewFunctions()
+	for name, call := range builtin {
+		decl, found := decls.Lookup(name)
+		if !found {
+			panic("No declaration of built-in function " + name)
+		}
+		funcs.fn[name] = &Func{Function: decl, Call: call}
+	}
+	for name, clock := range clocks {
+		decl, found := decls.Lookup(name)
+		if !found {
+			panic("No declaration of built-in function " + name)
+		}
+		funcs.fn[name] = &Func{Function: decl, Call: clock(funcs)}
+	}
+	return funcs
+}
+
+func (self *Functions) Register(decl *function.Function,
+	call func(args []Value) (Value, error)) error {
+	name := strings.ToUpper(decl.Name)
+	if _, found := self.fn[name]; found {
+		return fmt.Errorf("Function %s() already exists", name)
+	}
+	if decl.Aggregate || decl.Window {
+		return fmt.Errorf("Function %s() is not a scalar function", name)
+	}
+	if decl.MaxArgs != function.VARIADIC && decl.MaxArgs < decl.MinArgs {
+		return fmt.Errorf("Bad arity of function %s()", name)
+	}
+	self.fn[name] = &Func{Function: decl, Call: call}
+	return nil
+}
+
+// Name of function is case-insensitive.
+func (self *Functions) Lookup(name string) (*Func, bool) {
+	fn, found := self.fn[strings.ToUpper(name)]
+	return fn, found
+}
+
+// Result is NULL if any one of arguments is NULL.
+func strict(call func(args []Value) (Value, error)) func(args []Value) (Value, error) {
+	return func(args []Value) (Value, error) {
+		for _, arg := range args {
+			if arg.IsNull() {
+				return Null(), nil
+			}
+		}
+		return call(args)
+	}
+}
+
+func float1(call func(x float64) float64) func(args []Value) (Value, error) {
+	return strict(func(args []Value) (Value, error) {
+		r := call(args[0].AsFloat())
+		if math.IsNaN(r) || math.IsInf(r, 0) {
+			return Null(), nil
+		}
+		return Float(r), nil
+	})
+}
+
+func string1(call func(s string) string) func(args []Value) (Value, error) {
+	return strict(func(args []Value) (Value, error) {
+		return String(call(args[0].AsString())), nil
+	})
+}
+
+var builtin = map[string]func(args []Value) (Value, error){
+	//--------------------------------------------------------------------------
+	// Math
+	//--------------------------------------------------------------------------
+	"ABS":     strict(fnAbs),
+	"SIGN":    strict(fnSign),
+	"ROUND":   strict(fnRound),
+	"CEIL":    strict(fnCeil),
+	"CEILING": strict(fnCeil),
+	"FLOOR":   strict(fnFloor),
+	"MOD":     strict(fnMod),
+	"POW":     strict(fnPow),
+	"POWER":   strict(fnPow),
+	"SQRT":    float1(math.Sqrt),
+	"EXP":     float1(math.Exp),
+	"LN":      float1(ln),
+	"LOG":     strict(fnLog),
+	"RAND":    fnRand,
+	"RANDOM": func(args []Value) (Value, error) {
+		return Int(int64(rand.Uint64())), nil
+	},
+	"GREATEST": strict(func(args []Value) (Value, error) {
+		return extremum(args, 1)
+	}),
+	"LEAST": strict(func(args []Value) (Value, error) {
+		return extremum(args, -1)
+	}),
+
+	//--------------------------------------------------------------------------
+	// String
+	//--------------------------------------------------------------------------
+	"LENGTH": strict(func(args []Value) (Value, error) {
+		return Int(int64(len(args[0].AsString()))), nil
+	}),
+	"CHAR_LENGTH": strict(func(args []Value) (Value, error) {
+		return Int(int64(utf8.RuneCountInString(args[0].AsString()))), nil
+	}),
+	"LOWER":     string1(strings.ToLower),
+	"UPPER":     string1(strings.ToUpper),
+	"LCASE":     string1(strings.ToLower),
+	"UCASE":     string1(strings.ToUpper),
+	"REVERSE":   string1(reverse),
+	"TRIM":      strict(trim(strings.Trim)),
+	"LTRIM":     strict(trim(strings.TrimLeft)),
+	"RTRIM":     strict(trim(strings.TrimRight)),
+	"SUBSTR":    strict(fnSubstr),
+	"SUBSTRING": strict(fnSubstr),
+	"CONCAT": strict(func(args []Value) (Value, error) {
+		var buf strings.Builder
+		for _, arg := range args {
+			buf.WriteString(arg.AsString())
+		}
+		return String(buf.String()), nil
+	}),
+	"CONCAT_WS": fnConcatWs,
+	"REPLACE": strict(func(args []Value) (Value, error) {
+		return String(strings.Replace(args[0].AsString(), args[1].AsString(),
+			args[2].AsString(), -1)), nil
+	}),
+	"INSTR": strict(func(args []Value) (Value, error) {
+		s := args[0].AsString()
+		i := strings.Index(s, args[1].AsString())
+		if i < 0 {
+			return Int(0), nil
+		}
+		return Int(int64(utf8.RuneCountInString(s[:i]) + 1)), nil
+	}),
+	"REPEAT": strict(fnRepeat),
+	"LPAD":   strict(pad(true)),
+	"RPAD":   strict(pad(false)),
+	"HEX":    strict(fnHex),
+	"QUOTE": func(args []Value) (Value, error) {
+		if args[0].IsNull() {
+			return String("NULL"), nil
+		}
+		return String("'" + strings.Replace(args[0].AsString(), "'", "''", -1) + "'"), nil
+	},
+
+	//--------------------------------------------------------------------------
+	// Date and time
+	//--------------------------------------------------------------------------
+	"DATE": date1(func(d datetime.DateTime) Value {
+		return Date(d)
+	}),
+	"TIMESTAMP": date1(func(d datetime.DateTime) Value {
+		return DateTime(d)
+	}),
+	"TIME": strict(func(args []Value) (Value, error) {
+		if d, err := durationOf(args[0]); err == nil {
+			return Time(d), nil
+		}
+		return Null(), nil
+	}),
+	"YEAR": date1(func(d datetime.DateTime) Value {
+		return Int(int64(d.Year()))
+	}),
+	"MONTH": date1(func(d datetime.DateTime) Value {
+		return Int(int64(d.Month()))
+	}),
+	"DAY": date1(func(d datetime.DateTime) Value {
+		return Int(int64(d.Day()))
+	}),
+	"HOUR":   clock1(datetime.Duration.Hour),
+	"MINUTE": clock1(datetime.Duration.Minute),
+	"SECOND": clock1(datetime.Duration.Second),
+	"DAYOFWEEK": date1(func(d datetime.DateTime) Value {
+		if t, ok := d.Time(); ok {
+			return Int(int64(t.Weekday()) + 1) // 1 is Sunday
+		}
+		return Null()
+	}),
+	"DAYOFYEAR": date1(func(d datetime.DateTime) Value {
+		if t, ok := d.Time(); ok {
+			return Int(int64(t.YearDay()))
+		}
+		return Null()
+	}),
+	"LAST_DAY": date1(func(d datetime.DateTime) Value {
+		if d.Month() == 0 {
+			return Null()
+		}
+		return Date(datetime.Date(d.Year(), d.Month(), datetime.DaysIn(d.Year(), d.Month())))
+	}),
+	"DATEDIFF":      strict(fnDateDiff),
+	"DATE_FORMAT":   strict(fnDateFormat),
+	"DATE_ADD":      strict(dateAdd(false)),
+	"DATE_SUB":      strict(dateAdd(true)),
+	"FROM_UNIXTIME": strict(fnFromUnixTime),
+
+	//--------------------------------------------------------------------------
+	// NULL handling
+	//--------------------------------------------------------------------------
+	"COALESCE": fnCoalesce,
+	"IFNULL":   fnCoalesce,
+	"NULLIF": func(args []Value) (Value, error) {
+		if args[0].IsNull() || args[1].IsNull() {
+			return args[0], nil
+		}
+		c, err := Compare(args[0], args[1])
+		if err != nil || c != 0 {
+			return args[0], err
+		}
+		return Null(), nil
+	},
+	"IIF": func(args []Value) (Value, error) {
+		if b, null := args[0].Truth(); b && !null {
+			return args[1], nil
+		}
+		return args[2], nil
+	},
+
+	//--------------------------------------------------------------------------
+	// Misc
+	//--------------------------------------------------------------------------
+	"TYPEOF": func(args []Value) (Value, error) {
+		return String(args[0].Kind.String()), nil
+	},
+}
+
+//------------------------------------------------------------------------------
+// Math
+//------------------------------------------------------------------------------
+func fnAbs(args []Value) (Value, error) {
+	v := args[0]
+	switch v.Kind {
+	case KIND_INT:
+		if v.i >= 0 {
+			return v, nil
+		}
+		return Negate(v)
+	case KIND_DECIMAL:
+		return Decimal(v.d.Abs()), nil
+	default:
+		return Float(math.Abs(v.AsFloat())), nil
+	}
+}
+
+func fnSign(args []Value) (Value, error) {
+	v := args[0]
+	switch v.Kind {
+	case KIND_INT:
+		return Int(int64(compareInt(v.i, 0))), nil
+	case KIND_DECIMAL:
+		return Int(int64(v.d.Sign())), nil
+	default:
+		return Int(int64(compareFloat(v.AsFloat(), 0))), nil
+	}
+}
+
+// ROUND(x[, d]): Rounds half away from zero to d digits after decimal point,
+// d can be negative.
+func fnRound(args []Value) (Value, error) {
+	v, d := args[0], int64(0)
+	if len(args) > 1 {
+		d, _ = args[1].AsInt()
+	}
+	if d > decimal.MAX_SCALE {
+		d = decimal.MAX_SCALE
+	} else if d < -decimal.MAX_SCALE {
+		d = -decimal.MAX_SCALE
+	}
+
+	switch v.Kind {
+	case KIND_INT, KIND_DECIMAL:
+		if v.Kind == KIND_INT && d >= 0 {
+			return v, nil
+		}
+		r := v.AsDecimal().Round(int(d), decimal.ROUND_HALF_UP)
+		if v.Kind == KIND_INT {
+			i, ok := r.Int64()
+			if !ok {
+				return Null(), fmt.Errorf("BIGINT value is out of range")
+			}
+			return Int(i), nil
+		}
+		return Decimal(r), nil
+
+	default:
+		exp := math.Pow(10, float64(d))
+		return Float(math.Round(v.AsFloat()*exp) / exp), nil
+	}
+}
+
+func fnCeil(args []Value) (Value, error) {
+	return integral(args[0], math.Ceil, decimal.ROUND_CEILING)
+}
+
+func fnFloor(args []Value) (Value, error) {
+	return integral(args[0], math.Floor, decimal.ROUND_FLOOR)
+}
+
+// CEIL and FLOOR: DECIMAL is rounded in mode.
+func integral(v Value, call func(float64) float64, mode decimal.RoundingMode) (Value, error) {
+	switch v.Kind {
+	case KIND_INT:
+		return v, nil
+	case KIND_DECIMAL:
+		r := v.d.Round(0, mode)
+		if i, ok := r.Int64(); ok {
+			return Int(i), nil
+		}
+		return Decimal(r), nil
+	default:
+		return Float(call(v.AsFloat())), nil
+	}
+}
+
+// MOD(x, y): Sign of result is the same as x, MOD(x, 0) is NULL.
+func fnMod(args []Value) (Value, error) {
+	x, y := args[0], args[1]
+	switch {
+	case x.Kind == KIND_INT && y.Kind == KIND_INT:
+		if y.i == 0 {
+			return Null(), nil
+		}
+		if y.i == -1 {
+			return Int(0), nil
+		}
+		return Int(x.i % y.i), nil
+
+	case x.Kind == KIND_FLOAT || y.Kind == KIND_FLOAT || !x.IsNumeric() || !y.IsNumeric():
+		if y.AsFloat() == 0 {
+			return Null(), nil
+		}
+		return Float(math.Mod(x.AsFloat(), y.AsFloat())), nil
+
+	default:
+		a, b := x.AsDecimal(), y.AsDecimal()
+		if b.IsZero() {
+			return Null(), nil
+		}
+		q, _ := a.Quo(b, 0, decimal.ROUND_DOWN)
+		return Decimal(a.Sub(b.Mul(q))), nil
+	}
+}
+
+func fnPow(args []Value) (Value, error) {
+	r := math.Pow(args[0].AsFloat(), args[1].AsFloat())
+	if math.IsInf(r, 0) {
+		return Null(), fmt.Errorf("DOUBLE value is out of range in POW(%s, %s)", args[0], args[1])
+	}
+	if math.IsNaN(r) {
+		return Null(), nil
+	}
+	return Float(r), nil
+}
+
+func ln(x float64) float64 {
+	if x <= 0 {
+		return math.NaN()
+	}
+	return math.Log(x)
+}
+
+// LOG(x) is LN(x), LOG(b, x) is logarithm of x to the base b.
+func fnLog(args []Value) (Value, error) {
+	if len(args) == 1 {
+		return float1(ln)(args)
+	}
+	b, x := args[0].AsFloat(), args[1].AsFloat()
+	if b <= 0 || b == 1 || x <= 0 {
+		return Null(), nil
+	}
+	return Float(math.Log(x) / math.Log(b)), nil
+}
+
+// RAND([seed]): Result is in [0, 1), it is repeatable if seed is given and
+// NULL seed is the same as 0.
+func fnRand(args []Value) (Value, error) {
+	if len(args) == 0 {
+		return Float(rand.Float64()), nil
+	}
+	seed, _ := args[0].AsInt()
+	return Float(rand.New(rand.NewSource(seed)).Float64()), nil
+}
+
+// Greatest if sign is 1, least if sign is -1.
+func extremum(args []Value, sign int) (Value, error) {
+	rv := args[0]
+	for _, arg := range args[1:] {
+		c, err := Compare(arg, rv)
+		if err != nil {
+			return Null(), err
+		}
+		if c*sign > 0 {
+			rv = arg
+		}
+	}
+	return rv, nil
+}
+
+//------------------------------------------------------------------------------
+// String
+//------------------------------------------------------------------------------
+func reverse(s string) string {
+	r := []rune(s)
+	for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+		r[i], r[j] = r[j], r[i]
+	}
+	return string(r)
+}
+
+// TRIM(s[, chars]): Removes spaces or any one of chars.
+func trim(call func(s, cutset string) string) func(args []Value) (Value, error) {
+	return func(args []Value) (Value, error) {
+		cutset := " "
+		if len(args) > 1 {
+			cutset = args[1].AsString()
+		}
+		return String(call(args[0].AsString(), cutset)), nil
+	}
+}
+
+// SUBSTR(s, pos[, len]): pos starts from 1, negative pos is counted from the
+// end of s.
+func fnSubstr(args []Value) (Value, error) {
+	s := []rune(args[0].AsString())
+	pos, _ := args[1].AsInt()
+	n := int64(len(s))
+	if len(args) > 2 {
+		n, _ = args[2].AsInt()
+	}
+
+	switch {
+	case pos > 0:
+		pos--
+	case pos < 0:
+		pos += int64(len(s))
+	default:
+		return String(""), nil
+	}
+	if pos < 0 || pos >= int64(len(s)) || n <= 0 {
+		return String(""), nil
+	}
+	if n > int64(len(s))-pos {
+		n = int64(len(s)) - pos
+	}
+	return String(string(s[pos : pos+n])), nil
+}
+
+// CONCAT_WS(sep, s...): NULL arguments after separator are skipped.
+func fnConcatWs(args []Value) (Value, error) {
+	if args[0].IsNull() {
+		return Null(), nil
+	}
+	var parts []string
+	for _, arg := range args[1:] {
+		if !arg.IsNull() {
+			parts = append(parts, arg.AsString())
+		}
+	}
+	return String(strings.Join(parts, args[0].AsString())), nil
+}
+
+// Limit of result of REPEAT, LPAD and RPAD.
+const MAX_STRING_LENGTH = 16 * 1024 * 1024
+
+func fnRepeat(args []Value) (Value, error) {
+	s := args[0].AsString()
+	n, _ := args[1].AsInt()
+	if n <= 0 {
+		return String(""), nil
+	}
+	if int64(len(s))*n > MAX_STRING_LENGTH {
+		return Null(), fmt.Errorf("Result is larger than %d", MAX_STRING_LENGTH)
+	}
+	return String(strings.Repeat(s, int(n))), nil
+}
+
+// LPAD(s, len, padding) and RPAD(s, len, padding): s is truncated if it is
+// longer than len.
+func pad(left bool) func(args []Value) (Value, error) {
+	return func(args []Value) (Value, error) {
+		s := []rune(args[0].AsString())
+		n, _ := args[1].AsInt()
+		padding := []rune(args[2].AsString())
+		switch {
+		case n < 0:
+			return Null(), nil
+		case n > MAX_STRING_LENGTH:
+			return Null(), fmt.Errorf("Result is larger than %d", MAX_STRING_LENGTH)
+		case n <= int64(len(s)):
+			return String(string(s[:n])), nil
+		case len(padding) == 0:
+			return Null(), nil
+		}
+
+		fill := make([]rune, 0, n)
+		for int64(len(fill)+len(s)) < n {
+			fill = append(fill, padding[len(fill)%len(padding)])
+		}
+		if left {
+			return String(string(fill) + string(s)), nil
+		}
+		return String(string(s) + string(fill)), nil
+	}
+}
+
+// HEX(n) is hexadecimal of integer, HEX(s) is hexadecimal of every bytes.
+func fnHex(args []Value) (Value, error) {
+	v := args[0]
+	if v.IsNumeric() {
+		i, ok := v.AsInt()
+		if !ok {
+			return Null(), fmt.Errorf("BIGINT value is out of range: %s", v)
+		}
+		return String(strings.ToUpper(fmt.Sprintf("%x", uint64(i)))), nil
+	}
+	return String(strings.ToUpper(fmt.Sprintf("%x", v.AsString()))), nil
+}
+
+//------------------------------------------------------------------------------
+// NULL handling
+//------------------------------------------------------------------------------
+func fnCoalesce(args []Value) (Value, error) {
+	for _, arg := range args {
+		if !arg.IsNull() {
+			return arg, nil
+		}
+	}
+	return Null(), nil
+}
+
+//------------------------------------------------------------------------------
+// Date and time
+//------------------------------------------------------------------------------
+
+// Functions of clock of Functions, the current time is read in every call.
+var clocks = map[string]func(funcs *Functions) func(args []Value) (Value, error){
+	"NOW":               now(DateTime),
+	"CURRENT_TIMESTAMP": now(DateTime),
+	"CURDATE":           now(Date),
+	"CURRENT_DATE":      now(Date),
+	"CURTIME": now(func(d datetime.DateTime) Value {
+		return Time(d.Duration())
+	}),
+	"CURRENT_TIME": now(func(d datetime.DateTime) Value {
+		return Time(d.Duration())
+	}),
+	"UNIX_TIMESTAMP": fnUnixTimestamp,
+}
+
+// NOW([fsp]): Current time is rounded to fsp digits.
+func now(call func(d datetime.DateTime) Value) func(
+	funcs *Functions) func(args []Value) (Value, error) {
+	return func(funcs *Functions) func(args []Value) (Value, error) {
+		return strict(func(args []Value) (Value, error) {
+			fsp := int64(0)
+			if len(args) > 0 {
+				fsp, _ = args[0].AsInt()
+			}
+			if err := datetime.CheckFsp(int(fsp)); err != nil {
+				return Null(), err
+			}
+			return call(datetime.FromTime(funcs.Now().UTC()).Round(int(fsp))), nil
+		})
+	}
+}
+
+// Like MySQL, result is NULL if argument is not a valid date or datetime.
+func date1(call func(d datetime.DateTime) Value) func(args []Value) (Value, error) {
+	return strict(func(args []Value) (Value, error) {
+		d, _, err := dateTimeOf(args[0], datetime.ZERO_ALLOW)
+		if err != nil {
+			return Null(), nil
+		}
+		return call(d), nil
+	})
+}
+
+// HOUR(), MINUTE() and SECOND() of TIME or time part of datetime.
+func clock1(call func(d datetime.Duration) int) func(args []Value) (Value, error) {
+	return strict(func(args []Value) (Value, error) {
+		d, err := durationOf(args[0])
+		if err != nil {
+			return Null(), nil
+		}
+		return Int(int64(call(d))), nil
+	})
+}
+
+// DATEDIFF(a, b): Days from b to a, the time parts are ignored.
+func fnDateDiff(args []Value) (Value, error) {
+	var days [2]int64
+	for i, arg := range args {
+		d, _, err := dateTimeOf(arg, datetime.ZERO_ALLOW)
+		if err != nil {
+			return Null(), nil
+		}
+		n, ok := d.DayNumber()
+		if !ok {
+			return Null(), nil
+		}
+		days[i] = n
+	}
+	return Int(days[0] - days[1]), nil
+}
+
+func fnDateFormat(args []Value) (Value, error) {
+	d, _, err := dateTimeOf(args[0], datetime.ZERO_ALLOW)
+	if err != nil {
+		return Null(), nil
+	}
+	return String(d.Format(args[1].AsString())), nil
+}
+
+// DATE_ADD(d, INTERVAL expr unit) and DATE_SUB().
+func dateAdd(sub bool) func(args []Value) (Value, error) {
+	return func(args []Value) (Value, error) {
+		if args[1].Kind != KIND_INTERVAL {
+			return Null(), fmt.Errorf("Expected INTERVAL, but %s", args[1].Kind)
+		}
+		iv := args[1].iv
+		if sub {
+			iv = iv.Neg()
+		}
+		return AddInterval(args[0], iv), nil
+	}
+}
+
+// UNIX_TIMESTAMP([d]): Seconds since '1970-01-01 00:00:00' UTC, it is 0 if d is
+// out of range of TIMESTAMP.
+func fnUnixTimestamp(funcs *Functions) func(args []Value) (Value, error) {
+	return strict(func(args []Value) (Value, error) {
+		if len(args) == 0 {
+			return Int(funcs.Now().Unix()), nil
+		}
+		d, _, err := dateTimeOf(args[0], datetime.ZERO_ALLOW)
+		if err != nil {
+			return Null(), nil
+		}
+		t, ok := d.Time()
+		if !ok || d.Compare(minTimestamp) < 0 || d.Compare(maxTimestamp) > 0 {
+			return Int(0), nil
+		}
+		return Int(t.Unix()), nil
+	})
+}
+
+// FROM_UNIXTIME(ts[, format]): Datetime in UTC, it is NULL if ts is negative or
+// out of range of TIMESTAMP.
+func fnFromUnixTime(args []Value) (Value, error) {
+	micros, ok := args[0].AsDecimal().Mul(decimal.FromInt(datetime.SECOND)).Int64()
+	if !ok || micros < 0 {
+		return Null(), nil
+	}
+	d := datetime.FromTime(time.Unix(micros/datetime.SECOND, micros%datetime.SECOND*1000).UTC())
+	if d.Compare(maxTimestamp) > 0 {
+		return Null(), nil
+	}
+	if len(args) > 1 {
+		return String(d.Format(args[1].AsString())), nil
+	}
+	return DateTime(d), nil
+}
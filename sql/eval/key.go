@@ -0,0 +1,90 @@
+package eval
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/emptyland/akino/sql/collate"
+)
+
+// Total order for ORDER BY and index: NULL is the least, then numbers, dates,
+// times, strings and bytes. Values of the same class are compared in collation.
+func SortCompare(a, b Value, coll *collate.Collation) int {
+	if c := compareInt(sortClass(a), sortClass(b)); c != 0 || a.IsNull() {
+		return c
+	}
+	c, err := CompareCollate(a, b, coll)
+	if err != nil {
+		return 0
+	}
+	return c
+}
+
+func sortClass(v Value) int64 {
+	switch v.Kind {
+	case KIND_NULL:
+		return 0
+	case KIND_INT, KIND_FLOAT, KIND_DECIMAL:
+		return 1
+	case KIND_DATE, KIND_DATETIME:
+		return 2
+	case KIND_TIME:
+		return 3
+	case KIND_STRING:
+		return 4
+	case KIND_BYTES:
+		return 5
+	default:
+		return 6
+	}
+}
+
+// Key of values for GROUP BY and DISTINCT: keys are the same if values are
+// equal in collations, NULLs are in the same group. colls can be shorter than
+// values, strings without collation are compared in binary.
+//
+// Numbers of different kinds are the same if the decimal values are equal, so
+// 1, 1.0 and float 1 are in the same group, and so are DATE '2020-01-02' and
+// DATETIME '2020-01-02 00:00:00'.
+func GroupKey(values []Value, colls []*collate.Collation) string {
+	var buf strings.Builder
+	for i, v := range values {
+		var coll *collate.Collation
+		if i < len(colls) {
+			coll = colls[i]
+		}
+
+		var tag byte
+		var key string
+		switch v.Kind {
+		case KIND_NULL:
+			tag = 'N'
+		case KIND_INT, KIND_DECIMAL:
+			tag, key = 'D', v.AsDecimal().Normalize().String()
+		case KIND_FLOAT:
+			tag, key = 'D', v.AsDecimal().Normalize().String()
+			if math.IsNaN(v.f) || math.IsInf(v.f, 0) {
+				tag, key = 'F', v.AsString()
+			}
+		case KIND_STRING:
+			tag, key = 'S', v.s
+			if coll != nil {
+				key = string(coll.Key(v.s))
+			}
+		case KIND_BYTES:
+			tag, key = 'B', v.s
+		case KIND_DATE, KIND_DATETIME:
+			tag, key = 'T', v.t.String()
+		case KIND_TIME:
+			tag, key = 'U', v.AsString()
+		case KIND_INTERVAL:
+			tag, key = 'I', v.AsString()
+		}
+		buf.WriteByte(tag)
+		buf.WriteString(strconv.Itoa(len(key)))
+		buf.WriteByte(':')
+		buf.WriteString(key)
+	}
+	return buf.String()
+}
@@ -0,0 +1,128 @@
+package eval
+
+import (
+	"fmt"
+
+	"github.com/emptyland/akino/sql/datetime"
+	"github.com/emptyland/akino/sql/token"
+)
+
+// Date or datetime of value, strings are parsed and numbers are like
+// 20200102 or 20200102030405. date is true if value has no time part.
+func dateTimeOf(v Value, mode datetime.ZeroMode) (datetime.DateTime, bool, error) {
+	switch v.Kind {
+	case KIND_DATE:
+		return v.t, true, v.t.Check(mode)
+	case KIND_DATETIME:
+		return v.t, false, v.t.Check(mode)
+	case KIND_TIME:
+		return datetime.DateTime{}, false, fmt.Errorf("Incorrect datetime value: '%s'",
+			v.AsString())
+	case KIND_INT, KIND_FLOAT, KIND_DECIMAL:
+		return datetime.ParseNumber(v.AsDecimal().String(), mode)
+	default:
+		return datetime.Parse(v.AsString(), mode)
+	}
+}
+
+// Time of value, the time part is used if value is a datetime.
+func durationOf(v Value) (datetime.Duration, error) {
+	switch v.Kind {
+	case KIND_TIME:
+		return datetime.Duration(v.i), nil
+	case KIND_DATE, KIND_DATETIME:
+		return v.t.Duration(), nil
+	case KIND_INT, KIND_FLOAT, KIND_DECIMAL:
+		return datetime.ParseDuration(v.AsDecimal().String())
+	}
+	d, err := datetime.ParseDuration(v.AsString())
+	if err != nil {
+		if dt, date, e := datetime.Parse(v.AsString(), datetime.ZERO_ALLOW); e == nil && !date {
+			return dt.Duration(), nil
+		}
+	}
+	return d, err
+}
+
+// Compare date/time with other value: numbers are compared as numbers, TIME
+// with TIME or string as TIME, others as DATETIME.
+func compareTemporal(a, b Value) (int, error) {
+	switch {
+	case a.Kind == KIND_FLOAT || b.Kind == KIND_FLOAT:
+		return compareFloat(a.AsFloat(), b.AsFloat()), nil
+
+	case a.IsNumeric() || b.IsNumeric():
+		return a.AsDecimal().Cmp(b.AsDecimal()), nil
+
+	case a.Kind == KIND_TIME || b.Kind == KIND_TIME:
+		for _, v := range []Value{a, b} {
+			if v.Kind == KIND_DATE || v.Kind == KIND_DATETIME || v.Kind == KIND_BYTES {
+				return 0, fmt.Errorf("Can not compare %s with %s", a.Kind, b.Kind)
+			}
+		}
+		x, err := durationOf(a)
+		if err != nil {
+			return 0, err
+		}
+		y, err := durationOf(b)
+		if err != nil {
+			return 0, err
+		}
+		return compareInt(int64(x), int64(y)), nil
+
+	default:
+		x, _, err := dateTimeOf(a, datetime.ZERO_ALLOW)
+		if err != nil {
+			return 0, err
+		}
+		y, _, err := dateTimeOf(b, datetime.ZERO_ALLOW)
+		if err != nil {
+			return 0, err
+		}
+		return x.Compare(y), nil
+	}
+}
+
+// Add interval to date/time like DATE_ADD(). Result is DATE if v is a date
+// and interval has no time part, TIME for TIME, otherwise DATETIME. Result is
+// NULL if v is not a valid date or it is out of range.
+func AddInterval(v Value, iv datetime.Interval) Value {
+	if v.IsNull() {
+		return v
+	}
+	if v.Kind == KIND_TIME {
+		d := int64(v.i) + iv.Micros
+		if iv.Months != 0 || d > int64(datetime.MAX_DURATION) || d < -int64(datetime.MAX_DURATION) {
+			return Null()
+		}
+		return Time(datetime.Duration(d))
+	}
+
+	d, date, err := dateTimeOf(v, datetime.ZERO_ALLOW)
+	if err != nil {
+		return Null()
+	}
+	r, err := d.Add(iv)
+	if err != nil {
+		return Null()
+	}
+	if date && !iv.HasTime() {
+		return Date(r)
+	}
+	return DateTime(r)
+}
+
+// Date arithmetic: date + INTERVAL, INTERVAL + date and date - INTERVAL.
+func intervalArithmetic(op token.Token, a, b Value) (Value, error) {
+	switch {
+	case op == token.PLUS && a.Kind == KIND_INTERVAL && b.Kind != KIND_INTERVAL:
+		return AddInterval(b, a.iv), nil
+	case op == token.PLUS && b.Kind == KIND_INTERVAL && a.Kind != KIND_INTERVAL:
+		return AddInterval(a, b.iv), nil
+	case op == token.MINUS && b.Kind == KIND_INTERVAL && a.Kind != KIND_INTERVAL:
+		return AddInterval(a, b.iv.Neg()), nil
+	default:
+		return Null(), fmt.Errorf("Incompatible operands for %s: %s and %s", op, a.Kind,
+			b.Kind)
+	}
+}
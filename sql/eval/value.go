@@ -0,0 +1,380 @@
+package eval
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/emptyland/akino/sql/collate"
+	"github.com/emptyland/akino/sql/datetime"
+	"github.com/emptyland/akino/sql/decimal"
+)
+
+type Kind int
+
+const (
+	KIND_NULL Kind = iota
+	KIND_INT
+	KIND_FLOAT
+	KIND_DECIMAL
+	KIND_STRING
+	KIND_BYTES
+	KIND_DATE
+	KIND_DATETIME
+	KIND_TIME
+	KIND_INTERVAL // Only in date arithmetic: d + INTERVAL 1 DAY
+)
+
+var kindNames = []string{
+	"null",
+	"int",
+	"float",
+	"decimal",
+	"string",
+	"bytes",
+	"date",
+	"datetime",
+	"time",
+	"interval",
+}
+
+func (self Kind) String() string {
+	return kindNames[self]
+}
+
+// Value is a SQL value, the zero value is NULL.
+type Value struct {
+	Kind Kind
+	i    int64 // INT and microseconds of TIME
+	f    float64
+	d    decimal.Decimal
+	s    string
+	t    datetime.DateTime
+	iv   datetime.Interval
+}
+
+func Null() Value {
+	return Value{}
+}
+
+func Int(i int64) Value {
+	return Value{Kind: KIND_INT, i: i}
+}
+
+func Bool(b bool) Value {
+	if b {
+		return Int(1)
+	}
+	return Int(0)
+}
+
+func Float(f float64) Value {
+	return Value{Kind: KIND_FLOAT, f: f}
+}
+
+func Decimal(d decimal.Decimal) Value {
+	return Value{Kind: KIND_DECIMAL, d: d}
+}
+
+// Parse decimal like "-12.340", scale is digits after decimal point.
+func ParseDecimal(s string) (Value, error) {
+	d, err := decimal.Parse(s)
+	if err != nil {
+		return Null(), err
+	}
+	return Decimal(d), nil
+}
+
+func String(s string) Value {
+	return Value{Kind: KIND_STRING, s: s}
+}
+
+func Bytes(b []byte) Value {
+	return Value{Kind: KIND_BYTES, s: string(b)}
+}
+
+// Time part is truncated.
+func Date(d datetime.DateTime) Value {
+	return Value{Kind: KIND_DATE, t: d.Date()}
+}
+
+// DATETIME and TIMESTAMP.
+func DateTime(d datetime.DateTime) Value {
+	return Value{Kind: KIND_DATETIME, t: d}
+}
+
+func Time(d datetime.Duration) Value {
+	return Value{Kind: KIND_TIME, i: int64(d)}
+}
+
+func Interval(iv datetime.Interval) Value {
+	return Value{Kind: KIND_INTERVAL, iv: iv}
+}
+
+func (self Value) IsNull() bool {
+	return self.Kind == KIND_NULL
+}
+
+func (self Value) IsNumeric() bool {
+	return self.Kind == KIND_INT || self.Kind == KIND_FLOAT || self.Kind == KIND_DECIMAL
+}
+
+// DATE, DATETIME or TIME.
+func (self Value) IsTemporal() bool {
+	return self.Kind == KIND_DATE || self.Kind == KIND_DATETIME || self.Kind == KIND_TIME
+}
+
+//------------------------------------------------------------------------------
+// Conversion
+//------------------------------------------------------------------------------
+
+// Like MySQL, the longest numeric prefix of string is used, "12abc" is 12 and
+// "abc" is 0.
+func numericPrefix(s string) string {
+	s = strings.TrimSpace(s)
+	end, digits, dot, exp := 0, false, false, false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= '0' && c <= '9':
+			digits = true
+			end = i + 1
+		case (c == '+' || c == '-') && (i == 0 || s[i-1] == 'e' || s[i-1] == 'E'):
+		case c == '.' && !dot && !exp:
+			dot = true
+		case (c == 'e' || c == 'E') && digits && !exp:
+			exp = true
+		default:
+			i = len(s)
+		}
+	}
+	if !digits {
+		return "0"
+	}
+	return strings.TrimRight(s[:end], "eE+-")
+}
+
+func (self Value) AsFloat() float64 {
+	switch self.Kind {
+	case KIND_INT:
+		return float64(self.i)
+	case KIND_FLOAT:
+		return self.f
+	case KIND_DECIMAL:
+		return self.d.Float64()
+	case KIND_STRING, KIND_BYTES:
+		f, _ := strconv.ParseFloat(numericPrefix(self.s), 64)
+		return f
+	case KIND_DATE, KIND_DATETIME, KIND_TIME:
+		f, _ := strconv.ParseFloat(self.number(), 64)
+		return f
+	default:
+		return 0
+	}
+}
+
+// Numbers are rounded half away from zero, returns false if out of range.
+func (self Value) AsInt() (int64, bool) {
+	switch self.Kind {
+	case KIND_INT:
+		return self.i, true
+	case KIND_DECIMAL:
+		return self.d.Int64()
+	case KIND_STRING, KIND_BYTES:
+		return Float(self.AsFloat()).AsInt()
+	default:
+		f := math.Round(self.AsFloat())
+		if f < math.MinInt64 || f >= math.MaxInt64 {
+			return 0, false
+		}
+		return int64(f), true
+	}
+}
+
+// Exact value of number, strings are parsed by prefix, and float is the
+// shortest decimal which is converted to the same float.
+func (self Value) AsDecimal() decimal.Decimal {
+	switch self.Kind {
+	case KIND_INT:
+		return decimal.FromInt(self.i)
+	case KIND_DECIMAL:
+		return self.d
+	case KIND_STRING, KIND_BYTES:
+		d, _ := decimal.Parse(numericPrefix(self.s))
+		return d
+	case KIND_DATE, KIND_DATETIME, KIND_TIME:
+		d, _ := decimal.Parse(self.number())
+		return d
+	default:
+		d, _ := decimal.FromFloat(self.AsFloat())
+		return d
+	}
+}
+
+// Like MySQL, date/time in numeric context: 20200102, 20200102030405.5 and
+// 30405 for '03:04:05'.
+func (self Value) number() string {
+	var s string
+	var micro int
+	switch self.Kind {
+	case KIND_DATE:
+		return strconv.FormatInt(self.t.Int64()/1000000, 10)
+	case KIND_DATETIME:
+		s, micro = strconv.FormatInt(self.t.Int64(), 10), self.t.Microsecond()
+	default:
+		d := datetime.Duration(self.i)
+		s, micro = strconv.FormatInt(d.Int64(), 10), d.Microsecond()
+		if d < 0 && d.Int64() == 0 {
+			s = "-0"
+		}
+	}
+	if micro != 0 {
+		s += fmt.Sprintf(".%06d", micro)
+	}
+	return s
+}
+
+// Digits after decimal point of DECIMAL.
+func (self Value) Scale() int {
+	return self.d.Scale()
+}
+
+func (self Value) AsString() string {
+	switch self.Kind {
+	case KIND_NULL:
+		return ""
+	case KIND_INT:
+		return strconv.FormatInt(self.i, 10)
+	case KIND_FLOAT:
+		return strconv.FormatFloat(self.f, 'g', -1, 64)
+	case KIND_DECIMAL:
+		return self.d.String()
+	case KIND_DATE:
+		return self.t.DateString()
+	case KIND_DATETIME:
+		return self.t.String()
+	case KIND_TIME:
+		return datetime.Duration(self.i).String()
+	case KIND_INTERVAL:
+		return self.iv.String()
+	default:
+		return self.s
+	}
+}
+
+// Value of DATE or DATETIME.
+func (self Value) AsDateTime() datetime.DateTime {
+	return self.t
+}
+
+// Value of TIME.
+func (self Value) AsDuration() datetime.Duration {
+	return datetime.Duration(self.i)
+}
+
+// Value of INTERVAL.
+func (self Value) AsInterval() datetime.Interval {
+	return self.iv
+}
+
+func (self Value) String() string {
+	switch self.Kind {
+	case KIND_NULL:
+		return "NULL"
+	case KIND_STRING, KIND_DATE, KIND_DATETIME, KIND_TIME:
+		return "'" + self.AsString() + "'"
+	case KIND_BYTES:
+		return fmt.Sprintf("x'%x'", self.s)
+	default:
+		return self.AsString()
+	}
+}
+
+// Truth value for conditions, the second result is true for NULL.
+func (self Value) Truth() (bool, bool) {
+	switch self.Kind {
+	case KIND_NULL:
+		return false, true
+	case KIND_INT:
+		return self.i != 0, false
+	case KIND_DECIMAL:
+		return self.d.Sign() != 0, false
+	case KIND_DATE, KIND_DATETIME:
+		return !self.t.IsZero(), false
+	case KIND_TIME:
+		return self.i != 0, false
+	default:
+		return self.AsFloat() != 0, false
+	}
+}
+
+//------------------------------------------------------------------------------
+// Comparison
+//------------------------------------------------------------------------------
+
+// Compare two non-NULL values like MySQL: numbers are compared with strings as
+// float, strings are compared with date/time by parsing them, and date/time are
+// compared with numbers as numbers.
+func Compare(a, b Value) (int, error) {
+	return CompareCollate(a, b, nil)
+}
+
+// Compare strings in collation, nil is the binary collation.
+func CompareCollate(a, b Value, coll *collate.Collation) (int, error) {
+	if a.IsNull() || b.IsNull() {
+		return 0, fmt.Errorf("Can not compare NULL")
+	}
+	if a.Kind == KIND_INTERVAL || b.Kind == KIND_INTERVAL {
+		return 0, fmt.Errorf("Can not compare %s with %s", a.Kind, b.Kind)
+	}
+
+	switch {
+	case a.Kind == KIND_INT && b.Kind == KIND_INT:
+		return compareInt(a.i, b.i), nil
+
+	case a.IsNumeric() && b.IsNumeric():
+		if a.Kind == KIND_FLOAT || b.Kind == KIND_FLOAT {
+			return compareFloat(a.AsFloat(), b.AsFloat()), nil
+		}
+		return a.AsDecimal().Cmp(b.AsDecimal()), nil
+
+	case a.IsTemporal() || b.IsTemporal():
+		return compareTemporal(a, b)
+
+	case a.IsNumeric() || b.IsNumeric():
+		if a.Kind == KIND_BYTES || b.Kind == KIND_BYTES {
+			return 0, fmt.Errorf("Can not compare %s with %s", a.Kind, b.Kind)
+		}
+		return compareFloat(a.AsFloat(), b.AsFloat()), nil
+
+	case coll != nil && a.Kind == KIND_STRING && b.Kind == KIND_STRING:
+		return coll.Compare(a.s, b.s), nil
+
+	default: // Strings and bytes
+		return bytes.Compare([]byte(a.s), []byte(b.s)), nil
+	}
+}
+
+func compareInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,131 @@
+package function
+
+var builtin = []Function{
+	//--------------------------------------------------------------------------
+	// Aggregate
+	//--------------------------------------------------------------------------
+	{Name: "COUNT", MinArgs: 1, MaxArgs: 1, Return: TYPE_INT, Aggregate: true, Star: true},
+	{Name: "SUM", MinArgs: 1, MaxArgs: 1, Return: TYPE_ARG, Aggregate: true},
+	{Name: "AVG", MinArgs: 1, MaxArgs: 1, Return: TYPE_REAL, Aggregate: true},
+	{Name: "TOTAL", MinArgs: 1, MaxArgs: 1, Return: TYPE_REAL, Aggregate: true},
+	{Name: "MIN", MinArgs: 1, MaxArgs: 1, Return: TYPE_ARG, Aggregate: true},
+	{Name: "MAX", MinArgs: 1, MaxArgs: 1, Return: TYPE_ARG, Aggregate: true},
+	{Name: "GROUP_CONCAT", MinArgs: 1, MaxArgs: 2, Args: []Type{TYPE_ANY, TYPE_STRING},
+		Return: TYPE_STRING, Aggregate: true},
+
+	//--------------------------------------------------------------------------
+	// Window
+	//--------------------------------------------------------------------------
+	{Name: "ROW_NUMBER", MinArgs: 0, MaxArgs: 0, Return: TYPE_INT, Window: true},
+	{Name: "RANK", MinArgs: 0, MaxArgs: 0, Return: TYPE_INT, Window: true},
+	{Name: "DENSE_RANK", MinArgs: 0, MaxArgs: 0, Return: TYPE_INT, Window: true},
+	{Name: "PERCENT_RANK", MinArgs: 0, MaxArgs: 0, Return: TYPE_REAL, Window: true},
+	{Name: "CUME_DIST", MinArgs: 0, MaxArgs: 0, Return: TYPE_REAL, Window: true},
+	{Name: "NTILE", MinArgs: 1, MaxArgs: 1, Args: []Type{TYPE_INT}, Return: TYPE_INT, Window: true},
+	{Name: "LAG", MinArgs: 1, MaxArgs: 3, Args: []Type{TYPE_ANY, TYPE_INT, TYPE_ANY},
+		Return: TYPE_ARG, Window: true},
+	{Name: "LEAD", MinArgs: 1, MaxArgs: 3, Args: []Type{TYPE_ANY, TYPE_INT, TYPE_ANY},
+		Return: TYPE_ARG, Window: true},
+	{Name: "FIRST_VALUE", MinArgs: 1, MaxArgs: 1, Return: TYPE_ARG, Window: true},
+	{Name: "LAST_VALUE", MinArgs: 1, MaxArgs: 1, Return: TYPE_ARG, Window: true},
+	{Name: "NTH_VALUE", MinArgs: 2, MaxArgs: 2, Args: []Type{TYPE_ANY, TYPE_INT},
+		Return: TYPE_ARG, Window: true},
+
+	//--------------------------------------------------------------------------
+	// Math
+	//--------------------------------------------------------------------------
+	{Name: "ABS", MinArgs: 1, MaxArgs: 1, Return: TYPE_ARG},
+	{Name: "SIGN", MinArgs: 1, MaxArgs: 1, Return: TYPE_INT},
+	{Name: "ROUND", MinArgs: 1, MaxArgs: 2, Args: []Type{TYPE_ANY, TYPE_INT}, Return: TYPE_ARG},
+	{Name: "CEIL", MinArgs: 1, MaxArgs: 1, Return: TYPE_ARG},
+	{Name: "CEILING", MinArgs: 1, MaxArgs: 1, Return: TYPE_ARG},
+	{Name: "FLOOR", MinArgs: 1, MaxArgs: 1, Return: TYPE_ARG},
+	{Name: "MOD", MinArgs: 2, MaxArgs: 2, Return: TYPE_ARG},
+	{Name: "POW", MinArgs: 2, MaxArgs: 2, Args: []Type{TYPE_REAL}, Return: TYPE_REAL},
+	{Name: "POWER", MinArgs: 2, MaxArgs: 2, Args: []Type{TYPE_REAL}, Return: TYPE_REAL},
+	{Name: "SQRT", MinArgs: 1, MaxArgs: 1, Args: []Type{TYPE_REAL}, Return: TYPE_REAL},
+	{Name: "EXP", MinArgs: 1, MaxArgs: 1, Args: []Type{TYPE_REAL}, Return: TYPE_REAL},
+	{Name: "LN", MinArgs: 1, MaxArgs: 1, Args: []Type{TYPE_REAL}, Return: TYPE_REAL},
+	{Name: "LOG", MinArgs: 1, MaxArgs: 2, Args: []Type{TYPE_REAL}, Return: TYPE_REAL},
+	{Name: "RAND", MinArgs: 0, MaxArgs: 1, Args: []Type{TYPE_INT}, Return: TYPE_REAL},
+	{Name: "RANDOM", MinArgs: 0, MaxArgs: 0, Return: TYPE_INT},
+	{Name: "GREATEST", MinArgs: 2, MaxArgs: VARIADIC, Return: TYPE_ARG},
+	{Name: "LEAST", MinArgs: 2, MaxArgs: VARIADIC, Return: TYPE_ARG},
+
+	//--------------------------------------------------------------------------
+	// String
+	//--------------------------------------------------------------------------
+	{Name: "LENGTH", MinArgs: 1, MaxArgs: 1, Return: TYPE_INT},
+	{Name: "CHAR_LENGTH", MinArgs: 1, MaxArgs: 1, Args: []Type{TYPE_STRING}, Return: TYPE_INT},
+	{Name: "LOWER", MinArgs: 1, MaxArgs: 1, Args: []Type{TYPE_STRING}, Return: TYPE_STRING},
+	{Name: "UPPER", MinArgs: 1, MaxArgs: 1, Args: []Type{TYPE_STRING}, Return: TYPE_STRING},
+	{Name: "LCASE", MinArgs: 1, MaxArgs: 1, Args: []Type{TYPE_STRING}, Return: TYPE_STRING},
+	{Name: "UCASE", MinArgs: 1, MaxArgs: 1, Args: []Type{TYPE_STRING}, Return: TYPE_STRING},
+	{Name: "TRIM", MinArgs: 1, MaxArgs: 2, Args: []Type{TYPE_STRING}, Return: TYPE_STRING},
+	{Name: "LTRIM", MinArgs: 1, MaxArgs: 2, Args: []Type{TYPE_STRING}, Return: TYPE_STRING},
+	{Name: "RTRIM", MinArgs: 1, MaxArgs: 2, Args: []Type{TYPE_STRING}, Return: TYPE_STRING},
+	{Name: "SUBSTR", MinArgs: 2, MaxArgs: 3, Args: []Type{TYPE_STRING, TYPE_INT}, Return: TYPE_STRING},
+	{Name: "SUBSTRING", MinArgs: 2, MaxArgs: 3, Args: []Type{TYPE_STRING, TYPE_INT}, Return: TYPE_STRING},
+	{Name: "CONCAT", MinArgs: 1, MaxArgs: VARIADIC, Args: []Type{TYPE_STRING}, Return: TYPE_STRING},
+	{Name: "CONCAT_WS", MinArgs: 2, MaxArgs: VARIADIC, Args: []Type{TYPE_STRING}, Return: TYPE_STRING},
+	{Name: "REPLACE", MinArgs: 3, MaxArgs: 3, Args: []Type{TYPE_STRING}, Return: TYPE_STRING},
+	{Name: "INSTR", MinArgs: 2, MaxArgs: 2, Args: []Type{TYPE_STRING}, Return: TYPE_INT},
+	{Name: "REPEAT", MinArgs: 2, MaxArgs: 2, Args: []Type{TYPE_STRING, TYPE_INT}, Return: TYPE_STRING},
+	{Name: "REVERSE", MinArgs: 1, MaxArgs: 1, Args: []Type{TYPE_STRING}, Return: TYPE_STRING},
+	{Name: "LPAD", MinArgs: 3, MaxArgs: 3, Args: []Type{TYPE_STRING, TYPE_INT, TYPE_STRING},
+		Return: TYPE_STRING},
+	{Name: "RPAD", MinArgs: 3, MaxArgs: 3, Args: []Type{TYPE_STRING, TYPE_INT, TYPE_STRING},
+		Return: TYPE_STRING},
+	{Name: "HEX", MinArgs: 1, MaxArgs: 1, Return: TYPE_STRING},
+	{Name: "QUOTE", MinArgs: 1, MaxArgs: 1, Return: TYPE_STRING},
+
+	//--------------------------------------------------------------------------
+	// NULL handling
+	//--------------------------------------------------------------------------
+	{Name: "COALESCE", MinArgs: 1, MaxArgs: VARIADIC, Return: TYPE_ARG},
+	{Name: "IFNULL", MinArgs: 2, MaxArgs: 2, Return: TYPE_ARG},
+	{Name: "NULLIF", MinArgs: 2, MaxArgs: 2, Return: TYPE_ARG},
+	{Name: "IIF", MinArgs: 3, MaxArgs: 3, Args: []Type{TYPE_BOOL, TYPE_ANY}, Return: TYPE_ANY},
+
+	//--------------------------------------------------------------------------
+	// Date and time
+	//--------------------------------------------------------------------------
+	{Name: "NOW", MinArgs: 0, MaxArgs: 1, Args: []Type{TYPE_INT}, Return: TYPE_DATETIME},
+	{Name: "CURRENT_TIMESTAMP", MinArgs: 0, MaxArgs: 1, Args: []Type{TYPE_INT},
+		Return: TYPE_DATETIME},
+	{Name: "CURDATE", MinArgs: 0, MaxArgs: 0, Return: TYPE_DATETIME},
+	{Name: "CURRENT_DATE", MinArgs: 0, MaxArgs: 0, Return: TYPE_DATETIME},
+	{Name: "CURTIME", MinArgs: 0, MaxArgs: 1, Args: []Type{TYPE_INT}, Return: TYPE_DATETIME},
+	{Name: "CURRENT_TIME", MinArgs: 0, MaxArgs: 1, Args: []Type{TYPE_INT}, Return: TYPE_DATETIME},
+	{Name: "DATE", MinArgs: 1, MaxArgs: 1, Args: []Type{TYPE_DATETIME}, Return: TYPE_DATETIME},
+	{Name: "TIME", MinArgs: 1, MaxArgs: 1, Args: []Type{TYPE_DATETIME}, Return: TYPE_DATETIME},
+	{Name: "TIMESTAMP", MinArgs: 1, MaxArgs: 1, Args: []Type{TYPE_DATETIME},
+		Return: TYPE_DATETIME},
+	{Name: "YEAR", MinArgs: 1, MaxArgs: 1, Args: []Type{TYPE_DATETIME}, Return: TYPE_INT},
+	{Name: "MONTH", MinArgs: 1, MaxArgs: 1, Args: []Type{TYPE_DATETIME}, Return: TYPE_INT},
+	{Name: "DAY", MinArgs: 1, MaxArgs: 1, Args: []Type{TYPE_DATETIME}, Return: TYPE_INT},
+	{Name: "HOUR", MinArgs: 1, MaxArgs: 1, Args: []Type{TYPE_DATETIME}, Return: TYPE_INT},
+	{Name: "MINUTE", MinArgs: 1, MaxArgs: 1, Args: []Type{TYPE_DATETIME}, Return: TYPE_INT},
+	{Name: "SECOND", MinArgs: 1, MaxArgs: 1, Args: []Type{TYPE_DATETIME}, Return: TYPE_INT},
+	{Name: "DAYOFWEEK", MinArgs: 1, MaxArgs: 1, Args: []Type{TYPE_DATETIME}, Return: TYPE_INT},
+	{Name: "DAYOFYEAR", MinArgs: 1, MaxArgs: 1, Args: []Type{TYPE_DATETIME}, Return: TYPE_INT},
+	{Name: "LAST_DAY", MinArgs: 1, MaxArgs: 1, Args: []Type{TYPE_DATETIME},
+		Return: TYPE_DATETIME},
+	{Name: "DATE_ADD", MinArgs: 2, MaxArgs: 2, Args: []Type{TYPE_DATETIME, TYPE_INTERVAL},
+		Return: TYPE_DATETIME},
+	{Name: "DATE_SUB", MinArgs: 2, MaxArgs: 2, Args: []Type{TYPE_DATETIME, TYPE_INTERVAL},
+		Return: TYPE_DATETIME},
+	{Name: "DATEDIFF", MinArgs: 2, MaxArgs: 2, Args: []Type{TYPE_DATETIME}, Return: TYPE_INT},
+	{Name: "DATE_FORMAT", MinArgs: 2, MaxArgs: 2, Args: []Type{TYPE_DATETIME, TYPE_STRING},
+		Return: TYPE_STRING},
+	{Name: "UNIX_TIMESTAMP", MinArgs: 0, MaxArgs: 1, Args: []Type{TYPE_DATETIME}, Return: TYPE_INT},
+	{Name: "FROM_UNIXTIME", MinArgs: 1, MaxArgs: 2, Args: []Type{TYPE_INT, TYPE_STRING},
+		Return: TYPE_DATETIME},
+
+	//--------------------------------------------------------------------------
+	// Misc
+	//--------------------------------------------------------------------------
+	{Name: "TYPEOF", MinArgs: 1, MaxArgs: 1, Return: TYPE_STRING},
+	// For MySQL: ON DUPLICATE KEY UPDATE c = VALUES(c)
+	{Name: "VALUES", MinArgs: 1, MaxArgs: 1, Return: TYPE_ARG},
+}
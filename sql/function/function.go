@@ -0,0 +1,119 @@
+package function
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Type of arguments and return value of function.
+type Type int
+
+const (
+	TYPE_ANY Type = iota
+	TYPE_INT
+	TYPE_REAL
+	TYPE_DECIMAL
+	TYPE_STRING
+	TYPE_BYTES
+	TYPE_DATETIME
+	TYPE_BOOL
+	TYPE_INTERVAL // INTERVAL Expr Unit
+	TYPE_ARG      // Same as the type of the first argument
+)
+
+var typeNames = []string{
+	"any",
+	"int",
+	"real",
+	"decimal",
+	"string",
+	"bytes",
+	"datetime",
+	"bool",
+	"interval",
+	"arg",
+}
+
+func (self Type) String() string {
+	return typeNames[self]
+}
+
+const VARIADIC = -1
+
+type Function struct {
+	Name      string
+	MinArgs   int
+	MaxArgs   int    // VARIADIC for no limit
+	Args      []Type // The last one is for all rest arguments
+	Return    Type
+	Aggregate bool
+	Window    bool
+	Star      bool // Accept `*' as argument: COUNT(*)
+}
+
+func (self *Function) CheckArity(n int) bool {
+	return n >= self.MinArgs && (self.MaxArgs == VARIADIC || n <= self.MaxArgs)
+}
+
+func (self *Function) ArgType(i int) Type {
+	switch {
+	case len(self.Args) == 0:
+		return TYPE_ANY
+	case i < len(self.Args):
+		return self.Args[i]
+	default:
+		return self.Args[len(self.Args)-1]
+	}
+}
+
+// Description of arity for error message: "1", "1 to 3" or "at least 2"
+func (self *Function) Arity() string {
+	switch {
+	case self.MaxArgs == VARIADIC:
+		return fmt.Sprintf("at least %d", self.MinArgs)
+	case self.MinArgs == self.MaxArgs:
+		return fmt.Sprintf("%d", self.MinArgs)
+	default:
+		return fmt.Sprintf("%d to %d", self.MinArgs, self.MaxArgs)
+	}
+}
+
+//------------------------------------------------------------------------------
+// Registry
+//------------------------------------------------------------------------------
+type Registry struct {
+	fn map[string]*Function
+}
+
+func NewRegistry() *Registry {
+	return &Registry{fn: make(map[string]*Function)}
+}
+
+// New registry with all built-in functions, user functions registered in it
+// will not affect others.
+func Builtin() *Registry {
+	reg := NewRegistry()
+	for i := range builtin {
+		fn := builtin[i]
+		reg.fn[fn.Name] = &fn
+	}
+	return reg
+}
+
+func (self *Registry) Register(fn *Function) error {
+	name := strings.ToUpper(fn.Name)
+	if _, found := self.fn[name]; found {
+		return fmt.Errorf("Function %s() already exists", name)
+	}
+	if fn.MaxArgs != VARIADIC && fn.MaxArgs < fn.MinArgs {
+		return fmt.Errorf("Bad arity of function %s()", name)
+	}
+	self.fn[name] = fn
+	return nil
+}
+
+// Name of function is case-insensitive.
+func (self *Registry) Lookup(name string) (*Function, bool) {
+	fn, found := self.fn[strings.ToUpper(name)]
+	return fn, found
+}
@@ -0,0 +1,92 @@
+package function
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/emptyland/akino/sql/parser"
+)
+
+func TestLookup(t *testing.T) {
+	reg := Builtin()
+	fn, found := reg.Lookup("count")
+	if !found || !fn.Aggregate || !fn.Star || fn.Return != TYPE_INT {
+		t.Fatal(fn)
+	}
+	if _, found = reg.Lookup("nosuchfn"); found {
+		t.Fatal("fail")
+	}
+
+	fn, _ = reg.Lookup("SUBSTR")
+	if fn.ArgType(0) != TYPE_STRING || fn.ArgType(2) != TYPE_INT {
+		t.Fatal(fn.Args)
+	}
+	if fn.Arity() != "2 to 3" {
+		t.Fatal(fn.Arity())
+	}
+}
+
+func TestRegister(t *testing.T) {
+	reg := Builtin()
+	if err := reg.Register(&Function{Name: "my_fn", MinArgs: 1, MaxArgs: VARIADIC}); err != nil {
+		t.Fatal(err)
+	}
+	if fn, found := reg.Lookup("MY_FN"); !found || !fn.CheckArity(5) || fn.CheckArity(0) {
+		t.Fatal(fn)
+	}
+	if err := reg.Register(&Function{Name: "abs", MinArgs: 1, MaxArgs: 1}); err == nil {
+		t.Fatal("Duplicated function should be fail")
+	}
+	if err := reg.Register(&Function{Name: "bad", MinArgs: 2, MaxArgs: 1}); err == nil {
+		t.Fatal("Bad arity should be fail")
+	}
+
+	// Registered in another registry
+	if _, found := Builtin().Lookup("my_fn"); found {
+		t.Fatal("fail")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assertValid(t, "SELECT COUNT(*), COUNT(DISTINCT a), SUM(b), COALESCE(c, d, 0) FROM t")
+	assertValid(t, "SELECT * FROM t WHERE a IN (SELECT MAX(a) FROM u) ORDER BY ABS(b)")
+	assertValid(t, "UPDATE t SET a = UPPER(b) WHERE LENGTH(c) > 1")
+	assertValid(t, "INSERT INTO t (a) VALUES (1) ON DUPLICATE KEY UPDATE a = VALUES(a) + 1")
+
+	assertInvalid(t, "SELECT COUNT(a, b, c) FROM t", "[7] Function COUNT() takes 1 argument(s), but 3 given")
+	assertInvalid(t, "SELECT NOSUCHFN(1)", "[7] Unknown function NOSUCHFN()")
+	assertInvalid(t, "SELECT SUM(*) FROM t", "[11] Function SUM() does not accept `*'")
+	assertInvalid(t, "SELECT ABS(DISTINCT a) FROM t", "[7] DISTINCT in non-aggregate function ABS()")
+	assertInvalid(t, "SELECT * FROM (SELECT CONCAT() FROM t) x", "[22] Function CONCAT() takes at least 1 argument(s), but 0 given")
+}
+
+func TestValidateAll(t *testing.T) {
+	cmd, err := parser.ParseCommand("SELECT f(1), g(2) FROM t WHERE ABS(1, 2) > 0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := Builtin().Validate(cmd); len(errs) != 3 {
+		t.Fatal(errs)
+	}
+}
+
+func assertValid(t *testing.T, sql string) {
+	cmd, err := parser.ParseCommand(sql)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := Builtin().Validate(cmd); errs != nil {
+		t.Fatal(sql, errs)
+	}
+}
+
+func assertInvalid(t *testing.T, sql, expected string) {
+	cmd, err := parser.ParseCommand(sql)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs := Builtin().Validate(cmd)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), expected) {
+		t.Fatal(sql, errs)
+	}
+}
@@ -0,0 +1,52 @@
+package function
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/emptyland/akino/sql/ast"
+	"github.com/emptyland/akino/sql/token"
+)
+
+// Validate all function calls in node, returns nil if no error.
+//
+// Errors: unknown function, wrong number of arguments, `*' or DISTINCT in
+// functions which do not accept them.
+func (self *Registry) Validate(node ast.Node) []error {
+	var errs []error
+	ast.Walk(node, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok {
+			if err := self.validateCall(call); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return true
+	})
+	return errs
+}
+
+func (self *Registry) validateCall(call *ast.CallExpr) error {
+	_, name := call.Func.Dequote()
+	name = strings.ToUpper(name)
+
+	fn, found := self.Lookup(name)
+	if !found {
+		return fmt.Errorf("[%d] Unknown function %s()", call.Pos(), name)
+	}
+
+	if !fn.CheckArity(len(call.Args)) {
+		return fmt.Errorf("[%d] Function %s() takes %s argument(s), but %d given",
+			call.Pos(), name, fn.Arity(), len(call.Args))
+	}
+
+	for _, arg := range call.Args {
+		if lit, ok := arg.(*ast.Literal); ok && lit.Kind == token.STAR && !fn.Star {
+			return fmt.Errorf("[%d] Function %s() does not accept `*'", lit.Pos(), name)
+		}
+	}
+
+	if call.Distinct && !fn.Aggregate {
+		return fmt.Errorf("[%d] DISTINCT in non-aggregate function %s()", call.Pos(), name)
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/emptyland/akino/sql/ast"
+	"github.com/emptyland/akino/sql/datetime"
 	"github.com/emptyland/akino/sql/token"
 )
 
@@ -19,14 +20,18 @@ func ParseExpression(expr string) (ast.Expr, error) {
 }
 
 type Parser struct {
-	cmd string
-	lah tokeniton // look a head
-	lex *token.Lexer
+	cmd   string
+	lah   tokeniton // look a head
+	lah2  tokeniton // look a head of look a head
+	ahead bool      // lah2 is valid
+	trig  bool      // NEW and OLD are pseudo tables in trigger
+	lex   *token.Lexer
 }
 
 func (self *Parser) Init(cmd string) *Parser {
 	self.cmd = cmd
 	self.lex = token.NewLexer(cmd)
+	self.ahead = false
 	self.skip()
 	return self
 }
@@ -48,7 +53,8 @@ func (self *Parser) NextStatement() (ast.Command, error) {
 func (self *Parser) Next() (ast.Command, error) {
 
 	switch self.peek() {
-	case token.BEGIN, token.START, token.COMMIT, token.ROLLBACK, token.END:
+	case token.BEGIN, token.START, token.COMMIT, token.ROLLBACK, token.END, token.SAVEPOINT,
+		token.RELEASE:
 		return self.parseTransaction(self.peek())
 
 	case token.ILLEGAL:
@@ -57,7 +63,49 @@ func (self *Parser) Next() (ast.Command, error) {
 	case token.SHOW:
 		return self.parseShow()
 
-	case token.SELECT:
+	case token.DESCRIBE, token.DESC:
+		return self.parseDescribe()
+
+	case token.EXPLAIN:
+		return self.parseExplain()
+
+	case token.DROP:
+		return self.parseDrop()
+
+	case token.USE:
+		return self.parseUse()
+
+	case token.ATTACH:
+		return self.parseAttach()
+
+	case token.DETACH:
+		return self.parseDetach()
+
+	case token.PRAGMA:
+		return self.parsePragma()
+
+	case token.VACUUM:
+		return self.parseVacuum()
+
+	case token.REINDEX:
+		return self.parseReindex()
+
+	case token.TRUNCATE:
+		return self.parseTruncate()
+
+	case token.PREPARE:
+		return self.parsePrepare()
+
+	case token.EXECUTE:
+		return self.parseExecute()
+
+	case token.DEALLOCATE:
+		return self.parseDeallocate()
+
+	case token.SET:
+		return self.parseSetVariable()
+
+	case token.SELECT, token.LPAREN:
 		return self.parseSelect()
 
 	case token.CREATE:
@@ -73,10 +121,30 @@ func (self *Parser) Next() (ast.Command, error) {
 		return self.parseDelete()
 
 	default:
+		if self.peekWord("ANALYZE") {
+			return self.parseAnalyze()
+		}
 		return nil, self.errorf(`Unknown command: "%s"`, self.peekLiteral())
 	}
 }
 
+//
+// Transaction ::= `BEGIN' TransType Trans TransOptList
+//               | `START' TransType Trans TransOptList
+//               | `COMMIT' Trans
+//               | `END' Trans
+//               | `ROLLBACK' Trans
+//               | `ROLLBACK' Trans `TO' Savepoint Identifier
+//               | `SAVEPOINT' Identifier
+//               | `RELEASE' Savepoint Identifier
+//
+// Trans       ::= `TRANSACTION'
+//               | `WORK'
+//               |
+//
+// Savepoint   ::= `SAVEPOINT'
+//               |
+//
 func (self *Parser) parseTransaction(op token.Token) (ast.Command, error) {
 	cmd := &ast.Transaction{
 		TransactionPos: self.peekPos(),
@@ -85,22 +153,112 @@ func (self *Parser) parseTransaction(op token.Token) (ast.Command, error) {
 	}
 	self.skip()
 
-	// Parse transaction type
-	if cmd.Op == token.BEGIN || cmd.Op == token.START {
+	var err error
+	switch cmd.Op {
+	case token.BEGIN, token.START:
+		// Parse transaction type
 		cmd.Type = self.parseTransactionType()
+		if !self.test(token.TRANSACTION) {
+			self.testWord("WORK")
+		}
+		if err = self.parseTransactionOption(cmd); err != nil {
+			return nil, err
+		}
+
+	case token.COMMIT, token.END:
+		if !self.test(token.TRANSACTION) {
+			self.testWord("WORK")
+		}
+
+	case token.ROLLBACK:
+		if !self.test(token.TRANSACTION) {
+			self.testWord("WORK")
+		}
+		if self.test(token.TO) {
+			self.test(token.SAVEPOINT)
+			if cmd.Savepoint, err = self.parseName(); err != nil {
+				return nil, err
+			}
+		}
+
+	case token.SAVEPOINT:
+		if cmd.Savepoint, err = self.parseName(); err != nil {
+			return nil, err
+		}
+
+	case token.RELEASE:
+		self.test(token.SAVEPOINT)
+		if cmd.Savepoint, err = self.parseName(); err != nil {
+			return nil, err
+		}
 	}
 
-	// Parse transaction option
 	switch self.peek() {
-	case token.EOF:
-		return cmd, nil
-
-	case token.TRANSACTION:
-		self.skip()
+	case token.EOF, token.SEMI:
+		cmd.TransactionEnd = self.peekPos()
 		return cmd, nil
 
 	default:
-		return cmd, nil
+		return nil, self.errorf(`Bad transaction command, unexpected "%s"`, self.peekLiteral())
+	}
+}
+
+//
+// TransOptList ::= TransOptList OptComma TransOpt
+//                |
+//
+// TransOpt     ::= `ISOLATION' `LEVEL' IsolationLevel
+//                | `READ' `ONLY'
+//                | `READ' `WRITE'
+//
+// IsolationLevel ::= `READ' `UNCOMMITTED'
+//                  | `READ' `COMMITTED'
+//                  | `REPEATABLE' `READ'
+//                  | `SERIALIZABLE'
+//
+func (self *Parser) parseTransactionOption(cmd *ast.Transaction) error {
+	for {
+		switch {
+		case self.testWord("ISOLATION"):
+			if !self.testWord("LEVEL") {
+				return self.errorf(`Unexpected "%s", expected "LEVEL"`, self.peekLiteral())
+			}
+			switch {
+			case self.testWord("READ"):
+				if self.testWord("UNCOMMITTED") {
+					cmd.Isolation = ast.ISOLATION_READ_UNCOMMITTED
+				} else if self.testWord("COMMITTED") {
+					cmd.Isolation = ast.ISOLATION_READ_COMMITTED
+				} else {
+					return self.errorf(`Bad isolation level, unexpected "%s"`, self.peekLiteral())
+				}
+
+			case self.testWord("REPEATABLE"):
+				if !self.testWord("READ") {
+					return self.errorf(`Unexpected "%s", expected "READ"`, self.peekLiteral())
+				}
+				cmd.Isolation = ast.ISOLATION_REPEATABLE_READ
+
+			case self.testWord("SERIALIZABLE"):
+				cmd.Isolation = ast.ISOLATION_SERIALIZABLE
+
+			default:
+				return self.errorf(`Bad isolation level, unexpected "%s"`, self.peekLiteral())
+			}
+
+		case self.testWord("READ"):
+			if self.testWord("ONLY") {
+				cmd.ReadOnly = true
+			} else if self.testWord("WRITE") {
+				cmd.ReadOnly = false
+			} else {
+				return self.errorf(`Bad transaction access mode, unexpected "%s"`, self.peekLiteral())
+			}
+
+		default:
+			return nil
+		}
+		self.test(token.COMMA)
 	}
 }
 
@@ -118,21 +276,261 @@ func (self *Parser) parseTransactionType() token.Token {
 	return rv
 }
 
+//
+// Show      ::= `SHOW' `DATABASES' ShowLike
+//             | `SHOW' Full `TABLES' ShowFrom ShowLike
+//             | `SHOW' Full Columns `FROM' NameRef ShowFrom ShowLike
+//             | `SHOW' Index `FROM' NameRef ShowFrom Where
+//             | `SHOW' `CREATE' `TABLE' NameRef
+//
+// Full      ::= `FULL'
+//             |
+//
+// Columns   ::= `COLUMNS' | `FIELDS'
+//
+// Index     ::= `INDEX' | `INDEXES' | `KEYS'
+//
+// ShowFrom  ::= `FROM' Identifier
+//             | `IN' Identifier
+//             |
+//
+// ShowLike  ::= `LIKE' StringLiteral
+//             | `WHERE' Expr
+//             |
+//
 func (self *Parser) parseShow() (ast.Command, error) {
 	cmd := &ast.Show{
 		ShowPos: self.peekPos(),
 	}
 	self.skip() // skip "SHOW"
 
-	switch self.peek() {
-	case token.DATABASES, token.TABLES:
+	cmd.Full = self.testWord("FULL")
+
+	var err error
+	switch {
+	case self.peek() == token.DATABASES:
+		if cmd.Full {
+			return nil, self.errorf(`Bad show command, unexpected: "%s"`, self.peekLiteral())
+		}
+		cmd.Dest = self.peek()
+		self.skip()
+
+	case self.peek() == token.TABLES:
+		cmd.Dest = self.peek()
+		self.skip()
+		if self.test(token.FROM) || self.test(token.IN) {
+			if cmd.Database, err = self.parseName(); err != nil {
+				return nil, err
+			}
+		}
+
+	case self.peekWord("COLUMNS") || self.peekWord("FIELDS"):
+		cmd.Dest = token.COLUMNS
+		self.skip()
+		if err = self.parseShowTarget(cmd); err != nil {
+			return nil, err
+		}
+
+	case self.peek() == token.INDEX || self.peekWord("INDEXES") || self.peekWord("KEYS"):
+		if cmd.Full {
+			return nil, self.errorf(`Bad show command, unexpected: "%s"`, self.peekLiteral())
+		}
+		cmd.Dest = token.INDEX
+		self.skip()
+		if err = self.parseShowTarget(cmd); err != nil {
+			return nil, err
+		}
+
+	case self.peek() == token.CREATE:
+		if cmd.Full {
+			return nil, self.errorf(`Bad show command, unexpected: "%s"`, self.peekLiteral())
+		}
 		cmd.Dest = self.peek()
 		self.skip()
+		if _, err = self.match(token.TABLE); err != nil {
+			return nil, err
+		}
+		if cmd.Target, err = self.parseNameRef(); err != nil {
+			return nil, err
+		}
+		cmd.ShowEnd = self.peekPos()
 		return cmd, nil
 
 	default:
 		return nil, self.errorf(`Bad show command, unexpected: "%s"`, self.peekLiteral())
 	}
+
+	switch {
+	case self.peek() == token.LIKE && cmd.Dest != token.INDEX:
+		self.skip()
+		if self.peek() != token.STRING_LITERAL {
+			return nil, self.errorf("LIKE operator need string pattern")
+		}
+		cmd.Like = &ast.Literal{
+			ValuePos: self.peekPos(),
+			Value:    self.peekLiteral(),
+			Kind:     self.peek(),
+		}
+		self.skip()
+
+	case self.test(token.WHERE):
+		if cmd.Where, err = self.NextExpr(); err != nil {
+			return nil, err
+		}
+	}
+	cmd.ShowEnd = self.peekPos()
+	return cmd, nil
+}
+
+func (self *Parser) parseShowTarget(cmd *ast.Show) error {
+	var err error
+	if !self.test(token.FROM) {
+		if _, err = self.match(token.IN); err != nil {
+			return err
+		}
+	}
+	if cmd.Target, err = self.parseNameRef(); err != nil {
+		return err
+	}
+	if self.peek() == token.FROM || self.peek() == token.IN {
+		if cmd.Target.Second != "" {
+			return self.errorf(`Unexpected "%s", table name is already qualified`,
+				self.peek().String())
+		}
+		self.skip()
+		cmd.Target.Second = cmd.Target.First
+		if cmd.Target.First, err = self.parseName(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//
+// Describe ::= `DESCRIBE' NameRef
+//            | `DESC' NameRef
+//            | `DESCRIBE' Command
+//
+// As same as: `SHOW' `COLUMNS' `FROM' NameRef or `EXPLAIN' Command
+func (self *Parser) parseDescribe() (ast.Command, error) {
+	cmd := &ast.Show{
+		ShowPos: self.peekPos(),
+		Dest:    token.COLUMNS,
+	}
+	pos := self.peekPos()
+	self.skip() // skip `DESCRIBE'
+
+	switch self.peek() {
+	case token.SELECT, token.LPAREN, token.INSERT, token.REPLACE, token.UPDATE, token.DELETE:
+		return self.parseExplainCommand(&ast.Explain{
+			ExplainPos: pos,
+			Format:     token.TEXT,
+		})
+	}
+
+	var err error
+	if cmd.Target, err = self.parseNameRef(); err != nil {
+		return nil, err
+	}
+	cmd.ShowEnd = self.peekPos()
+	return cmd, nil
+}
+
+//
+// Explain     ::= `EXPLAIN' ExplainOpts Command
+//
+// ExplainOpts ::= `QUERY' `PLAN' Format
+//               | `ANALYZE' Format
+//               | `(' ExplainOptList `)'
+//               | Format
+//
+// Format      ::= `FORMAT' OptEq FormatType
+//               |
+//
+// FormatType  ::= `TEXT' | `JSON' | `YAML'
+//
+func (self *Parser) parseExplain() (ast.Command, error) {
+	cmd := &ast.Explain{
+		ExplainPos: self.peekPos(),
+		Format:     token.TEXT,
+	}
+	self.skip() // skip `EXPLAIN'
+
+	var err error
+	switch {
+	case self.testWord("QUERY"):
+		if !self.testWord("PLAN") {
+			return nil, self.errorf(`Unexpected "%s", expected "PLAN"`, self.peekLiteral())
+		}
+		cmd.QueryPlan = true
+
+	case self.testWord("ANALYZE"):
+		cmd.Analyze = true
+
+	case self.peek() == token.LPAREN && self.isExplainOption():
+		self.skip() // skip `('
+		for {
+			if self.testWord("ANALYZE") {
+				cmd.Analyze = true
+			} else if cmd.Format, err = self.parseExplainFormat(); err != nil {
+				return nil, err
+			}
+			if !self.test(token.COMMA) {
+				break
+			}
+		}
+		if _, err = self.match(token.RPAREN); err != nil {
+			return nil, err
+		}
+		return self.parseExplainCommand(cmd)
+	}
+
+	if self.peek() == token.ID {
+		if cmd.Format, err = self.parseExplainFormat(); err != nil {
+			return nil, err
+		}
+	}
+	return self.parseExplainCommand(cmd)
+}
+
+// `(' is an option list if an option follows, otherwise it starts a query:
+// EXPLAIN (SELECT ...) UNION (SELECT ...)
+func (self *Parser) isExplainOption() bool {
+	return self.peekNext() == token.ID && (strings.EqualFold(self.lah2.Literal, "ANALYZE") ||
+		strings.EqualFold(self.lah2.Literal, "FORMAT"))
+}
+
+func (self *Parser) parseExplainFormat() (token.Token, error) {
+	if !self.testWord("FORMAT") {
+		return token.ILLEGAL, self.errorf(`Bad explain option, unexpected "%s"`, self.peekLiteral())
+	}
+	self.test(token.EQ)
+
+	switch {
+	case self.test(token.TEXT):
+		return token.TEXT, nil
+	case self.testWord("JSON"):
+		return token.JSON, nil
+	case self.testWord("YAML"):
+		return token.YAML, nil
+	default:
+		return token.ILLEGAL, self.errorf(`Bad explain format, unexpected "%s"`, self.peekLiteral())
+	}
+}
+
+// Only queries and DML commands have plan.
+func (self *Parser) parseExplainCommand(cmd *ast.Explain) (ast.Command, error) {
+	switch self.peek() {
+	case token.SELECT, token.LPAREN, token.INSERT, token.REPLACE, token.UPDATE, token.DELETE:
+	default:
+		return nil, self.errorf(`Bad explain command, unexpected "%s"`, self.peekLiteral())
+	}
+
+	var err error
+	if cmd.Cmd, err = self.Next(); err != nil {
+		return nil, err
+	}
+	return cmd, nil
 }
 
 func (self *Parser) parseCreate() (ast.Command, error) {
@@ -143,8 +541,14 @@ func (self *Parser) parseCreate() (ast.Command, error) {
 
 	case token.TEMP:
 		self.skip()
+		if self.peek() == token.TRIGGER {
+			return self.parseCreateTrigger(true)
+		}
 		return self.parseCreateTable(true)
 
+	case token.TRIGGER:
+		return self.parseCreateTrigger(false)
+
 	case token.INDEX:
 		return self.parseCreateIndex(false)
 
@@ -153,36 +557,43 @@ func (self *Parser) parseCreate() (ast.Command, error) {
 		return self.parseCreateIndex(true)
 
 	default:
+		if self.peekWord("DATABASE") {
+			return self.parseCreateDatabase()
+		}
 		return nil, self.errorf(`Bad create statement, unexpected "%s"`, self.peek().String())
 	}
 }
 
+func (self *Parser) parseDrop() (ast.Command, error) {
+	self.skip() // skip `DROP'
+	switch self.peek() {
+	case token.TRIGGER:
+		return self.parseDropTrigger()
+
+	case token.PREPARE:
+		return self.parseDeallocate()
+
+	default:
+		if self.peekWord("DATABASE") {
+			return self.parseDropDatabase()
+		}
+		return nil, self.errorf(`Bad drop statement, unexpected "%s"`, self.peek().String())
+	}
+}
+
 //------------------------------------------------------------------------------
-// Create Table Actions:
+// Database Actions:
 //------------------------------------------------------------------------------
 //
-// CreateTable ::= `CREATE' Temp `TABLE' IfNotExists NameRef CreateTableArgs
-//
-// Temp        ::= `TEMP'
-//               |
-//
-// IfNotExists ::= `IF' `NOT' `EXISTS'
-//               |
-//
+// CreateDatabase ::= `CREATE' `DATABASE' IfNotExists Identifier TableOptions
 //
-func (self *Parser) parseCreateTable(temp bool) (*ast.CreateTable, error) {
-	var err error
-
-	cmd := &ast.CreateTable{
-		CreatePos:   self.peekPos(),
-		Temp:        temp,
-		IfNotExists: false,
-	}
-
-	if _, err = self.match(token.TABLE); err != nil {
-		return nil, err
+func (self *Parser) parseCreateDatabase() (*ast.CreateDatabase, error) {
+	cmd := &ast.CreateDatabase{
+		CreatePos: self.peekPos(),
 	}
+	self.skip() // skip `DATABASE'
 
+	var err error
 	if self.test(token.IF) {
 		if err = self.batchMatch(token.NOT, token.EXISTS); err != nil {
 			return nil, err
@@ -190,44 +601,278 @@ func (self *Parser) parseCreateTable(temp bool) (*ast.CreateTable, error) {
 		cmd.IfNotExists = true
 	}
 
-	if cmd.Table, err = self.parseNameRef(); err != nil {
+	if cmd.Name, err = self.parseName(); err != nil {
+		return nil, err
+	}
+	if err = self.parseTableOptions(&cmd.Options); err != nil {
 		return nil, err
 	}
+	cmd.CreateEnd = self.peekPos()
+	return cmd, nil
+}
 
-	switch self.peek() {
-	case token.LPAREN:
-		self.skip()
-		if cmd.Scheme, err = self.parseColumnScheme(cmd); err != nil {
-			return nil, err
-		}
-		if _, err = self.match(token.RPAREN); err != nil {
-			return nil, err
-		}
+//
+// DropDatabase ::= `DROP' `DATABASE' IfExists Identifier
+//
+// IfExists     ::= `IF' `EXISTS'
+//                |
+//
+func (self *Parser) parseDropDatabase() (*ast.DropDatabase, error) {
+	cmd := &ast.DropDatabase{
+		DropPos: self.peekPos(),
+	}
+	self.skip() // skip `DATABASE'
 
-	case token.AS:
-		self.skip()
-		if cmd.Template, err = self.parseSelect(); err != nil {
+	var err error
+	if self.test(token.IF) {
+		if _, err = self.match(token.EXISTS); err != nil {
 			return nil, err
 		}
+		cmd.IfExists = true
+	}
 
-	default:
-		return nil, self.errorf(`No table scheme be specified, unexpected "%s"`, self.peek().String())
+	if cmd.Name, err = self.parseName(); err != nil {
+		return nil, err
 	}
-	cmd.CreateEnd = self.peekPos()
+	cmd.DropEnd = self.peekPos()
 	return cmd, nil
 }
 
 //
-// ColumnScheme     ::= ColumnScheme `,' ColumnDefine
-//
-// ColumnDefine     ::= Identifer TypeDecl ColumnOptionList
-//
-// ColumnOptionList ::= ColumnOptionList ColumnOption
-//                    | ColumnOption
-//                    |
+// Use ::= `USE' Identifier
 //
-func (self *Parser) parseColumnScheme(cmd *ast.CreateTable) ([]ast.ColumnDefine, error) {
-	scheme := make([]ast.ColumnDefine, 0)
+func (self *Parser) parseUse() (*ast.Use, error) {
+	cmd := &ast.Use{
+		UsePos: self.peekPos(),
+	}
+	self.skip() // skip `USE'
+
+	var err error
+	if cmd.Name, err = self.parseName(); err != nil {
+		return nil, err
+	}
+	cmd.UseEnd = self.peekPos()
+	return cmd, nil
+}
+
+//
+// Attach ::= `ATTACH' Database Expr `AS' Identifier
+//
+// Database ::= `DATABASE'
+//            |
+//
+func (self *Parser) parseAttach() (*ast.Attach, error) {
+	cmd := &ast.Attach{
+		AttachPos: self.peekPos(),
+	}
+	self.skip() // skip `ATTACH'
+	if self.peekNext() != token.AS {
+		self.testWord("DATABASE") // Not the file: ATTACH database AS aux
+	}
+
+	var err error
+	if cmd.File, err = self.NextExpr(); err != nil {
+		return nil, err
+	}
+	if cmd.Name, err = self.parseAliasName(); err != nil {
+		return nil, err
+	}
+	cmd.AttachEnd = self.peekPos()
+	return cmd, nil
+}
+
+//
+// Detach ::= `DETACH' Database Identifier
+//
+func (self *Parser) parseDetach() (*ast.Detach, error) {
+	cmd := &ast.Detach{
+		DetachPos: self.peekPos(),
+	}
+	self.skip() // skip `DETACH'
+	if self.peekNext() == token.ID {
+		self.testWord("DATABASE") // Not the name: DETACH database
+	}
+
+	var err error
+	if cmd.Name, err = self.parseName(); err != nil {
+		return nil, err
+	}
+	cmd.DetachEnd = self.peekPos()
+	return cmd, nil
+}
+
+//------------------------------------------------------------------------------
+// Create Table Actions:
+//------------------------------------------------------------------------------
+//
+// CreateTable ::= `CREATE' Temp `TABLE' IfNotExists NameRef CreateTableArgs
+//
+// Temp        ::= `TEMP'
+//               |
+//
+// IfNotExists ::= `IF' `NOT' `EXISTS'
+//               |
+//
+//
+func (self *Parser) parseCreateTable(temp bool) (*ast.CreateTable, error) {
+	var err error
+
+	cmd := &ast.CreateTable{
+		CreatePos:   self.peekPos(),
+		Temp:        temp,
+		IfNotExists: false,
+	}
+
+	if _, err = self.match(token.TABLE); err != nil {
+		return nil, err
+	}
+
+	if self.test(token.IF) {
+		if err = self.batchMatch(token.NOT, token.EXISTS); err != nil {
+			return nil, err
+		}
+		cmd.IfNotExists = true
+	}
+
+	if cmd.Table, err = self.parseNameRef(); err != nil {
+		return nil, err
+	}
+
+	switch self.peek() {
+	case token.LPAREN:
+		self.skip()
+		if cmd.Scheme, err = self.parseColumnScheme(cmd); err != nil {
+			return nil, err
+		}
+		if _, err = self.match(token.RPAREN); err != nil {
+			return nil, err
+		}
+		if err = self.parseTableOptions(&cmd.Options); err != nil {
+			return nil, err
+		}
+
+	case token.AS:
+		self.skip()
+		if cmd.Template, err = self.parseSelect(); err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, self.errorf(`No table scheme be specified, unexpected "%s"`, self.peek().String())
+	}
+	cmd.CreateEnd = self.peekPos()
+	return cmd, nil
+}
+
+//
+// TableOptions ::= TableOptions OptComma TableOption
+//                |
+//
+// TableOption  ::= Default `CHARACTER' `SET' OptEq Identifier
+//                | Default `CHARSET' OptEq Identifier
+//                | Default `COLLATE' OptEq Identifier
+//                | `AUTO_INCREMENT' OptEq IntLiteral
+//                | `ENGINE' OptEq Identifier
+//                | `COMMENT' OptEq StringLiteral
+//                | Identifier OptEq OptionValue
+//
+// Default      ::= `DEFAULT'
+//                |
+//
+func (self *Parser) parseTableOptions(opts *ast.TableOptions) error {
+	var err error
+	for {
+		def := self.test(token.DEFAULT)
+
+		switch self.peek() {
+		case token.CHARACTER:
+			self.skip()
+			if _, err = self.match(token.SET); err != nil {
+				return err
+			}
+			self.test(token.EQ)
+			if opts.Charset, err = self.parseName(); err != nil {
+				return err
+			}
+
+		case token.COLLATE:
+			self.skip()
+			self.test(token.EQ)
+			if opts.Collate, err = self.parseName(); err != nil {
+				return err
+			}
+
+		case token.AUTO_INCREMENT:
+			self.skip()
+			self.test(token.EQ)
+			if opts.AutoIncr, err = self.parseIntLiteral(); err != nil {
+				return err
+			}
+
+		case token.ID:
+			name := strings.ToUpper(self.peekLiteral())
+			self.skip()
+			self.test(token.EQ)
+
+			var value string
+			if value, err = self.parseOptionValue(); err != nil {
+				return err
+			}
+			switch name {
+			case "CHARSET":
+				opts.Charset = value
+			case "ENGINE":
+				opts.Engine = value
+			case "COMMENT":
+				opts.Comment = value
+			default:
+				if opts.Extra == nil {
+					opts.Extra = make(map[string]string)
+				}
+				opts.Extra[name] = value
+			}
+
+		default:
+			if def {
+				return self.errorf(`Bad table option, unexpected "%s"`, self.peek().String())
+			}
+			return nil
+		}
+		self.test(token.COMMA)
+	}
+}
+
+//
+// OptionValue ::= Identifier
+//               | StringLiteral
+//               | IntLiteral
+//
+func (self *Parser) parseOptionValue() (string, error) {
+	switch self.peek() {
+	case token.ID:
+		return self.parseName()
+
+	case token.STRING_LITERAL, token.INT_LITERAL, token.FLOAT_LITERAL:
+		value := unquote(self.peekLiteral())
+		self.skip()
+		return value, nil
+
+	default:
+		return "", self.errorf(`Bad option value, unexpected "%s"`, self.peek().String())
+	}
+}
+
+//
+// ColumnScheme     ::= ColumnScheme `,' ColumnDefine
+//
+// ColumnDefine     ::= Identifer TypeDecl ColumnOptionList
+//
+// ColumnOptionList ::= ColumnOptionList ColumnOption
+//                    | ColumnOption
+//                    |
+//
+func (self *Parser) parseColumnScheme(cmd *ast.CreateTable) ([]ast.ColumnDefine, error) {
+	scheme := make([]ast.ColumnDefine, 0)
 
 	for {
 		def := ast.ColumnDefine{
@@ -262,12 +907,12 @@ func (self *Parser) parseColumnScheme(cmd *ast.CreateTable) ([]ast.ColumnDefine,
 		if !self.test(token.COMMA) {
 			break
 		}
-		if ok, err = self.parseColDefOption(cmd, scheme); err != nil {
+		if ok, err = self.parseColDefOption(cmd, scheme, ""); err != nil {
 			return scheme, err
 		}
 		if ok {
 			for self.test(token.COMMA) {
-				if ok, err = self.parseColDefOption(cmd, scheme); err != nil {
+				if ok, err = self.parseColDefOption(cmd, scheme, ""); err != nil {
 					return scheme, err
 				}
 				if !ok {
@@ -290,6 +935,12 @@ func (self *Parser) parseColumnScheme(cmd *ast.CreateTable) ([]ast.ColumnDefine,
 //                | `UNIQUE' OnConf
 //                | `CHECK' `(' Expr `)'
 //                | `COLLATE' Identifier
+//                | ForeignKeyClause
+//                | `NOT' `DEFERRABLE'
+//                | `CONSTRAINT' Identifier ColumnOption
+//                | `AUTO_INCREMENT'
+//                | `ON' `UPDATE' Expr
+//                | `COMMENT' StringLiteral
 //                |
 //
 // AutoIncr     ::= `AUTOINCR'
@@ -299,6 +950,47 @@ func (self *Parser) parseColumnOption(cmd *ast.CreateTable, def *ast.ColumnDefin
 	var err error
 
 	switch self.peek() {
+	case token.CONSTRAINT:
+		self.skip()
+		elem := ast.Constraint{
+			Column: []string{def.Name},
+		}
+		if elem.Name, err = self.parseName(); err != nil {
+			return false, err
+		}
+		if elem.Kind = self.peek(); elem.Kind == token.REFERENCES {
+			elem.Kind = token.FOREIGN
+		}
+
+		var ok bool
+		if elem.Kind != token.CONSTRAINT {
+			if ok, err = self.parseColumnOption(cmd, def); err != nil {
+				return false, err
+			}
+		}
+		if !ok {
+			return false, self.errorf(`Bad constraint "%s", unexpected "%s"`, elem.Name,
+				self.peek().String())
+		}
+		switch elem.Kind {
+		case token.CHECK:
+			elem.Check = cmd.CheckConstraint[len(cmd.CheckConstraint)-1]
+
+		case token.FOREIGN:
+			def.ForeignKey.Name = elem.Name
+		}
+		cmd.Constraint = append(cmd.Constraint, elem)
+		return true, nil
+
+	case token.REFERENCES:
+		def.ForeignKey = &ast.ForeignKey{
+			Column: []string{def.Name},
+		}
+		if err = self.parseForeignKey(def.ForeignKey); err != nil {
+			return false, err
+		}
+		return true, nil
+
 	case token.DEFAULT:
 		self.skip()
 		if self.peek() == token.LPAREN {
@@ -322,6 +1014,15 @@ func (self *Parser) parseColumnOption(cmd *ast.CreateTable, def *ast.ColumnDefin
 
 	case token.NOT:
 		self.skip()
+		if self.test(token.DEFERRABLE) {
+			if def.ForeignKey == nil {
+				return false, self.errorf(`"NOT DEFERRABLE" without foreign key`)
+			}
+			if err = self.parseDeferrable(def.ForeignKey, false); err != nil {
+				return false, err
+			}
+			return true, nil
+		}
 		if _, err = self.match(token.NULL); err != nil {
 			return false, err
 		}
@@ -381,6 +1082,34 @@ func (self *Parser) parseColumnOption(cmd *ast.CreateTable, def *ast.ColumnDefin
 			return true, nil
 		}
 
+	case token.AUTO_INCREMENT:
+		self.skip()
+		def.AutoIncr = true
+		return true, nil
+
+	case token.ON:
+		if self.peekNext() != token.UPDATE {
+			return false, nil
+		}
+		self.skip() // skip `ON'
+		self.skip() // skip `UPDATE'
+		if def.OnUpdate, err = self.NextExpr(); err != nil {
+			return false, err
+		}
+		return true, nil
+
+	case token.ID:
+		if !self.testWord("COMMENT") {
+			return false, nil
+		}
+		if self.peek() != token.STRING_LITERAL {
+			return false, self.errorf(`COMMENT need string literal, unexpected "%s"`,
+				self.peek().String())
+		}
+		def.Comment = unquote(self.peekLiteral())
+		self.skip()
+		return true, nil
+
 	default:
 		return false, nil
 	}
@@ -390,11 +1119,62 @@ func (self *Parser) parseColumnOption(cmd *ast.CreateTable, def *ast.ColumnDefin
 // ColDefOption ::= `PRIMARY' `KEY' `(' IdxDefList AutoIncr `)' OnConf
 //                | `UNIQUE' `(' IdxDefList `)' OnConf
 //                | `CHECK' `(' Expr `)'
+//                | `FOREIGN' `KEY' `(' NameList `)' ForeignKeyClause
+//                | `CONSTRAINT' Identifier ColDefOption
 //
 //
-func (self *Parser) parseColDefOption(cmd *ast.CreateTable, scheme []ast.ColumnDefine) (bool, error) {
+func (self *Parser) parseColDefOption(cmd *ast.CreateTable, scheme []ast.ColumnDefine,
+	name string) (bool, error) {
 	var err error
 	switch self.peek() {
+	case token.CONSTRAINT:
+		if name != "" {
+			return false, self.errorf(`Bad constraint "%s", unexpected "%s"`, name,
+				self.peek().String())
+		}
+		self.skip()
+		if name, err = self.parseName(); err != nil {
+			return false, err
+		}
+
+		var ok bool
+		if ok, err = self.parseColDefOption(cmd, scheme, name); err != nil {
+			return false, err
+		}
+		if !ok {
+			return false, self.errorf(`Bad constraint "%s", unexpected "%s"`, name,
+				self.peek().String())
+		}
+		return true, nil
+
+	case token.FOREIGN:
+		self.skip()
+		if err = self.batchMatch(token.KEY, token.LPAREN); err != nil {
+			return false, err
+		}
+
+		fk := ast.ForeignKey{Name: name}
+		if fk.Column, err = self.parseNameList(); err != nil {
+			return false, err
+		}
+		if _, err = self.match(token.RPAREN); err != nil {
+			return false, err
+		}
+		if err = self.parseForeignKey(&fk); err != nil {
+			return false, err
+		}
+		if self.test(token.NOT) {
+			if _, err = self.match(token.DEFERRABLE); err != nil {
+				return false, err
+			}
+			if err = self.parseDeferrable(&fk, false); err != nil {
+				return false, err
+			}
+		}
+		cmd.ForeignKey = append(cmd.ForeignKey, fk)
+		addConstraint(cmd, name, token.FOREIGN, fk.Column, nil)
+		return true, nil
+
 	case token.PRIMARY:
 		self.skip()
 		if err = self.batchMatch(token.KEY, token.LPAREN); err != nil {
@@ -421,12 +1201,18 @@ func (self *Parser) parseColDefOption(cmd *ast.CreateTable, scheme []ast.ColumnD
 		if onconf, err = self.parseOnConf(); err != nil {
 			return false, err
 		}
+		// Columns of table-level PRIMARY KEY are primary key columns as well, and
+		// AUTO_INCREMENT and COLLATE declared in column definitions are kept.
 		travelColumnDefine(list, scheme, func(idx *ast.IndexDefine, def *ast.ColumnDefine) {
-			def.AutoIncr = autoincr
+			def.AutoIncr = def.AutoIncr || autoincr
+			def.PrimaryKey = true
 			def.PrimaryKeyDesc = idx.Desc
 			def.PrimaryKeyOn = onconf
-			def.Collate = idx.Collate
+			if idx.Collate != "" {
+				def.Collate = idx.Collate
+			}
 		})
+		addConstraint(cmd, name, token.PRIMARY, indexNames(list), nil)
 		return true, nil
 
 	case token.UNIQUE:
@@ -453,129 +1239,728 @@ func (self *Parser) parseColDefOption(cmd *ast.CreateTable, scheme []ast.ColumnD
 			def.Unique = true
 			def.UniqueOn = onconf
 		})
+		addConstraint(cmd, name, token.UNIQUE, indexNames(list), nil)
+		return true, nil
+
+	case token.CHECK:
+		self.skip()
+		if _, err = self.match(token.LPAREN); err != nil {
+			return false, err
+		}
+
+		var expr ast.Expr
+		if expr, err = self.NextExpr(); err != nil {
+			return false, err
+		}
+
+		if _, err = self.match(token.RPAREN); err != nil {
+			return false, err
+		}
+
+		cmd.CheckConstraint = append(cmd.CheckConstraint, expr)
+		addConstraint(cmd, name, token.CHECK, nil, expr)
 		return true, nil
 
-	case token.CHECK:
-		self.skip()
-		if _, err = self.match(token.LPAREN); err != nil {
-			return false, err
-		}
+	default:
+		return false, nil
+	}
+}
+
+// Table constraints are recorded even if they are unnamed, columns are in the
+// declared order.
+func addConstraint(cmd *ast.CreateTable, name string, kind token.Token, column []string,
+	check ast.Expr) {
+	cmd.Constraint = append(cmd.Constraint, ast.Constraint{
+		Name:   name,
+		Kind:   kind,
+		Column: column,
+		Check:  check,
+	})
+}
+
+func indexNames(idx []ast.IndexDefine) []string {
+	name := make([]string, 0, len(idx))
+	for _, elem := range idx {
+		name = append(name, elem.Name)
+	}
+	return name
+}
+
+func travelColumnDefine(idx []ast.IndexDefine, scheme []ast.ColumnDefine,
+	closure func(idx *ast.IndexDefine, def *ast.ColumnDefine)) {
+	for i := 0; i < len(idx); i++ {
+		for j := 0; j < len(scheme); j++ {
+			if idx[i].Name == scheme[j].Name {
+				closure(&idx[i], &scheme[j])
+			}
+		}
+	}
+}
+
+//
+// ForeignKeyClause ::= `REFERENCES' NameRef RefColList RefArgs Deferrable
+//
+// RefColList       ::= `(' NameList `)'
+//                    |
+//
+// RefArgs          ::= RefArgs `ON' `DELETE' RefAct
+//                    | RefArgs `ON' `UPDATE' RefAct
+//                    |
+//
+// RefAct           ::= `SET' `NULL'
+//                    | `SET' `DEFAULT'
+//                    | `CASCADE'
+//                    | `RESTRICT'
+//                    | `NO' `ACTION'
+//
+// Deferrable       ::= `DEFERRABLE' InitDeferred
+//                    |
+//
+// InitDeferred     ::= `INITIALLY' `DEFERRED'
+//                    | `INITIALLY' `IMMEDIATE'
+//                    |
+//
+func (self *Parser) parseForeignKey(fk *ast.ForeignKey) error {
+	var err error
+	if _, err = self.match(token.REFERENCES); err != nil {
+		return err
+	}
+	if fk.Table, err = self.parseNameRef(); err != nil {
+		return err
+	}
+	if pos := self.peekPos(); self.test(token.LPAREN) {
+		if fk.RefColumn, err = self.parseNameList(); err != nil {
+			return err
+		}
+		if _, err = self.match(token.RPAREN); err != nil {
+			return err
+		}
+		if len(fk.RefColumn) != len(fk.Column) {
+			return self.errorAt(pos, "Foreign key has %d column(s), but %d referenced",
+				len(fk.Column), len(fk.RefColumn))
+		}
+	}
+
+	fk.OnDelete = token.NO_ACTION
+	fk.OnUpdate = token.NO_ACTION
+	var onDelete, onUpdate bool
+	for self.test(token.ON) {
+		switch self.peek() {
+		case token.DELETE:
+			if onDelete {
+				return self.errorf("Duplicated ON DELETE in foreign key")
+			}
+			onDelete = true
+			self.skip()
+			if fk.OnDelete, err = self.parseRefAct(); err != nil {
+				return err
+			}
+
+		case token.UPDATE:
+			if onUpdate {
+				return self.errorf("Duplicated ON UPDATE in foreign key")
+			}
+			onUpdate = true
+			self.skip()
+			if fk.OnUpdate, err = self.parseRefAct(); err != nil {
+				return err
+			}
+
+		default:
+			return self.errorf(`Bad foreign key action, unexpected "%s"`, self.peek().String())
+		}
+	}
+
+	if self.test(token.DEFERRABLE) {
+		return self.parseDeferrable(fk, true)
+	}
+	return nil
+}
+
+func (self *Parser) parseRefAct() (token.Token, error) {
+	switch self.peek() {
+	case token.SET:
+		self.skip()
+		if self.test(token.NULL) {
+			return token.SET_NULL, nil
+		}
+		if self.test(token.DEFAULT) {
+			return token.SET_DEFAULT, nil
+		}
+
+	case token.ID:
+		switch {
+		case self.testWord("CASCADE"):
+			return token.CASCADE, nil
+		case self.testWord("RESTRICT"):
+			return token.RESTRICT, nil
+		case self.testWord("NO"):
+			if !self.testWord("ACTION") {
+				return token.ILLEGAL, self.errorf(`Unexpected "%s", expected "ACTION"`,
+					self.peekLiteral())
+			}
+			return token.NO_ACTION, nil
+		}
+	}
+	return token.ILLEGAL, self.errorf(`Bad foreign key action, unexpected "%s"`, self.peek().String())
+}
+
+func (self *Parser) parseDeferrable(fk *ast.ForeignKey, deferrable bool) error {
+	fk.Deferrable = deferrable
+	if self.test(token.INITIALLY) {
+		if self.test(token.DEFERRED) {
+			fk.InitiallyDeferred = true
+		} else if self.test(token.IMMEDIATE) {
+			fk.InitiallyDeferred = false
+		} else {
+			return self.errorf(`Unexpected "%s", expected DEFERRED or IMMEDIATE`,
+				self.peekLiteral())
+		}
+	}
+	return nil
+}
+
+//
+// OnConf  ::= `ON' `CONFLICT' Resolve
+//           |
+//
+// Resolve ::= Raise
+//           | `IGNORE'
+//           | `DEFAULT'
+//           | `REPLACE'
+//
+// Raise   ::= `ROLLBACK'
+//           | `ABORT'
+//           | `FAIL'
+//
+func (self *Parser) parseOnConf() (token.Token, error) {
+	if self.peek() != token.ON || self.peekNext() != token.CONFLICT {
+		return token.DEFAULT, nil
+	}
+	self.skip() // skip `ON'
+
+	var err error
+	if _, err = self.match(token.CONFLICT); err != nil {
+		return token.ILLEGAL, err
+	}
+
+	conf := self.peek()
+	switch conf {
+	case token.IGNORE, token.DEFAULT, token.REPLACE, token.ROLLBACK, token.ABORT,
+		token.FAIL:
+		self.skip()
+		return conf, nil
+
+	default:
+		return token.ILLEGAL, self.errorf(`Bad "ON CONFLICT" option`)
+	}
+}
+
+//------------------------------------------------------------------------------
+// Create Index Actions:
+//------------------------------------------------------------------------------
+//
+// CreateIndex ::= `CREATE' UniqueFlag `INDEX' IfNotExists NameRef `ON' `(' IdxDefList `)'
+//
+// UniqueFlag  ::= `UNIQUE'
+//               |
+//
+func (self *Parser) parseCreateIndex(unique bool) (*ast.CreateIndex, error) {
+	cmd := &ast.CreateIndex{
+		CreatePos: self.peekPos(),
+		Unique:    unique,
+	}
+
+	var err error
+	if _, err = self.match(token.INDEX); err != nil {
+		return nil, err
+	}
+
+	if self.test(token.IF) {
+		if err = self.batchMatch(token.NOT, token.EXISTS); err != nil {
+			return nil, err
+		}
+		cmd.IfNotExists = true
+	}
+
+	if cmd.Name, err = self.parseNameRef(); err != nil {
+		return nil, err
+	}
+
+	if _, err = self.match(token.ON); err != nil {
+		return nil, err
+	}
+	if cmd.Table, err = self.parseName(); err != nil {
+		return nil, err
+	}
+
+	if _, err = self.match(token.LPAREN); err != nil {
+		return nil, err
+	}
+	if cmd.Index, err = self.parseIdxDefList(); err != nil {
+		return nil, err
+	}
+	if _, err = self.match(token.RPAREN); err != nil {
+		return nil, err
+	}
+
+	cmd.CreateEnd = self.peekPos()
+	return cmd, nil
+}
+
+//------------------------------------------------------------------------------
+// Maintenance Actions:
+//------------------------------------------------------------------------------
+//
+// Pragma      ::= `PRAGMA' NameRef
+//               | `PRAGMA' NameRef `=' PragmaValue
+//               | `PRAGMA' NameRef `(' PragmaValue `)'
+//
+// PragmaValue ::= Keyword
+//               | Expr
+//
+func (self *Parser) parsePragma() (*ast.Pragma, error) {
+	cmd := &ast.Pragma{
+		PragmaPos: self.peekPos(),
+	}
+	self.skip() // skip `PRAGMA'
+
+	var err error
+	if cmd.Name, err = self.parseNameRef(); err != nil {
+		return nil, err
+	}
+
+	switch self.peek() {
+	case token.EQ:
+		self.skip()
+		if cmd.Value, err = self.parsePragmaValue(); err != nil {
+			return nil, err
+		}
+
+	case token.LPAREN:
+		self.skip()
+		if cmd.Value, err = self.parsePragmaValue(); err != nil {
+			return nil, err
+		}
+		if _, err = self.match(token.RPAREN); err != nil {
+			return nil, err
+		}
+	}
+	cmd.PragmaEnd = self.peekPos()
+	return cmd, nil
+}
+
+// Keywords such as ON, YES or FULL are plain names in the value of pragma.
+func (self *Parser) parsePragmaValue() (ast.Expr, error) {
+	if self.peek().Kind() != token.TT_KEYWORD {
+		return self.NextExpr()
+	}
+	id := &ast.Identifier{
+		NamePos: self.peekPos(),
+		Name:    self.peekLiteral(),
+	}
+	self.skip()
+	return id, nil
+}
+
+//
+// Analyze ::= `ANALYZE'
+//           | `ANALYZE' NameRef
+//
+func (self *Parser) parseAnalyze() (*ast.Analyze, error) {
+	cmd := &ast.Analyze{
+		AnalyzePos: self.peekPos(),
+	}
+	self.skip() // skip `ANALYZE'
+
+	var err error
+	if self.peek() == token.ID {
+		if cmd.Target, err = self.parseNameRef(); err != nil {
+			return nil, err
+		}
+	}
+	cmd.AnalyzeEnd = self.peekPos()
+	return cmd, nil
+}
+
+//
+// Vacuum ::= `VACUUM'
+//          | `VACUUM' Identifier
+//
+func (self *Parser) parseVacuum() (*ast.Vacuum, error) {
+	cmd := &ast.Vacuum{
+		VacuumPos: self.peekPos(),
+	}
+	self.skip() // skip `VACUUM'
+
+	var err error
+	if self.peek() == token.ID {
+		if cmd.Database, err = self.parseName(); err != nil {
+			return nil, err
+		}
+	}
+	cmd.VacuumEnd = self.peekPos()
+	return cmd, nil
+}
+
+//
+// Reindex ::= `REINDEX'
+//           | `REINDEX' NameRef
+//
+func (self *Parser) parseReindex() (*ast.Reindex, error) {
+	cmd := &ast.Reindex{
+		ReindexPos: self.peekPos(),
+	}
+	self.skip() // skip `REINDEX'
+
+	var err error
+	if self.peek() == token.ID {
+		if cmd.Target, err = self.parseNameRef(); err != nil {
+			return nil, err
+		}
+	}
+	cmd.ReindexEnd = self.peekPos()
+	return cmd, nil
+}
+
+//
+// Truncate ::= `TRUNCATE' Table NameRef
+//
+// Table    ::= `TABLE'
+//            |
+//
+func (self *Parser) parseTruncate() (*ast.Truncate, error) {
+	cmd := &ast.Truncate{
+		TruncatePos: self.peekPos(),
+	}
+	self.skip() // skip `TRUNCATE'
+	self.test(token.TABLE)
+
+	var err error
+	if cmd.Table, err = self.parseNameRef(); err != nil {
+		return nil, err
+	}
+	cmd.TruncateEnd = self.peekPos()
+	return cmd, nil
+}
+
+//------------------------------------------------------------------------------
+// Prepared Statement Actions:
+//------------------------------------------------------------------------------
+//
+// Prepare ::= `PREPARE' Identifier `FROM' StringLiteral
+//           | `PREPARE' Identifier `FROM' Variable
+//
+func (self *Parser) parsePrepare() (*ast.Prepare, error) {
+	cmd := &ast.Prepare{
+		PreparePos: self.peekPos(),
+	}
+	self.skip() // skip `PREPARE'
 
-		var expr ast.Expr
-		if expr, err = self.NextExpr(); err != nil {
-			return false, err
-		}
+	var err error
+	if cmd.Name, err = self.parseName(); err != nil {
+		return nil, err
+	}
+	if _, err = self.match(token.FROM); err != nil {
+		return nil, err
+	}
 
-		if _, err = self.match(token.RPAREN); err != nil {
-			return false, err
+	switch self.peek() {
+	case token.STRING_LITERAL:
+		cmd.From = &ast.Literal{
+			ValuePos: self.peekPos(),
+			Value:    self.peekLiteral(),
+			Kind:     self.peek(),
 		}
+		self.skip()
 
-		cmd.CheckConstraint = append(cmd.CheckConstraint, expr)
-		return true, nil
+	case token.VARIABLE:
+		if cmd.From, err = self.parseVariable(); err != nil {
+			return nil, err
+		}
 
 	default:
-		return false, nil
+		return nil, self.errorf(`Bad prepared statement, unexpected "%s"`, self.peekLiteral())
 	}
+	cmd.PrepareEnd = self.peekPos()
+	return cmd, nil
 }
 
-func travelColumnDefine(idx []ast.IndexDefine, scheme []ast.ColumnDefine,
-	closure func(idx *ast.IndexDefine, def *ast.ColumnDefine)) {
-	for i := 0; i < len(idx); i++ {
-		for j := 0; j < len(scheme); j++ {
-			if idx[i].Name == scheme[j].Name {
-				closure(&idx[i], &scheme[j])
+//
+// Execute      ::= `EXECUTE' Identifier
+//                | `EXECUTE' Identifier `USING' VariableList
+//
+// VariableList ::= VariableList `,' Variable
+//                | Variable
+//
+func (self *Parser) parseExecute() (*ast.Execute, error) {
+	cmd := &ast.Execute{
+		ExecutePos: self.peekPos(),
+	}
+	self.skip() // skip `EXECUTE'
+
+	var err error
+	if cmd.Name, err = self.parseName(); err != nil {
+		return nil, err
+	}
+
+	if self.test(token.USING) {
+		for {
+			var v *ast.Variable
+			if v, err = self.parseVariable(); err != nil {
+				return nil, err
+			}
+			cmd.Using = append(cmd.Using, *v)
+			if !self.test(token.COMMA) {
+				break
 			}
 		}
 	}
+	cmd.ExecuteEnd = self.peekPos()
+	return cmd, nil
 }
 
 //
-// OnConf  ::= `ON' `CONFLICT' Resolve
-//           |
-//
-// Resolve ::= Raise
-//           | `IGNORE'
-//           | `DEFAULT'
-//           | `REPLACE'
-//
-// Raise   ::= `ROLLBACK'
-//           | `ABORT'
-//           | `FAIL'
+// Deallocate ::= `DEALLOCATE' `PREPARE' Identifier
+//              | `DROP' `PREPARE' Identifier
 //
-func (self *Parser) parseOnConf() (token.Token, error) {
-	if !self.test(token.ON) {
-		return token.DEFAULT, nil
+func (self *Parser) parseDeallocate() (*ast.Deallocate, error) {
+	cmd := &ast.Deallocate{
+		DeallocatePos: self.peekPos(),
 	}
+	self.test(token.DEALLOCATE)
 
 	var err error
-	if _, err = self.match(token.CONFLICT); err != nil {
-		return token.ILLEGAL, err
+	if _, err = self.match(token.PREPARE); err != nil {
+		return nil, err
+	}
+	if cmd.Name, err = self.parseName(); err != nil {
+		return nil, err
 	}
+	cmd.DeallocateEnd = self.peekPos()
+	return cmd, nil
+}
 
-	conf := self.peek()
-	switch conf {
-	case token.IGNORE, token.DEFAULT, token.REPLACE, token.ROLLBACK, token.ABORT,
-		token.FAIL:
-		self.skip()
-		return conf, nil
+//
+// SetVariable ::= `SET' VariableDefineList
+//
+// VariableDefineList ::= VariableDefineList `,' Variable `=' Expr
+//                      | Variable `=' Expr
+//
+func (self *Parser) parseSetVariable() (*ast.SetVariable, error) {
+	cmd := &ast.SetVariable{
+		SetPos: self.peekPos(),
+	}
+	self.skip() // skip `SET'
 
-	default:
-		return token.ILLEGAL, self.errorf(`Bad "ON CONFLICT" option`)
+	for {
+		v, err := self.parseVariable()
+		if err != nil {
+			return nil, err
+		}
+		if _, err = self.match(token.EQ); err != nil {
+			return nil, err
+		}
+		def := ast.VariableDefine{Name: *v}
+		if def.Value, err = self.NextExpr(); err != nil {
+			return nil, err
+		}
+		cmd.Set = append(cmd.Set, def)
+		if !self.test(token.COMMA) {
+			break
+		}
 	}
+	cmd.SetEnd = self.peekPos()
+	return cmd, nil
 }
 
 //------------------------------------------------------------------------------
-// Create Index Actions:
+// Trigger Actions:
 //------------------------------------------------------------------------------
 //
-// CreateIndex ::= `CREATE' UniqueFlag `INDEX' IfNotExists NameRef `ON' `(' IdxDefList `)'
+// CreateTrigger ::= `CREATE' Temp `TRIGGER' IfNotExists NameRef TriggerTime
+//                   TriggerEvent `ON' NameRef ForEachRow When TriggerBody
 //
-// UniqueFlag  ::= `UNIQUE'
-//               |
+// TriggerTime   ::= `BEFORE'
+//                 | `AFTER'
+//                 | `INSTEAD' `OF'
+//                 |
 //
-func (self *Parser) parseCreateIndex(unique bool) (*ast.CreateIndex, error) {
-	cmd := &ast.CreateIndex{
+// TriggerEvent  ::= `INSERT'
+//                 | `UPDATE'
+//                 | `UPDATE' `OF' NameList
+//                 | `DELETE'
+//
+// ForEachRow    ::= `FOR' `EACH' `ROW'
+//                 |
+//
+// When          ::= `WHEN' Expr
+//                 |
+//
+// TriggerBody   ::= `BEGIN' TriggerCmdList `END'
+//                 | TriggerCmd
+//
+func (self *Parser) parseCreateTrigger(temp bool) (*ast.CreateTrigger, error) {
+	cmd := &ast.CreateTrigger{
 		CreatePos: self.peekPos(),
-		Unique:    unique,
+		Temp:      temp,
+		Time:      token.BEFORE,
 	}
+	self.skip() // skip `TRIGGER'
 
 	var err error
-	if _, err = self.match(token.INDEX); err != nil {
-		return nil, err
-	}
-
 	if self.test(token.IF) {
 		if err = self.batchMatch(token.NOT, token.EXISTS); err != nil {
 			return nil, err
 		}
 		cmd.IfNotExists = true
 	}
-
 	if cmd.Name, err = self.parseNameRef(); err != nil {
 		return nil, err
 	}
 
+	switch {
+	case self.peek() == token.BEFORE || self.peek() == token.AFTER:
+		cmd.Time = self.peek()
+		self.skip()
+
+	case self.testWord("INSTEAD"):
+		if err = self.matchWord("OF"); err != nil {
+			return nil, err
+		}
+		cmd.Time = token.INSTEAD
+	}
+
+	switch self.peek() {
+	case token.INSERT, token.DELETE:
+		cmd.Event = self.peek()
+		self.skip()
+
+	case token.UPDATE:
+		cmd.Event = self.peek()
+		self.skip()
+		if self.testWord("OF") {
+			if cmd.Column, err = self.parseNameList(); err != nil {
+				return nil, err
+			}
+		}
+
+	default:
+		return nil, self.errorf(`Bad trigger event, unexpected "%s"`, self.peekLiteral())
+	}
+
 	if _, err = self.match(token.ON); err != nil {
 		return nil, err
 	}
-	if cmd.Table, err = self.parseName(); err != nil {
+	if cmd.Table, err = self.parseNameRef(); err != nil {
 		return nil, err
 	}
 
-	if _, err = self.match(token.LPAREN); err != nil {
-		return nil, err
+	if self.testWord("FOR") {
+		if err = self.matchWord("EACH"); err != nil {
+			return nil, err
+		}
+		if err = self.matchWord("ROW"); err != nil {
+			return nil, err
+		}
+		cmd.ForEachRow = true
 	}
-	if cmd.Index, err = self.parseIdxDefList(); err != nil {
-		return nil, err
+
+	self.trig = true
+	defer func() { self.trig = false }()
+
+	if self.test(token.WHEN) {
+		if cmd.When, err = self.NextExpr(); err != nil {
+			return nil, err
+		}
 	}
-	if _, err = self.match(token.RPAREN); err != nil {
+
+	if cmd.Body, err = self.parseTriggerBody(); err != nil {
 		return nil, err
 	}
-
 	cmd.CreateEnd = self.peekPos()
 	return cmd, nil
 }
 
+//
+// TriggerCmdList ::= TriggerCmdList TriggerCmd `;'
+//                  | TriggerCmd `;'
+//
+func (self *Parser) parseTriggerBody() ([]ast.Command, error) {
+	if !self.test(token.BEGIN) {
+		cmd, err := self.parseTriggerCmd()
+		if err != nil {
+			return nil, err
+		}
+		return []ast.Command{cmd}, nil
+	}
+
+	body := make([]ast.Command, 0)
+	for !self.test(token.END) {
+		cmd, err := self.parseTriggerCmd()
+		if err != nil {
+			return nil, err
+		}
+		if _, err = self.match(token.SEMI); err != nil {
+			return nil, err
+		}
+		body = append(body, cmd)
+	}
+	if len(body) == 0 {
+		return nil, self.errorf("Empty trigger body")
+	}
+	return body, nil
+}
+
+//
+// TriggerCmd ::= Select
+//              | Insert
+//              | Update
+//              | Delete
+//
+func (self *Parser) parseTriggerCmd() (ast.Command, error) {
+	switch self.peek() {
+	case token.SELECT:
+		return self.parseSelect()
+
+	case token.INSERT, token.REPLACE:
+		return self.parseInsert()
+
+	case token.UPDATE:
+		return self.parseUpdate()
+
+	case token.DELETE:
+		return self.parseDelete()
+
+	default:
+		return nil, self.errorf(`Bad trigger command, unexpected "%s"`, self.peekLiteral())
+	}
+}
+
+//
+// DropTrigger ::= `DROP' `TRIGGER' IfExists NameRef
+//
+func (self *Parser) parseDropTrigger() (*ast.DropTrigger, error) {
+	cmd := &ast.DropTrigger{
+		DropPos: self.peekPos(),
+	}
+	self.skip() // skip `TRIGGER'
+
+	var err error
+	if self.test(token.IF) {
+		if _, err = self.match(token.EXISTS); err != nil {
+			return nil, err
+		}
+		cmd.IfExists = true
+	}
+
+	if cmd.Name, err = self.parseNameRef(); err != nil {
+		return nil, err
+	}
+	cmd.DropEnd = self.peekPos()
+	return cmd, nil
+}
+
 func (self *Parser) parseIdxDefList() ([]ast.IndexDefine, error) {
 	idx := make([]ast.IndexDefine, 0)
 
@@ -617,9 +2002,12 @@ func (self *Parser) parseIdxDefList() ([]ast.IndexDefine, error) {
 // Insert Actions:
 //------------------------------------------------------------------------------
 //
-// Insert       ::= InsertPrefix `VALUES' `(' ExprList `)'
-//                | InsertPrefix Select
-//                | InsertPrefix `DEFAULT' `VALUES'
+// Insert       ::= InsertPrefix `VALUES' ValuesList Upsert Returning
+//                | InsertPrefix Select Upsert Returning
+//                | InsertPrefix `DEFAULT' `VALUES' Returning
+//
+// ValuesList   ::= ValuesList `,' `(' ExprList `)'
+//                | `(' ExprList `)'
 //
 // InsertPrefix ::= InsCmd `INTO' NameRef InsColList
 //
@@ -634,7 +2022,7 @@ func (self *Parser) parseInsert() (*ast.Insert, error) {
 		InsertPos: self.peekPos(),
 		Op:        token.DEFAULT,
 		Column:    make([]ast.Identifier, 0),
-		Item:      make([]ast.Expr, 0),
+		Rows:      make([][]ast.Expr, 0),
 	}
 
 	var err error
@@ -660,45 +2048,132 @@ func (self *Parser) parseInsert() (*ast.Insert, error) {
 		return nil, err
 	}
 
-	if self.test(token.LPAREN) {
-		if cmd.Column, err = self.parseIdentifierList(); err != nil {
-			return nil, err
-		}
-		if _, err = self.match(token.RPAREN); err != nil {
+	if self.test(token.LPAREN) {
+		if cmd.Column, err = self.parseIdentifierList(); err != nil {
+			return nil, err
+		}
+		if _, err = self.match(token.RPAREN); err != nil {
+			return nil, err
+		}
+	}
+
+	switch self.peek() {
+	case token.SELECT:
+		if cmd.From, err = self.parseSelect(); err != nil {
+			return nil, err
+		}
+
+	case token.VALUES:
+		self.skip()
+		for {
+			if _, err = self.match(token.LPAREN); err != nil {
+				return nil, err
+			}
+			var row []ast.Expr
+			if row, err = self.parseExprList(); err != nil {
+				return nil, err
+			}
+			if _, err = self.match(token.RPAREN); err != nil {
+				return nil, err
+			}
+			cmd.Rows = append(cmd.Rows, row)
+			if !self.test(token.COMMA) {
+				break
+			}
+		}
+
+	case token.DEFAULT:
+		self.skip()
+		if _, err = self.match(token.VALUES); err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, self.errorf(`Insert statement need values, unexpected "%s"`, self.peek().String())
+	}
+
+	if self.peek() == token.ON && !cmd.DefaultValues() {
+		if cmd.Upsert, err = self.parseUpsert(); err != nil {
+			return nil, err
+		}
+	}
+
+	if cmd.Returning, err = self.parseReturning(); err != nil {
+		return nil, err
+	}
+
+	cmd.InsertEnd = self.peekPos()
+	return cmd, nil
+}
+
+//
+// Upsert       ::= `ON' `DUPLICATE' `KEY' `UPDATE' SetList
+//                | `ON' `CONFLICT' ConflictTarget `DO' `UPDATE' `SET' SetList Where
+//                | `ON' `CONFLICT' ConflictTarget `DO' `NOTHING'
+//
+// ConflictTarget ::= `(' IdentifierList `)' Where
+//                  |
+//
+func (self *Parser) parseUpsert() (*ast.Upsert, error) {
+	upsert := &ast.Upsert{
+		UpsertPos: self.peekPos(),
+	}
+
+	var err error
+	if _, err = self.match(token.ON); err != nil {
+		return nil, err
+	}
+
+	switch self.peek() {
+	case token.DUPLICATE:
+		self.skip()
+		upsert.Conflict = token.DUPLICATE
+		if err = self.batchMatch(token.KEY, token.UPDATE); err != nil {
 			return nil, err
 		}
-	}
-
-	switch self.peek() {
-	case token.SELECT:
-		if cmd.From, err = self.parseSelect(); err != nil {
+		if upsert.Set, err = self.parseSetList(); err != nil {
 			return nil, err
 		}
 
-	case token.VALUES:
+	case token.CONFLICT:
 		self.skip()
-		if _, err = self.match(token.LPAREN); err != nil {
-			return nil, err
+		upsert.Conflict = token.CONFLICT
+		if self.test(token.LPAREN) {
+			if upsert.Target, err = self.parseIdentifierList(); err != nil {
+				return nil, err
+			}
+			if _, err = self.match(token.RPAREN); err != nil {
+				return nil, err
+			}
+			if self.test(token.WHERE) {
+				if upsert.TargetWhere, err = self.NextExpr(); err != nil {
+					return nil, err
+				}
+			}
 		}
-		if cmd.Item, err = self.parseExprList(); err != nil {
+		if _, err = self.match(token.DO); err != nil {
 			return nil, err
 		}
-		if _, err = self.match(token.RPAREN); err != nil {
+		if self.test(token.NOTHING) {
+			upsert.DoNothing = true
+			break
+		}
+		if err = self.batchMatch(token.UPDATE, token.SET); err != nil {
 			return nil, err
 		}
-
-	case token.DEFAULT:
-		self.skip()
-		if _, err = self.match(token.VALUES); err != nil {
+		if upsert.Set, err = self.parseSetList(); err != nil {
 			return nil, err
 		}
+		if self.test(token.WHERE) {
+			if upsert.Where, err = self.NextExpr(); err != nil {
+				return nil, err
+			}
+		}
 
 	default:
-		return nil, self.errorf(`Insert statement need values, unexpected "%s"`, self.peek().String())
+		return nil, self.errorf(`Bad upsert clause, unexpected "%s"`, self.peek().String())
 	}
-
-	cmd.InsertEnd = self.peekPos()
-	return cmd, nil
+	return upsert, nil
 }
 
 func (self *Parser) parseOrConf() (token.Token, error) {
@@ -722,11 +2197,14 @@ func (self *Parser) parseOrConf() (token.Token, error) {
 // Update Actions:
 //------------------------------------------------------------------------------
 //
-// Update  ::= `UPDATE' OrConf NameRef Indexed `SET' SetList Where OrderBy Limit
+// Update  ::= `UPDATE' OrConf SelTabList `SET' SetList UpdFrom Where OrderBy Limit Returning
 //
 // SetList ::= SetList `,' SetDefine
 //           | SetDefine
 //
+// UpdFrom ::= `FROM' SelTabList
+//           |
+//
 func (self *Parser) parseUpdate() (*ast.Update, error) {
 	cmd := &ast.Update{
 		UpdatePos: self.peekPos(),
@@ -739,29 +2217,31 @@ func (self *Parser) parseUpdate() (*ast.Update, error) {
 		return nil, err
 	}
 
-	if cmd.Dest, err = self.parseNameRef(); err != nil {
+	var join []ast.Source
+	if join, err = self.parseSelTabList(); err != nil {
 		return nil, err
 	}
-
-	if cmd.Indexed, err = self.parseIndexed(); err != nil {
-		return nil, err
+	first := join[0].Leftmost()
+	if first.Table == nil {
+		return nil, self.errorAt(first.Pos(), "Update target must be a table")
+	}
+	cmd.Dest = *first.Table
+	cmd.Indexed = first.Indexed
+	if len(join) > 1 || join[0].Join != nil || first.Alias != "" {
+		cmd.Join = join
 	}
 
 	if _, err = self.match(token.SET); err != nil {
 		return nil, err
 	}
 
-	var def ast.SetDefine
-	if def, err = self.parseSetDefine(); err != nil {
+	if cmd.Set, err = self.parseSetList(); err != nil {
 		return nil, err
-	} else {
-		cmd.Set = append(cmd.Set, def)
 	}
-	for self.test(token.COMMA) {
-		if def, err = self.parseSetDefine(); err != nil {
+
+	if self.test(token.FROM) {
+		if cmd.From, err = self.parseSelTabList(); err != nil {
 			return nil, err
-		} else {
-			cmd.Set = append(cmd.Set, def)
 		}
 	}
 
@@ -786,20 +2266,49 @@ func (self *Parser) parseUpdate() (*ast.Update, error) {
 		}
 	}
 
+	if cmd.Returning, err = self.parseReturning(); err != nil {
+		return nil, err
+	}
+
 	cmd.UpdateEnd = self.peekPos()
 	return cmd, nil
 }
 
+func (self *Parser) parseSetList() ([]ast.SetDefine, error) {
+	set := make([]ast.SetDefine, 0)
+
+	for {
+		if def, err := self.parseSetDefine(); err != nil {
+			return set, err
+		} else {
+			set = append(set, def)
+		}
+		if !self.test(token.COMMA) {
+			break
+		}
+	}
+	return set, nil
+}
+
 //
 // SetDefine ::= Identifier `=' Expr
+//             | Identifier `.' Identifier `=' Expr
 //
 func (self *Parser) parseSetDefine() (ast.SetDefine, error) {
-	var def ast.SetDefine
+	def := ast.SetDefine{
+		SetPos: self.peekPos(),
+	}
 	var err error
 
 	if def.Column, err = self.parseName(); err != nil {
 		return def, err
 	}
+	if self.test(token.DOT) {
+		def.Table = def.Column
+		if def.Column, err = self.parseName(); err != nil {
+			return def, err
+		}
+	}
 	if _, err = self.match(token.EQ); err != nil {
 		return def, err
 	}
@@ -813,7 +2322,12 @@ func (self *Parser) parseSetDefine() (ast.SetDefine, error) {
 // Delete Actions:
 //------------------------------------------------------------------------------
 //
-// Delete ::= `DELETE' `FROM' NameRef Indexed Where OrderBy Limit
+// Delete     ::= `DELETE' `FROM' NameRef Indexed Where OrderBy Limit Returning
+//              | `DELETE' `FROM' NameRefList `USING' SelTabList Where Returning
+//              | `DELETE' NameRefList `FROM' SelTabList Where Returning
+//
+// NameRefList ::= NameRefList `,' NameRef
+//               | NameRef
 //
 func (self *Parser) parseDelete() (*ast.Delete, error) {
 	cmd := &ast.Delete{
@@ -821,15 +2335,38 @@ func (self *Parser) parseDelete() (*ast.Delete, error) {
 	}
 
 	var err error
-	if err = self.batchMatch(token.DELETE, token.FROM); err != nil {
+	if _, err = self.match(token.DELETE); err != nil {
 		return nil, err
 	}
 
-	if cmd.Dest, err = self.parseNameRef(); err != nil {
-		return nil, err
+	var target []ast.NameRef
+	if self.test(token.FROM) {
+		if target, err = self.parseNameRefList(); err != nil {
+			return nil, err
+		}
+		if self.test(token.USING) {
+			if cmd.Using, err = self.parseSelTabList(); err != nil {
+				return nil, err
+			}
+		} else if len(target) > 1 {
+			return nil, self.errorf(`Multi-table delete need "USING", unexpected "%s"`,
+				self.peek().String())
+		}
+	} else {
+		if target, err = self.parseNameRefList(); err != nil {
+			return nil, err
+		}
+		if _, err = self.match(token.FROM); err != nil {
+			return nil, err
+		}
+		if cmd.Join, err = self.parseSelTabList(); err != nil {
+			return nil, err
+		}
 	}
-
-	if cmd.Indexed, err = self.parseIndexed(); err != nil {
+	cmd.Dest = target[0]
+	if cmd.Join != nil || cmd.Using != nil {
+		cmd.Target = target
+	} else if cmd.Indexed, err = self.parseIndexed(); err != nil {
 		return nil, err
 	}
 
@@ -854,6 +2391,10 @@ func (self *Parser) parseDelete() (*ast.Delete, error) {
 		}
 	}
 
+	if cmd.Returning, err = self.parseReturning(); err != nil {
+		return nil, err
+	}
+
 	cmd.DeleteEnd = self.peekPos()
 	return cmd, nil
 }
@@ -862,7 +2403,10 @@ func (self *Parser) parseDelete() (*ast.Delete, error) {
 // Select Statement Actions:
 //------------------------------------------------------------------------------
 //
-// Select       ::= Select SetOp SingleSelect
+// Select       ::= SetExpr OrderBy Limit
+//
+// SetExpr      ::= SetExpr SetOp SetExpr
+//                | `(' Select `)'
 //                | SingleSelect
 //
 // SetOp        ::= `UNION'
@@ -870,17 +2414,140 @@ func (self *Parser) parseDelete() (*ast.Delete, error) {
 //                | `EXCEPT'
 //                | `INTERSECT'
 //
-// SingleSelect ::= `SELECT' Distinct SelColList From Where GroupBy Having OrderBy Limit
+// INTERSECT binds tighter than UNION and EXCEPT, ORDER BY and LIMIT after the
+// last operand of compound belong to the result of compound.
+//
+func (self *Parser) parseSelect() (ast.Query, error) {
+	query, err := self.parseSetExpr(0)
+	if err != nil {
+		return nil, err
+	}
+	return self.parseSelectRest(query)
+}
+
+// Rest of select after the first operand lhs: SetOp SetExpr ... OrderBy Limit
+func (self *Parser) parseSelectRest(lhs ast.Query) (ast.Query, error) {
+	query, err := self.parseSetOps(lhs, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var orderBy []ast.OrderByItem
+	if self.test(token.ORDER) {
+		if _, err = self.match(token.BY); err != nil {
+			return nil, err
+		}
+		if orderBy, err = self.parseOrderBy(); err != nil {
+			return nil, err
+		}
+	}
+
+	var limit, offset ast.Expr
+	if self.test(token.LIMIT) {
+		if limit, offset, err = self.parseLimitOffset(); err != nil {
+			return nil, err
+		}
+	}
+
+	switch cmd := query.(type) {
+	case *ast.Select:
+		if (orderBy != nil && cmd.OrderBy != nil) || (limit != nil && cmd.Limit != nil) {
+			return nil, self.errorAt(cmd.Pos(), "Duplicated ORDER BY or LIMIT in select")
+		}
+		if orderBy != nil {
+			cmd.OrderBy = orderBy
+		}
+		if limit != nil {
+			cmd.Limit, cmd.Offset = limit, offset
+		}
+		if orderBy != nil || limit != nil {
+			cmd.SelectEnd = self.peekPos()
+		}
+
+	case *ast.Compound:
+		cmd.OrderBy = orderBy
+		cmd.Limit, cmd.Offset = limit, offset
+		cmd.CompoundEnd = self.peekPos()
+	}
+
+	if op, _ := self.peekSetOp(); op != 0 {
+		return nil, self.errorf("ORDER BY and LIMIT should come after the last compound select")
+	}
+	return query, nil
+}
+
+func (self *Parser) parseSetExpr(limit int) (ast.Query, error) {
+	var lhs ast.Query
+	var err error
+
+	if self.test(token.LPAREN) {
+		if lhs, err = self.parseSelect(); err != nil {
+			return nil, err
+		}
+		if _, err = self.match(token.RPAREN); err != nil {
+			return nil, err
+		}
+	} else if lhs, err = self.parseSingleSelect(); err != nil {
+		return nil, err
+	}
+	return self.parseSetOps(lhs, limit)
+}
+
+// Operators binding tighter than limit after lhs.
+func (self *Parser) parseSetOps(lhs ast.Query, limit int) (ast.Query, error) {
+	var err error
+	for {
+		op, prio := self.peekSetOp()
+		if op == 0 || prio <= limit {
+			break
+		}
+		self.skip()
+		if op == token.UNION && self.test(token.ALL) {
+			op = token.UNION_ALL
+		}
+
+		compound := &ast.Compound{
+			Op:   op,
+			Left: lhs,
+		}
+		if compound.Right, err = self.parseSetExpr(prio); err != nil {
+			return nil, err
+		}
+		compound.CompoundEnd = self.peekPos()
+		lhs = compound
+	}
+	return lhs, nil
+}
+
+func (self *Parser) peekSetOp() (token.Token, int) {
+	switch self.peek() {
+	case token.UNION, token.EXCEPT:
+		return self.peek(), 1
+
+	case token.INTERSECT:
+		return self.peek(), 2
+
+	default:
+		return 0, 0
+	}
+}
+
+//
+// SingleSelect ::= `SELECT' Distinct SelColList From Where GroupBy Having
 //
 // Distinct     ::= `DISTINCT'
 //                | `ALL'
 //                |
 //
-func (self *Parser) parseSelect() (*ast.Select, error) {
+func (self *Parser) parseSingleSelect() (*ast.Select, error) {
 	cmd := &ast.Select{
 		SelectPos: self.peekPos(),
 	}
-	self.skip()
+
+	var err error
+	if _, err = self.match(token.SELECT); err != nil {
+		return nil, err
+	}
 
 	if self.peek() == token.DISTINCT {
 		self.skip()
@@ -890,7 +2557,6 @@ func (self *Parser) parseSelect() (*ast.Select, error) {
 		cmd.Distinct = false
 	}
 
-	var err error
 	if cmd.SelColList, err = self.parseSelColList(); err != nil {
 		return nil, err
 	}
@@ -922,46 +2588,8 @@ func (self *Parser) parseSelect() (*ast.Select, error) {
 		}
 	}
 
-	if self.test(token.ORDER) {
-		if _, err = self.match(token.BY); err != nil {
-			return nil, err
-		}
-		if cmd.OrderBy, err = self.parseOrderBy(); err != nil {
-			return nil, err
-		}
-	}
-
-	if self.test(token.LIMIT) {
-		if cmd.Limit, cmd.Offset, err = self.parseLimitOffset(); err != nil {
-			return nil, err
-		}
-	}
-
 	// End of select statement
 	cmd.SelectEnd = self.peekPos()
-
-	switch self.peek() {
-	case token.UNION:
-		self.skip()
-		if self.test(token.ALL) {
-			cmd.Op = token.UNION_ALL
-		} else {
-			cmd.Op = token.UNION
-		}
-
-	case token.EXCEPT:
-		self.skip()
-		cmd.Op = token.EXCEPT
-
-	case token.INTERSECT:
-		self.skip()
-		cmd.Op = token.INTERSECT
-	}
-	if cmd.Op != 0 {
-		if cmd.Prior, err = self.parseSelect(); err != nil {
-			return nil, err
-		}
-	}
 	return cmd, nil
 }
 
@@ -1009,6 +2637,17 @@ func (self *Parser) parseSelColList() ([]ast.SelectColumn, error) {
 	return column, nil
 }
 
+//
+// Returning ::= `RETURNING' SelColList
+//             |
+//
+func (self *Parser) parseReturning() ([]ast.SelectColumn, error) {
+	if !self.test(token.RETURNING) {
+		return nil, nil
+	}
+	return self.parseSelColList()
+}
+
 //
 // AliasName ::= `AS' Identifer
 //
@@ -1028,93 +2667,152 @@ func (self *Parser) parseName() (string, error) {
 }
 
 //
-// SelTabList ::= SelTabList JoinOp Source
-//              | Source
+// SelTabList     ::= SelTabList `,' TableRef
+//                  | TableRef
 //
-// JoinOP     ::= `,'
-//              | `LEFT' `OUTER' `JOIN'
-//              | `LEFT' `JOIN'
-//              | `RIGHT' `OUTER' `JOIN'
-//              | `RIGHT' `JOIN'
-//              | `FULL' `OUTER' `JOIN'
-//              | `JOIN'
-//              | `INNER' `JOIN'
-//              | `CROSS' `JOIN'
-//              | `NATURAL' `JOIN'
+// TableRef       ::= TableRef JoinOp TableFactor JoinConstraint
+//                  | TableFactor
 //
-// Source     ::= Name Alias Indexed On Using
-//              | `(' Select `)'' Alias On Using
+// TableFactor    ::= NameRef Alias Indexed
+//                  | `(' Select `)' Alias
+//                  | `(' TableRef `)'
+//
+// JoinConstraint ::= `ON' Expr
+//                  | `USING' `(' IdentifierList `)'
+//                  |
 //
 func (self *Parser) parseSelTabList() ([]ast.Source, error) {
 	source := make([]ast.Source, 0)
 
 	for {
-		var err error
-		var elem ast.Source
+		elem, err := self.parseTableRef()
+		if err != nil {
+			return source, err
+		}
+		source = append(source, elem)
 
-		elem.SourcePos = self.peekPos()
-		if self.test(token.LPAREN) {
-			if elem.Subquery, err = self.parseSelect(); err != nil {
-				return source, err
-			}
+		if !self.test(token.COMMA) {
+			break
+		}
+	}
+	return source, nil
+}
 
-			if _, err = self.match(token.RPAREN); err != nil {
-				return source, err
-			}
-		} else {
-			var name ast.NameRef
-			if name, err = self.parseNameRef(); err != nil {
-				return source, nil
-			}
-			elem.Table = &name
+func (self *Parser) parseTableRef() (ast.Source, error) {
+	left, err := self.parseTableFactor()
+	if err != nil {
+		return left, err
+	}
+
+	for {
+		join := &ast.Join{Left: left}
+		if join.Type, err = self.parseJoinType(); err != nil {
+			return left, err
 		}
-		if self.test(token.AS) || self.peek() == token.ID {
-			if elem.Alias, err = self.parseName(); err != nil {
-				return source, err
-			}
+		if join.Type == 0 {
+			break
 		}
 
-		if elem.Table != nil {
-			if elem.Indexed, err = self.parseIndexed(); err != nil {
-				return source, err
-			}
+		if join.Right, err = self.parseTableFactor(); err != nil {
+			return left, err
+		}
+		if err = self.parseJoinConstraint(join); err != nil {
+			return left, err
 		}
+		left = ast.Source{
+			SourcePos: left.SourcePos,
+			SourceEnd: self.peekPos(),
+			Join:      join,
+		}
+	}
+	return left, nil
+}
 
-		if self.test(token.USING) {
-			if _, err = self.match(token.LPAREN); err != nil {
-				return source, err
-			}
-			if elem.Using, err = self.parseIdentifierList(); err != nil {
-				return source, err
+func (self *Parser) parseTableFactor() (ast.Source, error) {
+	var err error
+	var elem ast.Source
+
+	elem.SourcePos = self.peekPos()
+	if self.test(token.LPAREN) {
+		if self.peek() == token.SELECT {
+			elem.Subquery, err = self.parseSelect()
+		} else {
+			var inner ast.Source
+			if inner, err = self.parseTableRef(); err != nil {
+				return elem, err
 			}
-			if _, err = self.match(token.RPAREN); err != nil {
-				return source, err
+			if inner.Subquery == nil || inner.Alias != "" || self.peek() == token.RPAREN {
+				if _, err = self.match(token.RPAREN); err != nil {
+					return elem, err
+				}
+				if inner.Join == nil {
+					return inner, nil // `(' Name `)'
+				}
+				elem.Join = inner.Join
+				elem.SourceEnd = self.peekPos()
+				return elem, nil
 			}
+			// `(' `(' Select `)' SetOp ...: the first operand is parsed as table
+			elem.Subquery, err = self.parseSelectRest(inner.Subquery)
+		}
+		if err != nil {
+			return elem, err
+		}
+		if _, err = self.match(token.RPAREN); err != nil {
+			return elem, err
+		}
+	} else {
+		var name ast.NameRef
+		if name, err = self.parseNameRef(); err != nil {
+			return elem, err
+		}
+		elem.Table = &name
+	}
+	if self.test(token.AS) || (self.peek() == token.ID && !self.isFullJoin()) {
+		if elem.Alias, err = self.parseName(); err != nil {
+			return elem, err
+		}
+	}
+
+	if elem.Table != nil {
+		if elem.Indexed, err = self.parseIndexed(); err != nil {
+			return elem, err
 		}
+	}
+	elem.SourceEnd = self.peekPos()
+	return elem, nil
+}
 
-		elem.SourceEnd = self.peekPos()
-		if elem.JoinType, err = self.parseJoinType(); err != nil {
-			return source, err
+func (self *Parser) parseJoinConstraint(join *ast.Join) error {
+	var err error
+
+	switch {
+	case self.peek() == token.ON && !isUpsert(self.peekNext()):
+		self.skip() // skip `ON'
+		if join.On, err = self.NextExpr(); err != nil {
+			return err
 		}
 
-		if self.test(token.ON) {
-			if _, err = self.match(token.LPAREN); err != nil {
-				return source, err
-			}
-			if elem.On, err = self.NextExpr(); err != nil {
-				return source, err
-			}
-			if _, err = self.match(token.RPAREN); err != nil {
-				return source, err
-			}
+	case self.peek() == token.USING:
+		self.skip()
+		if _, err = self.match(token.LPAREN); err != nil {
+			return err
 		}
-		source = append(source, elem)
-
-		if elem.JoinType == 0 {
-			break
+		if join.Using, err = self.parseIdentifierList(); err != nil {
+			return err
 		}
+		if _, err = self.match(token.RPAREN); err != nil {
+			return err
+		}
+
+	default:
+		return nil
 	}
-	return source, nil
+
+	if join.Type&ast.JT_NATURAL != 0 {
+		return self.errorAt(join.Right.End(), "NATURAL join can not have ON or USING")
+	}
+	return nil
 }
 
 func (self *Parser) parseIndexed() (string, error) {
@@ -1140,48 +2838,102 @@ func (self *Parser) parseIndexed() (string, error) {
 	return indexed, nil
 }
 
+//
+// JoinOp ::= JoinType `JOIN'
+//          | `STRAIGHT_JOIN'
+//
+// JoinType ::= JoinType `INNER'
+//            | JoinType `CROSS'
+//            | JoinType `NATURAL'
+//            | JoinType `LEFT'
+//            | JoinType `RIGHT'
+//            | JoinType `FULL'
+//            | JoinType `OUTER'
+//            |
+//
 func (self *Parser) parseJoinType() (int, error) {
-	if self.test(token.COMMA) {
-		return ast.JT_INNER, nil
-	}
-
+	pos := self.peekPos()
 	jt := 0
 	for {
+		var bit int
 		switch self.peek() {
 		case token.INNER:
-			self.skip()
-			jt |= ast.JT_INNER
+			bit = ast.JT_INNER
 
 		case token.CROSS:
-			self.skip()
-			jt |= ast.JT_CROSS
+			bit = ast.JT_CROSS
 
 		case token.NATURAL:
-			self.skip()
-			jt |= ast.JT_NATURAL
+			bit = ast.JT_NATURAL
 
 		case token.LEFT:
-			self.skip()
-			jt |= ast.JT_LEFT
+			bit = ast.JT_LEFT
 
 		case token.RIGHT:
-			self.skip()
-			jt |= ast.JT_RIGHT
+			bit = ast.JT_RIGHT
 
 		case token.OUTER:
+			bit = ast.JT_OUTER
+
+		case token.STRAIGHT_JOIN:
+			if jt != 0 {
+				return 0, self.errorAt(pos, "Illegal join type")
+			}
 			self.skip()
-			jt |= ast.JT_OUTER
+			return ast.JT_STRAIGHT, nil
 
 		case token.JOIN:
+			self.skip()
 			if jt == 0 {
-				jt = ast.JT_INNER
+				return ast.JT_INNER, nil
+			}
+			if !isLegalJoinType(jt) {
+				return 0, self.errorAt(pos, "Illegal join type")
 			}
-			self.skip()
 			return jt, nil
 
 		default:
+			if self.isFullJoin() {
+				bit = ast.JT_FULL
+				break
+			}
+			if jt != 0 {
+				return 0, self.errorf(`Bad join type, unexpected "%s"`, self.peekLiteral())
+			}
 			return 0, nil
 		}
+
+		if jt&bit != 0 {
+			return 0, self.errorAt(pos, "Illegal join type")
+		}
+		jt |= bit
+		self.skip()
+	}
+}
+
+// FULL is not a reserved word, it is a join type only if JOIN or OUTER follows:
+// a FULL JOIN b, a FULL OUTER JOIN b.
+func (self *Parser) isFullJoin() bool {
+	return self.peekWord("FULL") && (self.peekNext() == token.JOIN || self.peekNext() == token.OUTER)
+}
+
+func isLegalJoinType(jt int) bool {
+	outer := jt & (ast.JT_LEFT | ast.JT_RIGHT | ast.JT_FULL)
+	switch {
+	case outer&(outer-1) != 0:
+		return false // LEFT RIGHT, LEFT FULL ...
+
+	case jt&ast.JT_OUTER != 0 && outer == 0:
+		return false // OUTER without LEFT, RIGHT or FULL
+
+	case jt&(ast.JT_INNER|ast.JT_CROSS) != 0 && outer != 0:
+		return false // INNER LEFT, CROSS RIGHT ...
+
+	case jt&ast.JT_INNER != 0 && jt&ast.JT_CROSS != 0:
+		return false
+
+	default:
+		return true
 	}
 }
 
@@ -1357,11 +3109,78 @@ func (self *Parser) parseSimple() (ast.Expr, error) {
 	case token.CAST:
 		return self.parseCast()
 
+	case token.INTERVAL:
+		return self.parseInterval()
+
+	case token.CURRENT_DATE, token.CURRENT_TIME, token.CURRENT_TIMESTAMP:
+		return self.parseCurrent()
+
+	case token.DATE, token.TIME, token.TIMESTAMP, token.YEAR:
+		return self.parseTemporal()
+
+	case token.VALUES:
+		return self.parseValuesFunc()
+
+	case token.REPLACE:
+		return self.parseReplaceFunc()
+
+	case token.VARIABLE:
+		return self.parseVariable()
+
 	default:
 		return self.parseSuffixed()
 	}
 }
 
+//
+// ReplaceFunc ::= `REPLACE' `(' ExprList `)'
+//
+// REPLACE is a reserved word of command, and also a string function.
+func (self *Parser) parseReplaceFunc() (*ast.CallExpr, error) {
+	call := &ast.CallExpr{
+		Func: ast.Identifier{
+			NamePos: self.peekPos(),
+			Name:    strings.ToUpper(self.peekLiteral()),
+		},
+	}
+	self.skip()
+
+	var err error
+	if call.Args, err = self.parseCallArgs(); err != nil {
+		return nil, err
+	}
+	return call, nil
+}
+
+//
+// ValuesFunc ::= `VALUES' `(' Identifier `)'
+//
+// For MySQL: ON DUPLICATE KEY UPDATE c = VALUES(c)
+func (self *Parser) parseValuesFunc() (*ast.CallExpr, error) {
+	call := &ast.CallExpr{
+		Func: ast.Identifier{
+			NamePos: self.peekPos(),
+			Name:    self.peekLiteral(),
+		},
+		Args: make([]ast.Expr, 0),
+	}
+	self.skip() // skip `VALUES'
+
+	var err error
+	if _, err = self.match(token.LPAREN); err != nil {
+		return nil, err
+	}
+	var expr ast.Expr
+	if expr, err = self.parsePrimary(); err != nil {
+		return nil, err
+	}
+	call.Args = append(call.Args, expr)
+	if _, err = self.match(token.RPAREN); err != nil {
+		return nil, err
+	}
+	return call, nil
+}
+
 func (self *Parser) parseCondition() (ast.Expr, error) {
 	cond := &ast.Condition{
 		OpPos:  self.peekPos(),
@@ -1435,12 +3254,146 @@ func (self *Parser) parseCast() (*ast.CastExpr, error) {
 	return cast, nil
 }
 
+//
+// IntervalExpr ::= `INTERVAL' Expr Unit
+//
+// Unit         ::= `YEAR'
+//                | Identifier
+//
+// Units are like MySQL: DAY, HOUR_MINUTE, ...
+func (self *Parser) parseInterval() (*ast.IntervalExpr, error) {
+	expr := &ast.IntervalExpr{
+		OpPos: self.peekPos(),
+	}
+	self.skip() // skip `INTERVAL'
+
+	var err error
+	if expr.Value, err = self.NextExpr(); err != nil {
+		return nil, err
+	}
+	if self.peek() != token.YEAR &&
+		(self.peek() != token.ID || !datetime.IsUnit(self.peekLiteral())) {
+		return nil, self.errorf(`Unknown INTERVAL unit "%s"`, self.peekLiteral())
+	}
+	expr.UnitPos = self.peekPos()
+	expr.Unit = strings.ToUpper(self.peekLiteral())
+	self.skip()
+	return expr, nil
+}
+
+//
+// CurrentExpr ::= `CURRENT_DATE' OptArgs
+//               | `CURRENT_TIME' OptArgs
+//               | `CURRENT_TIMESTAMP' OptArgs
+//
+// OptArgs     ::= `(' ExprList `)'
+//               | `(' `)'
+//               |
+//
+// They are calls of functions in the same names.
+func (self *Parser) parseCurrent() (*ast.CallExpr, error) {
+	call := &ast.CallExpr{
+		Func: ast.Identifier{
+			NamePos: self.peekPos(),
+			Name:    strings.ToUpper(self.peekLiteral()),
+		},
+		Args: make([]ast.Expr, 0),
+	}
+	self.skip()
+
+	if self.peek() == token.LPAREN {
+		var err error
+		if call.Args, err = self.parseCallArgs(); err != nil {
+			return nil, err
+		}
+	}
+	return call, nil
+}
+
+//
+// TemporalExpr ::= `DATE' StringLiteral
+//                | `TIME' StringLiteral
+//                | `TIMESTAMP' StringLiteral
+//                | `DATE' `(' ExprList `)'
+//                | `TIME' `(' ExprList `)'
+//                | `TIMESTAMP' `(' ExprList `)'
+//                | `YEAR' `(' ExprList `)'
+//
+// Typed literals: DATE '2020-01-02', and keywords of types are also names of
+// functions: YEAR(d).
+func (self *Parser) parseTemporal() (ast.Expr, error) {
+	kind := self.peek()
+	id := ast.Identifier{
+		NamePos: self.peekPos(),
+		Name:    strings.ToUpper(self.peekLiteral()),
+	}
+	self.skip()
+
+	switch {
+	case self.peek() == token.STRING_LITERAL && kind != token.YEAR:
+		lit := &ast.Literal{
+			ValuePos: self.peekPos(),
+			Value:    self.peekLiteral(),
+			Kind:     kind,
+		}
+		self.skip()
+		return lit, nil
+
+	case self.peek() == token.LPAREN:
+		args, err := self.parseCallArgs()
+		if err != nil {
+			return nil, err
+		}
+		return &ast.CallExpr{Func: id, Args: args}, nil
+
+	default:
+		return nil, self.errorf(`Unexpected expression, expected "%s"`, self.peekLiteral())
+	}
+}
+
+// `(' ExprList `)' or `(' `)'
+func (self *Parser) parseCallArgs() ([]ast.Expr, error) {
+	var err error
+	if _, err = self.match(token.LPAREN); err != nil {
+		return nil, err
+	}
+	args := make([]ast.Expr, 0)
+	if self.peek() != token.RPAREN {
+		if args, err = self.parseExprList(); err != nil {
+			return nil, err
+		}
+	}
+	if _, err = self.match(token.RPAREN); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
+
+// Type names which are not reserved words, they are identifiers elsewhere.
+var typeWords = map[string]token.Token{
+	"ENUM":               token.ENUM,
+	"JSON":               token.JSON,
+	"GEOMETRY":           token.GEOMETRY,
+	"POINT":              token.POINT,
+	"LINESTRING":         token.LINESTRING,
+	"POLYGON":            token.POLYGON,
+	"MULTIPOINT":         token.MULTIPOINT,
+	"MULTILINESTRING":    token.MULTILINESTRING,
+	"MULTIPOLYGON":       token.MULTIPOLYGON,
+	"GEOMETRYCOLLECTION": token.GEOMETRYCOLLECTION,
+}
+
 //
 // TypeDecl ::= Type Sign
 //            | Type `(' IntLiteral `)' Sign
 //            | Type `(' IntLiteral `,' IntLiteral `)' Sign
+//            | `ENUM' `(' StringList `)' Sign
+//            | `SET' `(' StringList `)' Sign
 //
-// Sign     ::= `UNSIGNED'
+// Sign     ::= Sign `UNSIGNED'
+//            | Sign `ZEROFILL'
+//            | Sign `CHARACTER' `SET' Identifier
+//            | Sign `CHARSET' Identifier
 //            |
 //
 // Type     ::= `TINYINT'
@@ -1448,18 +3401,35 @@ func (self *Parser) parseCast() (*ast.CastExpr, error) {
 //            | `INT'
 //            | ...
 func (self *Parser) parseType() (*ast.Type, error) {
-	if self.peek().Kind() != token.TT_KEYWORD {
+	kind := self.peek()
+	if kind == token.ID {
+		var found bool
+		if kind, found = typeWords[strings.ToUpper(self.peekLiteral())]; !found {
+			return nil, self.errorf(`"%s" not type!`, self.peekLiteral())
+		}
+	} else if kind.Kind() != token.TT_KEYWORD {
 		return nil, self.errorf(`"%s" not type!`, self.peek().String())
 	}
 
 	decl := &ast.Type{
 		TokenPos: self.peekPos(),
-		Kind:     self.peek(),
+		Kind:     kind,
 		Unsigned: false,
 	}
 	self.skip()
 
-	if self.peek() == token.LPAREN {
+	if decl.Kind == token.ENUM || decl.Kind == token.SET {
+		var err error
+		if _, err = self.match(token.LPAREN); err != nil {
+			return nil, err
+		}
+		if decl.Values, err = self.parseStringList(); err != nil {
+			return nil, err
+		}
+		if _, err = self.match(token.RPAREN); err != nil {
+			return nil, err
+		}
+	} else if self.peek() == token.LPAREN {
 		self.skip()
 
 		var err error
@@ -1479,12 +3449,49 @@ func (self *Parser) parseType() (*ast.Type, error) {
 		}
 	}
 
-	if self.peek() == token.UNSIGNED {
-		self.skip()
+	for {
+		var err error
+
+		switch {
+		case self.test(token.UNSIGNED):
+			decl.Unsigned = true
+
+		case self.test(token.ZEROFILL):
+			decl.Zerofill = true
+
+		case self.test(token.CHARACTER):
+			if _, err = self.match(token.SET); err != nil {
+				return nil, err
+			}
+			if decl.Charset, err = self.parseName(); err != nil {
+				return nil, err
+			}
+
+		case self.testWord("CHARSET"):
+			if decl.Charset, err = self.parseName(); err != nil {
+				return nil, err
+			}
+
+		default:
+			return decl, nil
+		}
+	}
+}
+
+func (self *Parser) parseStringList() ([]string, error) {
+	list := make([]string, 0)
 
-		decl.Unsigned = true
+	for {
+		if lah, err := self.match(token.STRING_LITERAL); err != nil {
+			return list, err
+		} else {
+			list = append(list, unquote(lah.Literal))
+		}
+		if !self.test(token.COMMA) {
+			break
+		}
 	}
-	return decl, nil
+	return list, nil
 }
 
 func (self *Parser) parseIntLiteral() (*ast.Literal, error) {
@@ -1563,6 +3570,9 @@ func (self *Parser) parsePrimary() (ast.Expr, error) {
 		}
 
 	case token.ID:
+		if self.trig && isPseudoRow(self.peekLiteral()) && self.peekNext() == token.DOT {
+			return self.parsePseudoColumn()
+		}
 		if expr, err = self.parseIdentifier(); err != nil {
 			return nil, err
 		}
@@ -1586,6 +3596,41 @@ func (self *Parser) parsePrimary() (ast.Expr, error) {
 	}
 }
 
+func isPseudoRow(name string) bool {
+	return strings.EqualFold(name, "NEW") || strings.EqualFold(name, "OLD")
+}
+
+//
+// PseudoColumn ::= `NEW' `.' Identifier
+//                | `OLD' `.' Identifier
+//
+func (self *Parser) parsePseudoColumn() (*ast.PseudoColumn, error) {
+	expr := &ast.PseudoColumn{
+		NamePos: self.peekPos(),
+		Row:     strings.ToUpper(self.peekLiteral()),
+	}
+	self.skip() // skip `NEW' or `OLD'
+	self.skip() // skip `.'
+
+	id, err := self.parseIdentifier()
+	if err != nil {
+		return nil, err
+	}
+	expr.Column = *id
+	return expr, nil
+}
+
+func (self *Parser) parseVariable() (*ast.Variable, error) {
+	tok, err := self.match(token.VARIABLE)
+	if err != nil {
+		return nil, err
+	}
+	return &ast.Variable{
+		NamePos: tok.Pos,
+		Name:    strings.TrimPrefix(tok.Literal, "@"),
+	}, nil
+}
+
 func (self *Parser) parseExprList() ([]ast.Expr, error) {
 	list := make([]ast.Expr, 0)
 	expr, err := self.NextExpr()
@@ -1659,6 +3704,38 @@ func (self *Parser) parseIdentifierList() ([]ast.Identifier, error) {
 	return id, nil
 }
 
+func (self *Parser) parseNameList() ([]string, error) {
+	name := make([]string, 0)
+
+	for {
+		if elem, err := self.parseName(); err != nil {
+			return name, err
+		} else {
+			name = append(name, elem)
+		}
+		if !self.test(token.COMMA) {
+			break
+		}
+	}
+	return name, nil
+}
+
+func (self *Parser) parseNameRefList() ([]ast.NameRef, error) {
+	list := make([]ast.NameRef, 0)
+
+	for {
+		if name, err := self.parseNameRef(); err != nil {
+			return list, err
+		} else {
+			list = append(list, name)
+		}
+		if !self.test(token.COMMA) {
+			break
+		}
+	}
+	return list, nil
+}
+
 func (self *Parser) parseNameRef() (ast.NameRef, error) {
 	var name ast.NameRef
 	if lah, err := self.match(token.ID); err != nil {
@@ -1690,6 +3767,11 @@ func (self *Parser) errorf(s string, a ...interface{}) error {
 	}
 }
 
+// Error of the source at pos, not at the next token.
+func (self *Parser) errorAt(pos int, s string, a ...interface{}) error {
+	return fmt.Errorf(`[%d] %s`, pos, fmt.Sprintf(s, a...))
+}
+
 func (self *Parser) peek() token.Token {
 	return self.lah.Token
 }
@@ -1711,8 +3793,35 @@ func (self *Parser) test(exp token.Token) bool {
 	}
 }
 
+func (self *Parser) peekNext() token.Token {
+	if !self.ahead {
+		self.lah2.Pos, self.lah2.Token, self.lah2.Literal = self.lex.Next()
+		self.ahead = true
+	}
+	return self.lah2.Token
+}
+
+// Next token is the non-reserved word, it is not skipped.
+func (self *Parser) peekWord(word string) bool {
+	return self.peek() == token.ID && strings.EqualFold(self.peekLiteral(), word)
+}
+
+func (self *Parser) testWord(word string) bool {
+	if self.peek() == token.ID && strings.EqualFold(self.peekLiteral(), word) {
+		self.skip()
+		return true
+	} else {
+		return false
+	}
+}
+
 func (self *Parser) skip() {
-	self.lah.Pos, self.lah.Token, self.lah.Literal = self.lex.Next()
+	if self.ahead {
+		self.lah = self.lah2
+		self.ahead = false
+	} else {
+		self.lah.Pos, self.lah.Token, self.lah.Literal = self.lex.Next()
+	}
 }
 
 func (self *Parser) batchMatch(list ...token.Token) error {
@@ -1734,6 +3843,13 @@ func (self *Parser) match(exp token.Token) (tokeniton, error) {
 	return prev, nil
 }
 
+func (self *Parser) matchWord(word string) error {
+	if !self.testWord(word) {
+		return self.errorf(`Unexpected "%s", expected "%s"`, self.peekLiteral(), word)
+	}
+	return nil
+}
+
 func priority(op token.Token) priorition {
 	prio, found := prio[op]
 	if !found {
@@ -1742,6 +3858,19 @@ func priority(op token.Token) priorition {
 	return prio
 }
 
+func unquote(lit string) string {
+	if len(lit) >= 2 && (lit[0] == '\'' || lit[0] == '"') && lit[len(lit)-1] == lit[0] {
+		return lit[1 : len(lit)-1]
+	}
+	return lit
+}
+
+// `ON' `CONFLICT' or `ON' `DUPLICATE' follows INSERT ... SELECT, it is not a
+// join constraint.
+func isUpsert(next token.Token) bool {
+	return next == token.CONFLICT || next == token.DUPLICATE
+}
+
 func isDot(expr ast.Expr) bool {
 	bin, ok := expr.(*ast.BinaryExpr)
 	if !ok {
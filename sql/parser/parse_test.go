@@ -28,6 +28,138 @@ func TestSanity(t *testing.T) {
 	}
 }
 
+func TestShow(t *testing.T) {
+	assertCmd(t, "SHOW DATABASES", "show_databases")
+	assertCmd(t, "SHOW FULL TABLES FROM db LIKE 'x%'", "show_tables_like")
+	assertCmd(t, "SHOW COLUMNS FROM t FROM db WHERE Field = 'id'", "show_columns")
+	assertCmd(t, "SHOW INDEX FROM db.t", "show_index")
+	assertCmd(t, "SHOW CREATE TABLE db.t", "show_create_table")
+	assertCmd(t, "DESCRIBE db.t", "describe")
+	assertCmd(t, "SHOW FULL FIELDS IN t", "show_full_fields")
+	assertCmd(t, "SHOW KEYS FROM t", "show_keys")
+	assertCmd(t, "SELECT keys, columns, fields, indexes, full FROM t full WHERE full.keys > 0",
+		"select_show_words")
+
+	for _, sql := range []string{
+		"SHOW COLUMNS FROM db.t FROM x",
+		"SHOW INDEX IN db.t IN x",
+		"SHOW FULL KEYS FROM t",
+	} {
+		if _, err := ParseCommand(sql); err == nil {
+			t.Fatal("Should be fail:", sql)
+		}
+	}
+}
+
+func TestExplain(t *testing.T) {
+	assertCmd(t, "EXPLAIN SELECT * FROM t", "explain_sanity")
+	assertCmd(t, "EXPLAIN QUERY PLAN SELECT * FROM t", "explain_query_plan")
+	assertCmd(t, "EXPLAIN ANALYZE FORMAT = JSON DELETE FROM t", "explain_analyze_json")
+	assertCmd(t, "EXPLAIN (ANALYZE, FORMAT YAML) UPDATE t SET a = 1", "explain_options")
+	assertCmd(t, "DESCRIBE SELECT * FROM t", "describe_select")
+	assertCmd(t, "EXPLAIN (SELECT a FROM t) UNION (SELECT a FROM u)", "explain_compound")
+	assertCmd(t, "EXPLAIN (analyze, format yaml) SELECT analyze, yaml FROM t", "explain_words")
+
+	for _, sql := range []string{
+		"EXPLAIN (FORMAT XML) SELECT * FROM t",
+		"EXPLAIN (ANALYZE SELECT * FROM t",
+		"EXPLAIN SHOW TABLES",
+		"EXPLAIN CREATE TABLE t (a INT)",
+	} {
+		if _, err := ParseCommand(sql); err == nil {
+			t.Fatal("Should be fail:", sql)
+		}
+	}
+}
+
+func TestTransaction(t *testing.T) {
+	cmd, err := ParseCommand("ROLLBACK TO SAVEPOINT sp1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rv := cmd.(*ast.Transaction); rv.Op != token.ROLLBACK || rv.Savepoint != "sp1" {
+		t.Fatal("Bad rollback to savepoint")
+	}
+
+	cmd, err = ParseCommand("START TRANSACTION ISOLATION LEVEL REPEATABLE READ, READ ONLY")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rv := cmd.(*ast.Transaction); rv.Isolation != ast.ISOLATION_REPEATABLE_READ || !rv.ReadOnly {
+		t.Fatal("Bad transaction options")
+	}
+
+	assertCmd(t, "SAVEPOINT sp1", "savepoint")
+	assertCmd(t, "RELEASE SAVEPOINT sp1", "release_savepoint")
+	assertCmd(t, "BEGIN IMMEDIATE TRANSACTION ISOLATION LEVEL SERIALIZABLE", "begin_isolation_level")
+
+	if _, err = ParseCommand("COMMIT foo"); err == nil {
+		t.Fatal("Trailing token should be rejected")
+	}
+	t.Log(err)
+}
+
+func TestDatabase(t *testing.T) {
+	assertCmd(t, "CREATE DATABASE IF NOT EXISTS db DEFAULT CHARACTER SET utf8mb4 COLLATE utf8mb4_bin", "create_database")
+	assertCmd(t, "DROP DATABASE IF EXISTS db", "drop_database")
+	assertCmd(t, "USE db", "use")
+	assertCmd(t, "ATTACH DATABASE 'file.db' AS aux", "attach")
+	assertCmd(t, "DETACH aux", "detach")
+	assertCmd(t, "SELECT database FROM t WHERE database.id > 0", "select_database_word")
+	assertCmd(t, "DETACH database", "detach_database_word")
+}
+
+func TestTrigger(t *testing.T) {
+	assertCmd(t, `CREATE TRIGGER IF NOT EXISTS audit AFTER UPDATE OF a, b ON t FOR EACH ROW
+		WHEN NEW.a <> OLD.a
+		BEGIN
+			INSERT INTO log VALUES (OLD.a, NEW.a);
+			UPDATE cnt SET n = n + 1 WHERE id = new.id;
+		END`, "create_trigger")
+	assertCmd(t, "CREATE TEMP TRIGGER v INSTEAD OF DELETE ON db.v DELETE FROM t WHERE id = OLD.id", "create_trigger_instead_of")
+	assertCmd(t, "DROP TRIGGER IF EXISTS db.audit", "drop_trigger")
+	assertCmd(t, "SELECT row, each, of, for, instead FROM t", "select_trigger_words")
+
+	_, err := ParseCommand("CREATE TRIGGER tr AFTER INSERT ON t BEGIN END")
+	if err == nil {
+		t.Fatal("Empty trigger body should be fail")
+	}
+	_, err = ParseCommand("CREATE TRIGGER tr AFTER INSERT ON t BEGIN DROP TABLE t; END")
+	if err == nil {
+		t.Fatal("DDL in trigger body should be fail")
+	}
+	_, err = ParseCommand("CREATE TRIGGER tr AFTER INSERT ON t FOR EACH x DELETE FROM u")
+	if err == nil || err.Error() != `[45] Unexpected "x", expected "ROW"` {
+		t.Fatal(err)
+	}
+}
+
+func TestMaintenance(t *testing.T) {
+	assertCmd(t, "PRAGMA main.journal_mode = WAL", "pragma")
+	assertCmd(t, "PRAGMA foreign_keys = ON", "pragma_keyword")
+	assertCmd(t, "PRAGMA table_info(t)", "pragma_call")
+	assertCmd(t, "PRAGMA cache_size = -2000", "pragma_negative")
+	assertCmd(t, "ANALYZE db.t", "analyze")
+	assertCmd(t, "VACUUM", "vacuum")
+	assertCmd(t, "REINDEX t_idx", "reindex")
+	assertCmd(t, "TRUNCATE TABLE db.t", "truncate")
+}
+
+func TestPrepare(t *testing.T) {
+	assertCmd(t, "PREPARE stmt FROM 'SELECT * FROM t WHERE a = ? AND b = ?'", "prepare")
+	assertCmd(t, "PREPARE stmt FROM @sql", "prepare_variable")
+	assertCmd(t, "EXECUTE stmt USING @a, @b", "execute")
+	assertCmd(t, "DEALLOCATE PREPARE stmt", "deallocate")
+	assertCmd(t, "DROP PREPARE stmt", "drop_prepare")
+	assertCmd(t, "SET @a = 1, @b = @a + 1", "set_variable")
+	assertCmd(t, "SELECT @a, b FROM t WHERE c = @c", "select_variable")
+
+	_, err := ParseCommand("EXECUTE stmt USING 1")
+	if err == nil {
+		t.Fatal("EXECUTE USING should only accept user variables")
+	}
+}
+
 func TestDotIdExpr(t *testing.T) {
 	assertExpr(t, "db.name", "dot_id")
 	assertExpr(t, "`db`.`name`", "quoted_dot_id")
@@ -62,6 +194,11 @@ func TestFuncCall(t *testing.T) {
 	assertExpr(t, "POW(1, 2)", "call_func_pow")
 	assertExpr(t, "SUM(DISTINCT amt)", "call_func_sum")
 	assertExpr(t, "COUNT(*)", "call_func_count")
+	assertExpr(t, "replace(name, 'a', 'b')", "call_func_replace")
+
+	if _, err := ParseExpression("REPLACE + 1"); err == nil {
+		t.Fatal("Should be fail: REPLACE + 1")
+	}
 }
 
 func TestIsOrNotNull(t *testing.T) {
@@ -109,6 +246,22 @@ func TestCast(t *testing.T) {
 	assertExpr(t, `CAST ("hello" AS VARCHAR(8))`, "cast_varchar")
 }
 
+func TestDateTime(t *testing.T) {
+	assertExpr(t, "d + INTERVAL 1 DAY", "interval_day")
+	assertExpr(t, "DATE_SUB(NOW(), INTERVAL '1:30' hour_minute) < CURRENT_TIMESTAMP", "interval_call")
+	assertExpr(t, "DATE '2020-01-02' - INTERVAL 1 + 1 YEAR", "interval_year")
+	assertExpr(t, "TIMESTAMP '2020-01-02 03:04:05' = CURRENT_TIMESTAMP(6)", "timestamp_literal")
+	assertExpr(t, "YEAR(CURRENT_DATE) + MONTH(DATE(d))", "year_call")
+
+	for _, bad := range []string{"INTERVAL 1 DAYS", "INTERVAL 1", "YEAR '2020'", "TIME + 1"} {
+		if _, err := ParseExpression(bad); err == nil {
+			t.Fatal(bad)
+		} else {
+			t.Log(err)
+		}
+	}
+}
+
 func TestSelectSanity(t *testing.T) {
 	assertCmd(t, "SELECT * FROM t;", "select_sanity")
 	assertCmd(t, "SELECT DISTINCT * FROM t;", "select_distinct")
@@ -138,7 +291,7 @@ func TestSelectIndexedBy(t *testing.T) {
 }
 
 func TestSelectUsing(t *testing.T) {
-	assertCmd(t, "SELECT * FROM t USING (a, b)", "using")
+	assertCmd(t, "SELECT * FROM s JOIN t USING (a, b)", "using")
 }
 
 func TestSelectJoin(t *testing.T) {
@@ -148,6 +301,34 @@ func TestSelectJoin(t *testing.T) {
 	assertCmd(t, "SELECT * FROM t1 LEFT OUTER JOIN t2 ON (t1.a = t2.a)", "left_outer_join_with_on")
 }
 
+func TestSelectJoinTree(t *testing.T) {
+	assertCmd(t, "SELECT * FROM a FULL OUTER JOIN b ON a.id = b.id", "full_outer_join")
+	assertCmd(t, "SELECT * FROM a full FULL JOIN b ON full.id = b.id", "full_join_alias")
+	assertCmd(t, "SELECT * FROM a STRAIGHT_JOIN b ON a.id = b.id", "straight_join")
+	assertCmd(t, "SELECT * FROM a NATURAL LEFT JOIN b RIGHT JOIN c USING (id)", "join_left_assoc")
+	assertCmd(t, "SELECT * FROM (a JOIN b ON a.x = b.x) LEFT JOIN (c JOIN d ON c.y = d.y) ON a.z = c.z",
+		"join_parenthesised")
+	assertCmd(t, "SELECT * FROM a, b JOIN c ON b.id = c.id", "comma_and_join")
+
+	for _, sql := range []string{
+		"SELECT * FROM a LEFT RIGHT JOIN b",
+		"SELECT * FROM a OUTER JOIN b",
+		"SELECT * FROM a INNER LEFT JOIN b",
+		"SELECT * FROM a CROSS INNER JOIN b",
+		"SELECT * FROM a LEFT LEFT JOIN b",
+		"SELECT * FROM a LEFT STRAIGHT_JOIN b",
+		"SELECT * FROM a LEFT b",
+		"SELECT * FROM a NATURAL JOIN b ON a.id = b.id",
+		"SELECT * FROM (a JOIN b",
+	} {
+		if _, err := ParseCommand(sql); err == nil {
+			t.Fatal("Should be fail:", sql)
+		} else {
+			t.Log(err)
+		}
+	}
+}
+
 func TestSelectGroupBy(t *testing.T) {
 	assertCmd(t, "SELECT * FROM t GROUP BY t.a, t.b, 1 + t.c, func(t.d)", "group_by")
 }
@@ -176,6 +357,31 @@ func TestSelectUnion(t *testing.T) {
 	assertCmd(t, "SELECT * FROM t INTERSECT SELECT * FROM u", "select_intersect")
 }
 
+func TestSelectCompound(t *testing.T) {
+	assertCmd(t, "SELECT a FROM t UNION SELECT a FROM u INTERSECT SELECT a FROM v", "select_intersect_priority")
+	assertCmd(t, "(SELECT a FROM t LIMIT 5) UNION ALL (SELECT a FROM u LIMIT 5) ORDER BY 1 LIMIT 3",
+		"select_compound_paren")
+	assertCmd(t, "SELECT a FROM t EXCEPT SELECT a FROM u ORDER BY a DESC", "select_compound_order_by")
+	assertCmd(t, "SELECT * FROM (SELECT a FROM t UNION SELECT a FROM u) x", "select_compound_subquery")
+	assertCmd(t, "SELECT * FROM ((SELECT a FROM t) UNION (SELECT a FROM u) ORDER BY a) AS x",
+		"select_compound_paren_subquery")
+	assertCmd(t, "SELECT * FROM ((SELECT a FROM t) x JOIN u)", "select_join_paren_subquery")
+
+	for _, sql := range []string{
+		"SELECT a FROM t ORDER BY a UNION SELECT a FROM u",
+		"SELECT a FROM t LIMIT 1 UNION SELECT a FROM u",
+		"(SELECT a FROM t LIMIT 1) LIMIT 2",
+		"(SELECT a FROM t UNION SELECT a FROM u",
+		"SELECT * FROM ((SELECT a FROM t) UNION SELECT a FROM u",
+	} {
+		if _, err := ParseCommand(sql); err == nil {
+			t.Fatal("Should be fail:", sql)
+		} else {
+			t.Log(err)
+		}
+	}
+}
+
 func TestCreateTableSanity(t *testing.T) {
 	assertCmd(t, "CREATE TABLE db.t (id INT, name VARCHAR(16))", "create_table_sanity")
 	assertCmd(t, "CREATE TEMP TABLE db.t (id SMALLINT)", "create_table_temp")
@@ -213,10 +419,52 @@ func TestCreateTableAsSelect(t *testing.T) {
 
 func TestCreateTablePostfix(t *testing.T) {
 	assertCmd(t, "CREATE TABLE t (id INT, PRIMARY KEY (id DESC AUTOINCR))", "create_table_post_primary_key")
+	assertCmd(t, "CREATE TABLE t (id INT AUTO_INCREMENT, name TEXT COLLATE nocase, PRIMARY KEY (id, name))",
+		"create_table_post_primary_key_attrs")
 	assertCmd(t, "CREATE TABLE t (id INT, name VARCHAR(16), UNIQUE (id, name) ON CONFLICT IGNORE)", "create_table_post_unique")
 	assertCmd(t, "CREATE TABLE t (id INT, name VARCHAR(16), CHECK (id <> name))", "create_table_post_check")
 }
 
+func TestCreateTableForeignKey(t *testing.T) {
+	assertCmd(t, "CREATE TABLE t (id INT, uid INT, FOREIGN KEY (uid) REFERENCES db.u (id) ON DELETE CASCADE ON UPDATE SET NULL DEFERRABLE INITIALLY DEFERRED)", "create_table_foreign_key")
+	assertCmd(t, "CREATE TABLE t (id INT, uid INT REFERENCES u (id) ON DELETE NO ACTION NOT NULL)", "create_table_references")
+	assertCmd(t, "CREATE TABLE t (action INT, no INT, cascade INT, restrict INT)", "create_table_action_words")
+	assertCmd(t, "SELECT action, no FROM t WHERE cascade = restrict", "select_action_words")
+
+	for _, sql := range []string{
+		"CREATE TABLE t (uid INT REFERENCES u ON DELETE NO)",
+		"CREATE TABLE t (uid INT REFERENCES u ON UPDATE action)",
+		"CREATE TABLE t (uid INT REFERENCES u DEFERRABLE INITIALLY foo)",
+		"CREATE TABLE t (uid INT REFERENCES u NOT DEFERRABLE INITIALLY)",
+		"CREATE TABLE t (a INT, b INT, FOREIGN KEY (a, b) REFERENCES u (x))",
+		"CREATE TABLE t (uid INT REFERENCES u (x, y))",
+		"CREATE TABLE t (uid INT REFERENCES u ON DELETE CASCADE ON DELETE RESTRICT)",
+		"CREATE TABLE t (uid INT REFERENCES u ON UPDATE CASCADE ON DELETE CASCADE ON UPDATE NO ACTION)",
+	} {
+		if _, err := ParseCommand(sql); err == nil {
+			t.Fatal("Should be fail:", sql)
+		}
+	}
+}
+
+func TestCreateTableConstraint(t *testing.T) {
+	assertCmd(t, "CREATE TABLE t (id INT, name VARCHAR(16), CONSTRAINT pk PRIMARY KEY (id), CONSTRAINT fk FOREIGN KEY (name) REFERENCES u (name) ON UPDATE RESTRICT)", "create_table_constraint")
+	assertCmd(t, "CREATE TABLE t (id INT CONSTRAINT nn NOT NULL CONSTRAINT fk REFERENCES u NOT DEFERRABLE)", "create_table_column_constraint")
+}
+
+func TestCreateTableMySQL(t *testing.T) {
+	assertCmd(t, "CREATE TABLE `user_info` (`id` INT(11) UNSIGNED ZEROFILL NOT NULL AUTO_INCREMENT COMMENT 'key', "+
+		"`name` VARCHAR(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin DEFAULT NULL, "+
+		"`updated` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP, "+
+		"PRIMARY KEY (`id`)) ENGINE=InnoDB AUTO_INCREMENT=100 DEFAULT CHARSET=utf8mb4 COMMENT='users'", "create_table_mysql")
+	assertCmd(t, "CREATE TABLE t (id INT) ENGINE = MyISAM, CHARACTER SET = latin1, COLLATE latin1_bin, ROW_FORMAT=DYNAMIC", "create_table_options")
+}
+
+func TestCreateTableMoreTypes(t *testing.T) {
+	assertCmd(t, "CREATE TABLE t (k ENUM('a', 'b'), s SET('x', 'y', 'z') CHARACTER SET utf8, doc JSON, pos POINT, area GEOMETRY NOT NULL)", "create_table_more_types")
+	assertCmd(t, "SELECT json, point, enum FROM t WHERE geometry IS NULL", "select_type_words")
+}
+
 func TestCreateIndex(t *testing.T) {
 	assertCmd(t, "CREATE INDEX db.idx ON t (id DESC, name ASC)", "create_index_sanity_0")
 	assertCmd(t, "CREATE UNIQUE INDEX IF NOT EXISTS db.idx ON t (id, name)", "create_index_sanity_1")
@@ -239,6 +487,22 @@ func TestInsertSource(t *testing.T) {
 	assertCmd(t, "INSERT INTO db.t (id, name) DEFAULT VALUES", "insert_collist_default")
 }
 
+func TestInsertMultiRows(t *testing.T) {
+	assertCmd(t, "INSERT INTO t VALUES (1, 2), (3, 4)", "insert_multi_rows")
+}
+
+func TestInsertUpsert(t *testing.T) {
+	assertCmd(t, "INSERT INTO t (id, n) VALUES (1, 2) ON DUPLICATE KEY UPDATE n = n + VALUES(n)", "insert_on_duplicate_key")
+	assertCmd(t, "INSERT INTO t (id, n) VALUES (1, 2) ON CONFLICT (id) DO UPDATE SET n = 2 WHERE n < 2", "insert_on_conflict_update")
+	assertCmd(t, "INSERT INTO t SELECT * FROM u ON CONFLICT DO NOTHING", "insert_on_conflict_nothing")
+}
+
+func TestReturning(t *testing.T) {
+	assertCmd(t, "INSERT INTO t VALUES (1, 2) RETURNING id, n AS num", "insert_returning")
+	assertCmd(t, "UPDATE t SET n = 1 WHERE id = 1 RETURNING *", "update_returning")
+	assertCmd(t, "DELETE FROM t WHERE id = 1 RETURNING id", "delete_returning")
+}
+
 func TestUpdateSanity(t *testing.T) {
 	assertCmd(t, "UPDATE db.t SET id = 1, name = 'john'", "update_sanity")
 	assertCmd(t, "UPDATE db.t SET name = 'john' WHERE id = 1", "update_sanity_where")
@@ -259,10 +523,25 @@ func TestUpdateLimit(t *testing.T) {
 	assertCmd(t, "UPDATE db.t SET name = 'john' WHERE id = 1 LIMIT 2 OFFSET 1", "update_limit_offset")
 }
 
+func TestUpdateMultiTable(t *testing.T) {
+	assertCmd(t, "UPDATE a JOIN b ON (a.id = b.id) SET a.x = b.y WHERE b.z > 0", "update_join")
+	assertCmd(t, "UPDATE a SET x = b.y FROM b WHERE a.id = b.id", "update_from")
+
+	_, err := ParseCommand("UPDATE (SELECT * FROM a) x JOIN b SET x.y = 1")
+	if err == nil || err.Error() != "[7] Update target must be a table" {
+		t.Fatal(err)
+	}
+}
+
 func TestDeleteSanity(t *testing.T) {
 	assertCmd(t, "DELETE FROM db.t INDEXED BY a WHERE id = 1 ORDER BY b LIMIT 1", "delete_sanity")
 }
 
+func TestDeleteMultiTable(t *testing.T) {
+	assertCmd(t, "DELETE t1 FROM t1 JOIN t2 ON (t1.id = t2.id) WHERE t2.x IS NULL", "delete_join")
+	assertCmd(t, "DELETE FROM t1, t2 USING t1 JOIN t2 USING (id)", "delete_using")
+}
+
 const (
 	kTestingPrefix = "testing/"
 )
@@ -0,0 +1,278 @@
+package pattern
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type Mode int
+
+const (
+	MODE_LIKE   Mode = iota // `%' and `_', with optional escape character
+	MODE_GLOB               // `*', `?' and `[...]', always case-sensitive
+	MODE_REGEXP             // Matches if any part of string matches
+)
+
+var modeNames = []string{
+	"LIKE",
+	"GLOB",
+	"REGEXP",
+}
+
+func (self Mode) String() string {
+	return modeNames[self]
+}
+
+// Compiled pattern, it is safe for concurrent use.
+type Pattern struct {
+	Mode   Mode
+	Source string
+	Fold   bool // Case-insensitive
+
+	elems  []elem
+	re     *regexp.Regexp
+	prefix string
+	exact  bool
+}
+
+type elemKind int
+
+const (
+	elemRune  elemKind = iota
+	elemOne            // `_' or `?'
+	elemAny            // `%' or `*'
+	elemClass          // `[...]'
+)
+
+type elem struct {
+	kind   elemKind
+	r      rune
+	ranges []rune // Pairs of [lo, hi] for class
+	negate bool
+}
+
+// Compile LIKE pattern, escape is 0 if there is no escape character.
+func CompileLike(pattern string, escape rune, fold bool) (*Pattern, error) {
+	self := &Pattern{Mode: MODE_LIKE, Source: pattern, Fold: fold}
+
+	rs := []rune(pattern)
+	for i := 0; i < len(rs); i++ {
+		switch c := rs[i]; {
+		case c == escape && escape != 0:
+			if i++; i >= len(rs) {
+				return nil, fmt.Errorf("Bad LIKE pattern '%s': escape character at end", pattern)
+			}
+			self.elems = append(self.elems, elem{kind: elemRune, r: rs[i]})
+		case c == '%':
+			self.appendAny()
+		case c == '_':
+			self.elems = append(self.elems, elem{kind: elemOne})
+		default:
+			self.elems = append(self.elems, elem{kind: elemRune, r: c})
+		}
+	}
+	self.initPrefix()
+	return self, nil
+}
+
+// Compile GLOB pattern: `[abc]', `[a-z]' and `[^abc]' match one character in
+// or not in the set, `]' is in the set if it is the first one.
+func CompileGlob(pattern string) (*Pattern, error) {
+	self := &Pattern{Mode: MODE_GLOB, Source: pattern}
+
+	rs := []rune(pattern)
+	for i := 0; i < len(rs); i++ {
+		switch rs[i] {
+		case '*':
+			self.appendAny()
+		case '?':
+			self.elems = append(self.elems, elem{kind: elemOne})
+		case '[':
+			class, next, err := parseClass(rs, i+1)
+			if err != nil {
+				return nil, fmt.Errorf("Bad GLOB pattern '%s': %v", pattern, err)
+			}
+			self.elems = append(self.elems, class)
+			i = next
+		default:
+			self.elems = append(self.elems, elem{kind: elemRune, r: rs[i]})
+		}
+	}
+	self.initPrefix()
+	return self, nil
+}
+
+// Compile regular expression in syntax of Go regexp package.
+func CompileRegexp(pattern string, fold bool) (*Pattern, error) {
+	self := &Pattern{Mode: MODE_REGEXP, Source: pattern, Fold: fold}
+
+	flags := syntax.Perl
+	if fold {
+		flags |= syntax.FoldCase
+	}
+	re, err := syntax.Parse(pattern, flags)
+	if err != nil {
+		return nil, fmt.Errorf("Bad REGEXP pattern '%s': %v", pattern, err)
+	}
+	if self.re, err = regexp.Compile(re.String()); err != nil {
+		return nil, fmt.Errorf("Bad REGEXP pattern '%s': %v", pattern, err)
+	}
+	self.prefix, self.exact = regexpPrefix(re.Simplify())
+	return self, nil
+}
+
+// Consecutive `%' are the same as one.
+func (self *Pattern) appendAny() {
+	if n := len(self.elems); n == 0 || self.elems[n-1].kind != elemAny {
+		self.elems = append(self.elems, elem{kind: elemAny})
+	}
+}
+
+// Class starts after `[', returns index of `]'.
+func parseClass(rs []rune, i int) (elem, int, error) {
+	class := elem{kind: elemClass}
+	if i < len(rs) && rs[i] == '^' {
+		class.negate = true
+		i++
+	}
+	for first := true; i < len(rs); i, first = i+1, false {
+		if rs[i] == ']' && !first {
+			return class, i, nil
+		}
+		lo, hi := rs[i], rs[i]
+		if i+2 < len(rs) && rs[i+1] == '-' && rs[i+2] != ']' {
+			hi = rs[i+2]
+			i += 2
+		}
+		if lo > hi {
+			return class, i, fmt.Errorf("bad range %c-%c", lo, hi)
+		}
+		class.ranges = append(class.ranges, lo, hi)
+	}
+	return class, i, fmt.Errorf("missing `]'")
+}
+
+func (self *Pattern) initPrefix() {
+	var buf strings.Builder
+	for _, e := range self.elems {
+		if e.kind != elemRune {
+			self.prefix = buf.String()
+			return
+		}
+		buf.WriteRune(e.r)
+	}
+	self.prefix, self.exact = buf.String(), true
+}
+
+// Only `^literal...' has prefix, because regular expression can match any part
+// of string.
+func regexpPrefix(re *syntax.Regexp) (string, bool) {
+	subs := []*syntax.Regexp{re}
+	if re.Op == syntax.OpConcat {
+		subs = re.Sub
+	}
+	if len(subs) < 2 || subs[0].Op != syntax.OpBeginText || subs[1].Op != syntax.OpLiteral ||
+		subs[1].Flags&syntax.FoldCase != 0 {
+		return "", false
+	}
+	exact := len(subs) == 3 && subs[2].Op == syntax.OpEndText
+	return string(subs[1].Rune), exact
+}
+
+//------------------------------------------------------------------------------
+// Matching
+//------------------------------------------------------------------------------
+func (self *Pattern) Match(s string) bool {
+	if self.re != nil {
+		return self.re.MatchString(s)
+	}
+
+	// Backtrack to the last `%' only, because `%' before it can not match more
+	// if the rest can not be matched.
+	p, star, mark := 0, -1, 0
+	for i := 0; i < len(s); {
+		r, n := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case p < len(self.elems) && self.elems[p].kind == elemAny:
+			star, mark = p, i
+			p++
+		case p < len(self.elems) && self.matchOne(&self.elems[p], r):
+			i += n
+			p++
+		case star >= 0:
+			_, n = utf8.DecodeRuneInString(s[mark:])
+			mark += n
+			i, p = mark, star+1
+		default:
+			return false
+		}
+	}
+	for p < len(self.elems) && self.elems[p].kind == elemAny {
+		p++
+	}
+	return p == len(self.elems)
+}
+
+func (self *Pattern) matchOne(e *elem, r rune) bool {
+	switch e.kind {
+	case elemOne:
+		return true
+	case elemRune:
+		return e.r == r || (self.Fold && equalFold(e.r, r))
+	case elemClass:
+		for i := 0; i < len(e.ranges); i += 2 {
+			if r >= e.ranges[i] && r <= e.ranges[i+1] {
+				return !e.negate
+			}
+		}
+		return e.negate
+	default:
+		return false
+	}
+}
+
+func equalFold(a, b rune) bool {
+	for r := unicode.SimpleFold(a); r != a; r = unicode.SimpleFold(r) {
+		if r == b {
+			return true
+		}
+	}
+	return false
+}
+
+//------------------------------------------------------------------------------
+// Prefix
+//------------------------------------------------------------------------------
+
+// Literal prefix of all matched strings, exact is true if the pattern matches
+// the prefix only.
+func (self *Pattern) Prefix() (prefix string, exact bool) {
+	return self.prefix, self.exact
+}
+
+// Range of matched strings in binary order: [from, to), to is empty if there is
+// no upper bound. ok is false if the range is unlimited or the pattern is
+// case-insensitive. e.g. name LIKE 'abc%' can be scanned in index as
+// name >= 'abc' AND name < 'abd'.
+func (self *Pattern) Range() (from, to string, ok bool) {
+	if self.prefix == "" || self.Fold {
+		return "", "", false
+	}
+	return self.prefix, successor(self.prefix), true
+}
+
+// The least string greater than all strings have the prefix.
+func successor(prefix string) string {
+	b := []byte(prefix)
+	for i := len(b) - 1; i >= 0; i-- {
+		if b[i] < 0xff {
+			b[i]++
+			return string(b[:i+1])
+		}
+	}
+	return ""
+}
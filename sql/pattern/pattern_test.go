@@ -0,0 +1,152 @@
+package pattern
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLike(t *testing.T) {
+	pat := mustLike(t, "h%o", 0, false)
+	assertMatch(t, pat, "hello", true)
+	assertMatch(t, pat, "ho", true)
+	assertMatch(t, pat, "hell", false)
+	assertMatch(t, pat, "Hello", false)
+
+	pat = mustLike(t, "h_llo%", 0, true)
+	assertMatch(t, pat, "HELLO world", true)
+	assertMatch(t, pat, "hllo", false)
+
+	pat = mustLike(t, "%a%b%", 0, false)
+	assertMatch(t, pat, "xxaxxbxx", true)
+	assertMatch(t, pat, "ab", true)
+	assertMatch(t, pat, "ba", false)
+
+	assertMatch(t, mustLike(t, "%", 0, false), "", true)
+	assertMatch(t, mustLike(t, "_", 0, false), "", false)
+	assertMatch(t, mustLike(t, "_", 0, false), "中", true)
+	assertMatch(t, mustLike(t, "", 0, false), "", true)
+	assertMatch(t, mustLike(t, "%%%x", 0, false), "aax", true)
+	assertMatch(t, mustLike(t, "straße", 0, true), "STRAßE", true)
+}
+
+func TestLikeEscape(t *testing.T) {
+	pat := mustLike(t, `100\%`, '\\', false)
+	assertMatch(t, pat, "100%", true)
+	assertMatch(t, pat, "1000", false)
+
+	pat = mustLike(t, "a!_b!!%", '!', false)
+	assertMatch(t, pat, "a_b!c", true)
+	assertMatch(t, pat, "axb!c", false)
+
+	_, err := CompileLike(`abc\`, '\\', false)
+	if err == nil || !strings.Contains(err.Error(), "escape character at end") {
+		t.Fatal(err)
+	}
+}
+
+func TestGlob(t *testing.T) {
+	pat := mustGlob(t, "*.go")
+	assertMatch(t, pat, "main.go", true)
+	assertMatch(t, pat, "main.GO", false)
+
+	pat = mustGlob(t, "[a-c]?[^0-9]*")
+	assertMatch(t, pat, "bxy", true)
+	assertMatch(t, pat, "dxy", false)
+	assertMatch(t, pat, "bx1", false)
+
+	pat = mustGlob(t, "[]x]%")
+	assertMatch(t, pat, "]%", true)
+	assertMatch(t, pat, "x%", true)
+	assertMatch(t, pat, "xa", false)
+
+	for _, bad := range []string{"[abc", "[z-a]"} {
+		if _, err := CompileGlob(bad); err == nil {
+			t.Fatal(bad)
+		}
+	}
+}
+
+func TestRegexp(t *testing.T) {
+	pat, err := CompileRegexp("b+c", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertMatch(t, pat, "abbcd", true)
+	assertMatch(t, pat, "ABBCD", false)
+
+	if pat, err = CompileRegexp("b+c", true); err != nil {
+		t.Fatal(err)
+	}
+	assertMatch(t, pat, "ABBCD", true)
+
+	if _, err = CompileRegexp("(a", false); err == nil {
+		t.Fatal("fail")
+	}
+}
+
+func TestPrefix(t *testing.T) {
+	assertPrefix(t, mustLike(t, "abc%", 0, false), "abc", false)
+	assertPrefix(t, mustLike(t, "abc", 0, false), "abc", true)
+	assertPrefix(t, mustLike(t, `a\%b_`, '\\', false), "a%b", false)
+	assertPrefix(t, mustLike(t, `a\%b`, '\\', false), "a%b", true)
+	assertPrefix(t, mustLike(t, "%abc", 0, false), "", false)
+	assertPrefix(t, mustGlob(t, "ab[c]"), "ab", false)
+
+	for input, prefix := range map[string]string{"^abc": "abc", "^abc.*x": "abc", "abc": "",
+		"^a|b": "", "^(?i)abc": ""} {
+		pat, err := CompileRegexp(input, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertPrefix(t, pat, prefix, false)
+	}
+	pat, _ := CompileRegexp("^abc$", false)
+	assertPrefix(t, pat, "abc", true)
+}
+
+func TestRange(t *testing.T) {
+	from, to, ok := mustLike(t, "abc%", 0, false).Range()
+	if !ok || from != "abc" || to != "abd" {
+		t.Fatal(from, to, ok)
+	}
+	for prefix, expected := range map[string]string{"a\xff": "b", "ab\xff\xff": "ac",
+		"\xff": ""} {
+		if to := successor(prefix); to != expected {
+			t.Fatalf("%q: unexpected %q", prefix, to)
+		}
+	}
+	if _, _, ok = mustLike(t, "abc%", 0, true).Range(); ok {
+		t.Fatal("fail")
+	}
+	if _, _, ok = mustLike(t, "%abc", 0, false).Range(); ok {
+		t.Fatal("fail")
+	}
+}
+
+func assertMatch(t *testing.T, pat *Pattern, s string, expected bool) {
+	if pat.Match(s) != expected {
+		t.Fatalf("%s %s '%s': expected %v", s, pat.Mode, pat.Source, expected)
+	}
+}
+
+func assertPrefix(t *testing.T, pat *Pattern, prefix string, exact bool) {
+	if p, e := pat.Prefix(); p != prefix || e != exact {
+		t.Fatalf("%s '%s': unexpected prefix %q %v", pat.Mode, pat.Source, p, e)
+	}
+}
+
+func mustLike(t *testing.T, pattern string, escape rune, fold bool) *Pattern {
+	pat, err := CompileLike(pattern, escape, fold)
+	if err != nil {
+		t.Fatal(pattern, err)
+	}
+	return pat
+}
+
+func mustGlob(t *testing.T, pattern string) *Pattern {
+	pat, err := CompileGlob(pattern)
+	if err != nil {
+		t.Fatal(pattern, err)
+	}
+	return pat
+}
@@ -0,0 +1,629 @@
+package simplify
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/emptyland/akino/sql/ast"
+	"github.com/emptyland/akino/sql/token"
+)
+
+// Like MySQL div_precision_increment: scale of quotient is scale of dividend
+// plus 4.
+const DIV_PRECISION_INCREMENT = 4
+
+// Simplify returns an equivalent expression: constants are folded, boolean
+// logic is normalized and dead CASE branches are removed. The input expression
+// is not changed, unchanged sub-expressions are shared with the result.
+func Simplify(expr ast.Expr) ast.Expr {
+	return simplify(expr, false)
+}
+
+// SimplifyCond is like Simplify, but for conditions of WHERE, ON, HAVING and
+// WHEN, where only TRUE matters, NULL and FALSE are the same. So it can do
+// more: `x = x' -> `x IS NOT NULL', `TRUE AND x' -> `x', `NOT NOT x' -> `x'.
+func SimplifyCond(expr ast.Expr) ast.Expr {
+	return simplify(expr, true)
+}
+
+// The expression is a constant true: a non-zero number literal.
+func IsTrue(expr ast.Expr) bool {
+	return truthOf(expr) == kTrue
+}
+
+// The expression is a constant false or NULL.
+func IsFalse(expr ast.Expr) bool {
+	t := truthOf(expr)
+	return t == kFalse || t == kNull
+}
+
+//------------------------------------------------------------------------------
+// Constants
+//------------------------------------------------------------------------------
+type truth int
+
+const (
+	kUnknown truth = iota
+	kTrue
+	kFalse
+	kNull
+)
+
+// Literals of constants, TRUE and FALSE are 1 and 0.
+func constOf(expr ast.Expr) (*ast.Literal, bool) {
+	switch node := expr.(type) {
+	case *ast.Literal:
+		switch node.Kind {
+		case token.NULL, token.INT_LITERAL, token.FLOAT_LITERAL, token.STRING_LITERAL:
+			return node, true
+		}
+
+	case *ast.Identifier:
+		if b, ok := node.BoolConst(); ok {
+			return boolLiteral(node.Pos(), b), true
+		}
+	}
+	return nil, false
+}
+
+func isNull(expr ast.Expr) bool {
+	lit, ok := constOf(expr)
+	return ok && lit.Kind == token.NULL
+}
+
+func numberOf(lit *ast.Literal) (*big.Rat, bool) {
+	if lit.Kind != token.INT_LITERAL && lit.Kind != token.FLOAT_LITERAL {
+		return nil, false
+	}
+	return new(big.Rat).SetString(lit.Value)
+}
+
+// Digits after decimal point.
+func scaleOf(lit *ast.Literal) int {
+	if i := strings.IndexByte(lit.Value, '.'); i >= 0 {
+		return len(lit.Value) - i - 1
+	}
+	return 0
+}
+
+// Strings are not folded, their values depend on conversion and collation.
+func truthOf(expr ast.Expr) truth {
+	lit, ok := constOf(expr)
+	if !ok {
+		return kUnknown
+	}
+	if lit.Kind == token.NULL {
+		return kNull
+	}
+	if r, ok := numberOf(lit); ok {
+		if r.Sign() != 0 {
+			return kTrue
+		}
+		return kFalse
+	}
+	return kUnknown
+}
+
+func nullLiteral(pos int) *ast.Literal {
+	return &ast.Literal{ValuePos: pos, Value: "NULL", Kind: token.NULL}
+}
+
+func boolLiteral(pos int, b bool) *ast.Literal {
+	if b {
+		return &ast.Literal{ValuePos: pos, Value: "1", Kind: token.INT_LITERAL}
+	}
+	return &ast.Literal{ValuePos: pos, Value: "0", Kind: token.INT_LITERAL}
+}
+
+func truthLiteral(pos int, t truth) *ast.Literal {
+	if t == kNull {
+		return nullLiteral(pos)
+	}
+	return boolLiteral(pos, t == kTrue)
+}
+
+// Returns false if integer is out of range of BIGINT.
+func numberLiteral(pos int, r *big.Rat, scale int, integer bool) (*ast.Literal, bool) {
+	if integer {
+		if !r.IsInt() || !r.Num().IsInt64() {
+			return nil, false
+		}
+		return &ast.Literal{ValuePos: pos, Value: r.Num().String(), Kind: token.INT_LITERAL}, true
+	}
+	return &ast.Literal{ValuePos: pos, Value: r.FloatString(scale), Kind: token.FLOAT_LITERAL}, true
+}
+
+//------------------------------------------------------------------------------
+// Simplification
+//------------------------------------------------------------------------------
+func simplify(expr ast.Expr, cond bool) ast.Expr {
+	switch node := expr.(type) {
+	case *ast.Identifier:
+		if lit, ok := constOf(node); ok {
+			return lit
+		}
+		return node
+
+	case *ast.UnaryExpr:
+		return simplifyUnary(node, cond)
+
+	case *ast.BinaryExpr:
+		return simplifyBinary(node, cond)
+
+	case *ast.Condition:
+		return simplifyCondition(node, cond)
+
+	case *ast.CastExpr:
+		return simplifyCast(node)
+
+	case *ast.IntervalExpr:
+		value := simplify(node.Value, false)
+		if value == node.Value {
+			return node
+		}
+		interval := *node
+		interval.Value = value
+		return &interval
+
+	case *ast.CallExpr:
+		args := simplifyList(node.Args)
+		if sameList(args, node.Args) {
+			return node
+		}
+		call := *node
+		call.Args = args
+		return &call
+
+	case ast.ExprList:
+		list := simplifyList(node)
+		if sameList(list, node) {
+			return node
+		}
+		return ast.ExprList(list)
+
+	default:
+		return expr
+	}
+}
+
+func simplifyList(list []ast.Expr) []ast.Expr {
+	rv := make([]ast.Expr, len(list))
+	for i, expr := range list {
+		rv[i] = simplify(expr, false)
+	}
+	return rv
+}
+
+func sameList(a, b []ast.Expr) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Result of expression is always 0, 1 or NULL.
+func isBoolean(expr ast.Expr) bool {
+	switch node := expr.(type) {
+	case *ast.UnaryExpr:
+		return node.Op == token.NOT || node.Op == token.IS_NULL || node.Op == token.IS_NOT_NULL
+
+	case *ast.BinaryExpr:
+		switch node.Op {
+		case token.EQ, token.NE, token.LT, token.LE, token.GT, token.GE, token.AND, token.OR,
+			token.IN, token.LIKE:
+			return true
+		}
+
+	default:
+		t := truthOf(expr)
+		if t == kNull {
+			return true
+		}
+		if lit, ok := constOf(expr); ok && (t == kTrue || t == kFalse) {
+			return lit.Value == "1" || lit.Value == "0"
+		}
+	}
+	return false
+}
+
+var negation = map[token.Token]token.Token{
+	token.EQ:          token.NE,
+	token.NE:          token.EQ,
+	token.LT:          token.GE,
+	token.GE:          token.LT,
+	token.GT:          token.LE,
+	token.LE:          token.GT,
+	token.IS_NULL:     token.IS_NOT_NULL,
+	token.IS_NOT_NULL: token.IS_NULL,
+}
+
+func simplifyUnary(expr *ast.UnaryExpr, cond bool) ast.Expr {
+	operand := simplify(expr.Operand, false)
+
+	switch expr.Op {
+	case token.MINUS:
+		if isNull(operand) {
+			return nullLiteral(expr.Pos())
+		}
+		if lit, ok := constOf(operand); ok {
+			if r, ok := numberOf(lit); ok {
+				if rv, ok := numberLiteral(expr.Pos(), r.Neg(r), scaleOf(lit),
+					lit.Kind == token.INT_LITERAL); ok {
+					return rv
+				}
+			}
+		}
+
+	case token.NOT:
+		if t := truthOf(operand); t != kUnknown {
+			switch t {
+			case kTrue:
+				t = kFalse
+			case kFalse:
+				t = kTrue
+			}
+			return truthLiteral(expr.Pos(), t)
+		}
+
+		switch inner := operand.(type) {
+		case *ast.UnaryExpr:
+			if inner.Op == token.NOT && (cond || isBoolean(inner.Operand)) {
+				return simplify(inner.Operand, cond)
+			}
+			if op, ok := negation[inner.Op]; ok {
+				return &ast.UnaryExpr{OpPos: inner.OpPos, Op: op, Operand: inner.Operand}
+			}
+
+		case *ast.BinaryExpr:
+			if op, ok := negation[inner.Op]; ok {
+				return simplify(&ast.BinaryExpr{
+					OpPos: inner.OpPos,
+					Op:    op,
+					Lhs:   inner.Lhs,
+					Rhs:   inner.Rhs,
+				}, cond)
+			}
+		}
+
+	case token.IS_NULL, token.IS_NOT_NULL:
+		if _, ok := constOf(operand); ok {
+			return boolLiteral(expr.Pos(), isNull(operand) == (expr.Op == token.IS_NULL))
+		}
+		if isNeverNull(operand) {
+			return boolLiteral(expr.Pos(), expr.Op == token.IS_NOT_NULL)
+		}
+	}
+
+	if operand == expr.Operand {
+		return expr
+	}
+	return &ast.UnaryExpr{OpPos: expr.OpPos, Op: expr.Op, Operand: operand}
+}
+
+func isNeverNull(expr ast.Expr) bool {
+	unary, ok := expr.(*ast.UnaryExpr)
+	return ok && (unary.Op == token.IS_NULL || unary.Op == token.IS_NOT_NULL)
+}
+
+func simplifyBinary(expr *ast.BinaryExpr, cond bool) ast.Expr {
+	if expr.Op == token.DOT {
+		return expr
+	}
+	if expr.Op == token.AND || expr.Op == token.OR {
+		return simplifyLogic(expr, cond)
+	}
+
+	lhs := simplify(expr.Lhs, false)
+	rhs := expr.Rhs
+	if _, ok := rhs.(ast.Query); !ok {
+		rhs = simplify(expr.Rhs, false)
+	}
+
+	switch expr.Op {
+	case token.PLUS, token.MINUS, token.STAR, token.SLASH:
+		if lit, ok := foldArithmetic(expr.Pos(), expr.Op, lhs, rhs); ok {
+			return lit
+		}
+
+	case token.EQ, token.NE, token.LT, token.LE, token.GT, token.GE:
+		if lit, ok := foldComparison(expr.Pos(), expr.Op, lhs, rhs); ok {
+			return lit
+		}
+		if cond && Equal(lhs, rhs) {
+			switch expr.Op {
+			case token.EQ, token.LE, token.GE:
+				return &ast.UnaryExpr{OpPos: expr.OpPos, Op: token.IS_NOT_NULL, Operand: lhs}
+			default:
+				return boolLiteral(expr.Pos(), false)
+			}
+		}
+
+	case token.IN, token.LIKE:
+		if isNull(lhs) {
+			return nullLiteral(expr.Pos())
+		}
+	}
+
+	if lhs == expr.Lhs && same(rhs, expr.Rhs) {
+		return expr
+	}
+	return &ast.BinaryExpr{OpPos: expr.OpPos, Op: expr.Op, Lhs: lhs, Rhs: rhs}
+}
+
+// ExprList is not comparable.
+func same(a, b ast.Expr) bool {
+	if x, ok := a.(ast.ExprList); ok {
+		y, ok := b.(ast.ExprList)
+		return ok && len(x) == len(y) && sameList(x, y)
+	}
+	return a == b
+}
+
+// Exact arithmetic like MySQL: INT op INT is INT except `/', DECIMAL keeps
+// scale of operands, and division by zero is NULL.
+func foldArithmetic(pos int, op token.Token, lhs, rhs ast.Expr) (*ast.Literal, bool) {
+	if isNull(lhs) || isNull(rhs) {
+		if _, ok := constOf(lhs); ok {
+			if _, ok = constOf(rhs); ok {
+				return nullLiteral(pos), true
+			}
+		}
+		return nil, false
+	}
+
+	a, ok1 := constOf(lhs)
+	b, ok2 := constOf(rhs)
+	if !ok1 || !ok2 {
+		return nil, false
+	}
+	x, ok1 := numberOf(a)
+	y, ok2 := numberOf(b)
+	if !ok1 || !ok2 {
+		return nil, false
+	}
+
+	integer := a.Kind == token.INT_LITERAL && b.Kind == token.INT_LITERAL
+	scale := scaleOf(a)
+	if scaleOf(b) > scale {
+		scale = scaleOf(b)
+	}
+	r := new(big.Rat)
+	switch op {
+	case token.PLUS:
+		r.Add(x, y)
+	case token.MINUS:
+		r.Sub(x, y)
+	case token.STAR:
+		r.Mul(x, y)
+		scale = scaleOf(a) + scaleOf(b)
+	case token.SLASH:
+		if y.Sign() == 0 {
+			return nullLiteral(pos), true
+		}
+		r.Quo(x, y)
+		scale = scaleOf(a) + DIV_PRECISION_INCREMENT
+		integer = false
+	}
+	return numberLiteral(pos, r, scale, integer)
+}
+
+func foldComparison(pos int, op token.Token, lhs, rhs ast.Expr) (*ast.Literal, bool) {
+	a, ok1 := constOf(lhs)
+	b, ok2 := constOf(rhs)
+	if !ok1 || !ok2 {
+		return nil, false
+	}
+	if a.Kind == token.NULL || b.Kind == token.NULL {
+		return nullLiteral(pos), true
+	}
+	x, ok1 := numberOf(a)
+	y, ok2 := numberOf(b)
+	if !ok1 || !ok2 {
+		return nil, false
+	}
+
+	c := x.Cmp(y)
+	switch op {
+	case token.EQ:
+		return boolLiteral(pos, c == 0), true
+	case token.NE:
+		return boolLiteral(pos, c != 0), true
+	case token.LT:
+		return boolLiteral(pos, c < 0), true
+	case token.LE:
+		return boolLiteral(pos, c <= 0), true
+	case token.GT:
+		return boolLiteral(pos, c > 0), true
+	default:
+		return boolLiteral(pos, c >= 0), true
+	}
+}
+
+func simplifyLogic(expr *ast.BinaryExpr, cond bool) ast.Expr {
+	lhs := simplify(expr.Lhs, cond)
+	rhs := simplify(expr.Rhs, cond)
+	lt, rt := truthOf(lhs), truthOf(rhs)
+
+	// `absorb' makes result constant, `identity' makes result the other side.
+	absorb, identity := kFalse, kTrue
+	if expr.Op == token.OR {
+		absorb, identity = kTrue, kFalse
+	}
+
+	switch {
+	case lt == absorb || rt == absorb:
+		return truthLiteral(expr.Pos(), absorb)
+
+	case lt != kUnknown && rt != kUnknown:
+		if lt == kNull || rt == kNull {
+			return truthLiteral(expr.Pos(), kNull)
+		}
+		return truthLiteral(expr.Pos(), identity)
+
+	case lt == identity && (cond || isBoolean(rhs)):
+		return rhs
+
+	case rt == identity && (cond || isBoolean(lhs)):
+		return lhs
+
+	case cond && (lt == kNull || rt == kNull):
+		// NULL AND x is never TRUE, NULL OR x is TRUE only if x is TRUE.
+		if expr.Op == token.AND {
+			return boolLiteral(expr.Pos(), false)
+		}
+		if lt == kNull {
+			return rhs
+		}
+		return lhs
+
+	case Equal(lhs, rhs) && (cond || isBoolean(lhs)):
+		return lhs
+	}
+
+	if lhs == expr.Lhs && rhs == expr.Rhs {
+		return expr
+	}
+	return &ast.BinaryExpr{OpPos: expr.OpPos, Op: expr.Op, Lhs: lhs, Rhs: rhs}
+}
+
+// WHEN of searched CASE is a condition, and result of CASE in a condition is a
+// condition as well.
+func simplifyCondition(expr *ast.Condition, cond bool) ast.Expr {
+	rv := &ast.Condition{OpPos: expr.OpPos}
+	if expr.Case != nil {
+		rv.Case = simplify(expr.Case, false)
+	}
+
+	matched := false
+	for _, block := range expr.Blocks {
+		when := simplify(block.When, rv.Case == nil)
+		then := simplify(block.Then, cond)
+
+		t := truthOf(when)
+		if rv.Case != nil {
+			t = kUnknown
+			if lit, ok := foldComparison(when.Pos(), token.EQ, rv.Case, when); ok {
+				t = truthOf(lit)
+			}
+		}
+
+		if t == kFalse || t == kNull {
+			continue // Never be matched
+		}
+		if t == kTrue {
+			// Always be matched, the rest blocks and ELSE are dead.
+			rv.Else, matched = then, true
+			break
+		}
+		rv.Blocks = append(rv.Blocks, ast.ConditionBlock{When: when, Then: then})
+	}
+	if !matched && expr.Else != nil {
+		rv.Else = simplify(expr.Else, cond)
+	}
+
+	if len(rv.Blocks) == 0 {
+		if rv.Else == nil {
+			return nullLiteral(expr.Pos())
+		}
+		return rv.Else
+	}
+	return rv
+}
+
+func simplifyCast(expr *ast.CastExpr) ast.Expr {
+	operand := simplify(expr.Operand, false)
+	if isNull(operand) {
+		return nullLiteral(expr.Pos())
+	}
+
+	if lit, ok := constOf(operand); ok && expr.To.Width == nil {
+		switch expr.To.Kind {
+		case token.TINYINT, token.SMALLINT, token.MEDIUMINT, token.INT, token.INTEGER,
+			token.BIGINT:
+			if lit.Kind == token.INT_LITERAL && !expr.To.Unsigned {
+				return lit
+			}
+
+		case token.CHAR, token.VARCHAR, token.TEXT:
+			switch lit.Kind {
+			case token.STRING_LITERAL:
+				return lit
+			case token.INT_LITERAL, token.FLOAT_LITERAL:
+				// Value of string literal is quoted
+				return &ast.Literal{ValuePos: expr.Pos(), Value: "'" + lit.Value + "'",
+					Kind: token.STRING_LITERAL}
+			}
+		}
+	}
+
+	if operand == expr.Operand {
+		return expr
+	}
+	cast := *expr
+	cast.Operand = operand
+	return &cast
+}
+
+//------------------------------------------------------------------------------
+// Equality
+//------------------------------------------------------------------------------
+
+// Equal reports whether two expressions are the same deterministic expression,
+// positions are ignored. Function calls, CASE and subqueries are never equal.
+func Equal(a, b ast.Expr) bool {
+	switch x := a.(type) {
+	case *ast.Literal:
+		y, ok := b.(*ast.Literal)
+		return ok && x.Kind == y.Kind && x.Value == y.Value
+
+	case *ast.Identifier:
+		y, ok := b.(*ast.Identifier)
+		if !ok {
+			return false
+		}
+		_, xname := x.Dequote()
+		_, yname := y.Dequote()
+		return strings.EqualFold(xname, yname)
+
+	case *ast.UnaryExpr:
+		y, ok := b.(*ast.UnaryExpr)
+		return ok && x.Op == y.Op && Equal(x.Operand, y.Operand)
+
+	case *ast.BinaryExpr:
+		y, ok := b.(*ast.BinaryExpr)
+		return ok && x.Op == y.Op && Equal(x.Lhs, y.Lhs) && Equal(x.Rhs, y.Rhs)
+
+	case *ast.CastExpr:
+		y, ok := b.(*ast.CastExpr)
+		return ok && x.To.Kind == y.To.Kind && equalLiteral(x.To.Width, y.To.Width) &&
+			equalLiteral(x.To.Decimal, y.To.Decimal) && Equal(x.Operand, y.Operand)
+
+	case *ast.IntervalExpr:
+		y, ok := b.(*ast.IntervalExpr)
+		return ok && x.Unit == y.Unit && Equal(x.Value, y.Value)
+
+	case ast.ExprList:
+		y, ok := b.(ast.ExprList)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !Equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+
+	default:
+		return false
+	}
+}
+
+func equalLiteral(a, b *ast.Literal) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return Equal(a, b)
+}
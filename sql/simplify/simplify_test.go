@@ -0,0 +1,132 @@
+package simplify
+
+import (
+	"testing"
+
+	"github.com/emptyland/akino/sql/ast"
+	"github.com/emptyland/akino/sql/parser"
+)
+
+func TestFoldConstant(t *testing.T) {
+	assertSimplify(t, "1 + 2 * 3", "7")
+	assertSimplify(t, "(1 + 2) * a", "3 * a")
+	assertSimplify(t, "1 / 4", "0.2500")
+	assertSimplify(t, "1.5 * 2.25", "3.375")
+	assertSimplify(t, "0.1 + 0.2", "0.3")
+	assertSimplify(t, "-(2 - 5)", "3")
+	assertSimplify(t, "1 / 0", "NULL")
+	assertSimplify(t, "NULL + a", "NULL + a")
+	assertSimplify(t, "NULL + 1", "NULL")
+	assertSimplify(t, "9223372036854775807 + 1", "9223372036854775807 + 1")
+	assertSimplify(t, "'a' + 1", "'a' + 1")
+	if call := Simplify(mustParse(t, "MAX(1 + 1)")).(*ast.CallExpr); !Equal(call.Args[0],
+		mustParse(t, "2")) {
+		t.Fatal(call.Args)
+	}
+	assertSimplify(t, "a IN (1 + 1, 3)", "a IN (2, 3)")
+	assertSimplify(t, "CAST(1 AS INT)", "1")
+	assertSimplify(t, "CAST(12 AS CHAR)", "'12'")
+	assertSimplify(t, "CAST(NULL AS DATE)", "NULL")
+	assertSimplify(t, "a + INTERVAL 1 + 1 DAY", "a + INTERVAL 2 DAY")
+	assertSimplify(t, "DATE '2020-01-02' = a", "DATE '2020-01-02' = a")
+}
+
+func TestFoldLogic(t *testing.T) {
+	assertSimplify(t, "1 < 2", "1")
+	assertSimplify(t, "2.0 = 2", "1")
+	assertSimplify(t, "NULL = NULL", "NULL")
+	assertSimplify(t, "NOT 0", "1")
+	assertSimplify(t, "NOT NULL", "NULL")
+	assertSimplify(t, "NULL IS NULL", "1")
+	assertSimplify(t, "a IS NULL IS NULL", "0")
+	assertSimplify(t, "a > 1 AND FALSE", "0")
+	assertSimplify(t, "a > 1 OR TRUE", "1")
+	assertSimplify(t, "NULL AND 0", "0")
+	assertSimplify(t, "NULL OR 0", "NULL")
+	assertSimplify(t, "a > 1 AND TRUE", "a > 1")
+	assertSimplify(t, "a AND TRUE", "a AND 1")
+	assertSimplify(t, "NOT NOT a", "NOT NOT a")
+	assertSimplify(t, "NOT NOT (a = 1)", "a = 1")
+	assertSimplify(t, "NOT (a < 1)", "a >= 1")
+	assertSimplify(t, "NOT (a IS NULL)", "a IS NOT NULL")
+	assertSimplify(t, "a = 1 OR a = 1", "a = 1")
+	assertSimplify(t, "x = x", "x = x")
+}
+
+func TestSimplifyCond(t *testing.T) {
+	assertSimplifyCond(t, "x = x AND TRUE", "x IS NOT NULL")
+	assertSimplifyCond(t, "NOT NOT a", "a")
+	assertSimplifyCond(t, "a AND 1", "a")
+	assertSimplifyCond(t, "a < a OR b", "b")
+	assertSimplifyCond(t, "NULL AND a", "0")
+	assertSimplifyCond(t, "NULL OR a", "a")
+	assertSimplifyCond(t, "t.a = t.a", "t.a IS NOT NULL")
+	assertSimplifyCond(t, "NOT (a = a)", "0")
+	if expr := mustParse(t, "RAND() = RAND()"); SimplifyCond(expr) != expr {
+		t.Fatal("fail")
+	}
+
+	if !IsTrue(SimplifyCond(mustParse(t, "1 = 1 OR a"))) {
+		t.Fatal("fail")
+	}
+	if !IsFalse(SimplifyCond(mustParse(t, "1 = 0"))) {
+		t.Fatal("fail")
+	}
+}
+
+func TestSimplifyCase(t *testing.T) {
+	assertSimplify(t, "CASE WHEN 1 THEN a ELSE b", "a")
+	assertSimplify(t, "CASE WHEN 0 THEN a ELSE b", "b")
+	assertSimplify(t, "CASE WHEN 0 THEN a", "NULL")
+	assertSimplify(t, "CASE 2 WHEN 1 THEN a WHEN 2 THEN b ELSE c", "b")
+
+	cond := Simplify(mustParse(t,
+		"CASE WHEN NULL THEN a WHEN x THEN 1 + 1 WHEN 2 > 1 THEN c ELSE d")).(*ast.Condition)
+	if len(cond.Blocks) != 1 || !Equal(cond.Blocks[0].When, mustParse(t, "x")) ||
+		!Equal(cond.Blocks[0].Then, mustParse(t, "2")) || !Equal(cond.Else, mustParse(t, "c")) {
+		t.Fatal(cond)
+	}
+
+	cond = Simplify(mustParse(t, "CASE x WHEN 1 THEN a WHEN 2 THEN b")).(*ast.Condition)
+	if len(cond.Blocks) != 2 || cond.Else != nil {
+		t.Fatal(cond)
+	}
+
+	cond = Simplify(mustParse(t, "CASE WHEN x = x THEN a")).(*ast.Condition)
+	if !Equal(cond.Blocks[0].When, mustParse(t, "x IS NOT NULL")) {
+		t.Fatal(cond.Blocks[0].When)
+	}
+}
+
+func TestSimplifyShared(t *testing.T) {
+	expr := mustParse(t, "a + (1 + 2)")
+	rv := Simplify(expr).(*ast.BinaryExpr)
+	if expr.(*ast.BinaryExpr).Rhs == rv.Rhs || rv.Lhs != expr.(*ast.BinaryExpr).Lhs {
+		t.Fatal("fail")
+	}
+
+	expr = mustParse(t, "a + b")
+	if Simplify(expr) != expr {
+		t.Fatal("fail")
+	}
+}
+
+func assertSimplify(t *testing.T, input, expected string) {
+	if rv := Simplify(mustParse(t, input)); !Equal(rv, mustParse(t, expected)) {
+		t.Fatalf("%s: unexpected %#v", input, rv)
+	}
+}
+
+func assertSimplifyCond(t *testing.T, input, expected string) {
+	if rv := SimplifyCond(mustParse(t, input)); !Equal(rv, mustParse(t, expected)) {
+		t.Fatalf("%s: unexpected %#v", input, rv)
+	}
+}
+
+func mustParse(t *testing.T, input string) ast.Expr {
+	expr, err := parser.ParseExpression(input)
+	if err != nil {
+		t.Fatal(input, err)
+	}
+	return expr
+}
@@ -50,6 +50,9 @@ func (self *Lexer) Next() (int, Token, string) {
 	case '\'', '"':
 		return self.readString(r)
 
+	case '@':
+		return self.readVariable()
+
 	case '/':
 		return self.readSlashPrefix()
 
@@ -97,7 +100,7 @@ func (self *Lexer) Error() error {
 
 func (self *Lexer) advance(r rune) (int, Token, string) {
 	switch {
-	case unicode.IsLetter(r):
+	case unicode.IsLetter(r), r == '_':
 		return self.readIdOrKeyword()
 
 	case unicode.IsSpace(r):
@@ -136,14 +139,14 @@ func (self *Lexer) readIdentifier(has_quote bool) (int, Token, string) {
 	if err != nil {
 		return self.eof(err)
 	}
-	if !unicode.IsLetter(r) {
+	if !unicode.IsLetter(r) && r != '_' {
 		return self.illegal("Bad identifier, should starts with a letter")
 	}
 	var sb bytes.Buffer
 	if has_quote {
 		sb.WriteRune('`')
 		for r != '`' {
-			if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if !isidentifier(r) {
 				return self.illegal("Illegal identifier character")
 			}
 			sb.WriteRune(r)
@@ -153,11 +156,11 @@ func (self *Lexer) readIdentifier(has_quote bool) (int, Token, string) {
 		}
 		sb.WriteRune('`')
 	} else {
-		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+		if isidentifier(r) {
 			sb.WriteRune(r)
 		}
 		for {
-			if r, err = self.peek(); err != nil || (!unicode.IsLetter(r) && !unicode.IsDigit(r)) {
+			if r, err = self.peek(); err != nil || !isidentifier(r) {
 				break
 			}
 			sb.WriteRune(r)
@@ -167,6 +170,26 @@ func (self *Lexer) readIdentifier(has_quote bool) (int, Token, string) {
 	return self.pos, ID, sb.String()
 }
 
+func (self *Lexer) readVariable() (int, Token, string) {
+	self.pos = self.last
+
+	self.skip() // skip '@'
+	var sb bytes.Buffer
+	sb.WriteRune('@')
+	for {
+		if r, err := self.peek(); err != nil || !isidentifier(r) {
+			break
+		} else {
+			sb.WriteRune(r)
+			self.skip()
+		}
+	}
+	if sb.Len() == 1 {
+		return self.illegal("Bad user variable, no name")
+	}
+	return self.pos, VARIABLE, sb.String()
+}
+
 func (self *Lexer) readString(quote rune) (int, Token, string) {
 	self.pos = self.last
 
@@ -401,3 +424,7 @@ func (self *Lexer) eof(err error) (int, Token, string) {
 func isnewline(r rune) bool {
 	return r == '\r' || r == '\n'
 }
+
+func isidentifier(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
@@ -47,6 +47,29 @@ func TestQuotedKeyword(t *testing.T) {
 	assertEnd(t, lex)
 }
 
+func TestUnderscoreId(t *testing.T) {
+	lex := NewLexer("user_id `_name` AUTO_INCREMENT")
+
+	assertNextToken(t, 0, ID, "user_id", lex)
+	assertNextToken(t, 8, ID, "`_name`", lex)
+	assertNextToken(t, 16, AUTO_INCREMENT, "AUTO_INCREMENT", lex)
+	assertEnd(t, lex)
+}
+
+func TestVariable(t *testing.T) {
+	lex := NewLexer("@a = @b_1")
+
+	assertNextToken(t, 0, VARIABLE, "@a", lex)
+	assertNextToken(t, 3, EQ, "=", lex)
+	assertNextToken(t, 5, VARIABLE, "@b_1", lex)
+	assertEnd(t, lex)
+
+	lex = NewLexer("@ a")
+	if _, tok, _ := lex.Next(); tok != ILLEGAL {
+		t.Fatal(tok)
+	}
+}
+
 func TestInt(t *testing.T) {
 	lex := NewLexer("190 255 31415926 65535")
 
@@ -157,6 +157,88 @@ const (
 	LONGBLOB   // 4GB BLOB
 	LONGTEXT   // 4GB TEXT
 	UNSIGNED
+
+	// Constraints
+	CONSTRAINT
+	FOREIGN
+	REFERENCES
+	DEFERRABLE
+	INITIALLY
+	// Actions of foreign key, they are not reserved words
+	CASCADE
+	RESTRICT
+	SET_NULL    // SET NULL
+	SET_DEFAULT // SET DEFAULT
+	NO_ACTION   // NO ACTION
+
+	// MySQL attributes
+	CHARACTER      // CHARACTER SET
+	ZEROFILL       // [UNSIGNED] [ZEROFILL]
+	AUTO_INCREMENT // AUTO_INCREMENT [=] 100
+
+	// More row types, they are not reserved words
+	ENUM               // ('a', 'b', ...) one of values
+	JSON               // JSON document
+	GEOMETRY           // Any spatial value
+	POINT              // (X, Y)
+	LINESTRING         // Points list
+	POLYGON            // Rings list
+	MULTIPOINT         // Points set
+	MULTILINESTRING    // Line strings set
+	MULTIPOLYGON       // Polygons set
+	GEOMETRYCOLLECTION // Geometries set
+
+	// Upsert
+	DUPLICATE // ON DUPLICATE KEY UPDATE
+	DO        // ON CONFLICT DO UPDATE
+	NOTHING   // ON CONFLICT DO NOTHING
+
+	RETURNING // INSERT/UPDATE/DELETE ... RETURNING SelColList
+
+	// Show
+	COLUMNS // SHOW COLUMNS, it is not a reserved word
+	DESCRIBE
+
+	// Explain
+	EXPLAIN
+	YAML // FORMAT YAML of EXPLAIN, it is not a reserved word
+
+	// Transaction
+	SAVEPOINT
+	RELEASE
+	TO
+
+	// Database
+	USE
+	ATTACH
+	DETACH
+
+	// Trigger
+	TRIGGER
+	BEFORE
+	AFTER
+	INSTEAD // INSTEAD OF, it is not a reserved word
+
+	// Maintenance
+	PRAGMA
+	VACUUM
+	REINDEX
+	TRUNCATE
+
+	// Prepared statement
+	PREPARE
+	EXECUTE
+	DEALLOCATE
+	VARIABLE // @var
+
+	// Join
+	STRAIGHT_JOIN
+
+	// Date and time
+	INTERVAL          // INTERVAL Expr Unit
+	CURRENT_DATE      // CURDATE()
+	CURRENT_TIME      // CURTIME()
+	CURRENT_TIMESTAMP // NOW()
 )
 
 type Type int
@@ -364,4 +446,85 @@ var tokenMetadata = []tokeniton{
 	tokeniton{"LONGBLOB", TT_KEYWORD},
 	tokeniton{"LONGTEXT", TT_KEYWORD},
 	tokeniton{"UNSIGNED", TT_KEYWORD},
+
+	// Constraints
+	tokeniton{"CONSTRAINT", TT_KEYWORD},
+	tokeniton{"FOREIGN", TT_KEYWORD},
+	tokeniton{"REFERENCES", TT_KEYWORD},
+	tokeniton{"DEFERRABLE", TT_KEYWORD},
+	tokeniton{"INITIALLY", TT_KEYWORD},
+	tokeniton{"CASCADE", TT_OPERATOR},     // CASCADE
+	tokeniton{"RESTRICT", TT_OPERATOR},    // RESTRICT
+	tokeniton{"SET NULL", TT_OPERATOR},    // SET_NULL
+	tokeniton{"SET DEFAULT", TT_OPERATOR}, // SET_DEFAULT
+	tokeniton{"NO ACTION", TT_OPERATOR},   // NO_ACTION
+
+	// MySQL attributes
+	tokeniton{"CHARACTER", TT_KEYWORD},
+	tokeniton{"ZEROFILL", TT_KEYWORD},
+	tokeniton{"AUTO_INCREMENT", TT_KEYWORD},
+
+	// More row types
+	tokeniton{"ENUM", TT_OPERATOR},
+	tokeniton{"JSON", TT_OPERATOR},
+	tokeniton{"GEOMETRY", TT_OPERATOR},
+	tokeniton{"POINT", TT_OPERATOR},
+	tokeniton{"LINESTRING", TT_OPERATOR},
+	tokeniton{"POLYGON", TT_OPERATOR},
+	tokeniton{"MULTIPOINT", TT_OPERATOR},
+	tokeniton{"MULTILINESTRING", TT_OPERATOR},
+	tokeniton{"MULTIPOLYGON", TT_OPERATOR},
+	tokeniton{"GEOMETRYCOLLECTION", TT_OPERATOR},
+
+	// Upsert
+	tokeniton{"DUPLICATE", TT_KEYWORD},
+	tokeniton{"DO", TT_KEYWORD},
+	tokeniton{"NOTHING", TT_KEYWORD},
+
+	tokeniton{"RETURNING", TT_KEYWORD},
+
+	// Show
+	tokeniton{"COLUMNS", TT_OPERATOR}, // COLUMNS
+	tokeniton{"DESCRIBE", TT_KEYWORD},
+
+	// Explain
+	tokeniton{"EXPLAIN", TT_KEYWORD},
+	tokeniton{"YAML", TT_OPERATOR}, // YAML
+
+	// Transaction
+	tokeniton{"SAVEPOINT", TT_KEYWORD},
+	tokeniton{"RELEASE", TT_KEYWORD},
+	tokeniton{"TO", TT_KEYWORD},
+
+	// Database
+	tokeniton{"USE", TT_KEYWORD},
+	tokeniton{"ATTACH", TT_KEYWORD},
+	tokeniton{"DETACH", TT_KEYWORD},
+
+	// Trigger
+	tokeniton{"TRIGGER", TT_KEYWORD},
+	tokeniton{"BEFORE", TT_KEYWORD},
+	tokeniton{"AFTER", TT_KEYWORD},
+	tokeniton{"INSTEAD OF", TT_OPERATOR}, // INSTEAD
+
+	// Maintenance
+	tokeniton{"PRAGMA", TT_KEYWORD},
+	tokeniton{"VACUUM", TT_KEYWORD},
+	tokeniton{"REINDEX", TT_KEYWORD},
+	tokeniton{"TRUNCATE", TT_KEYWORD},
+
+	// Prepared statement
+	tokeniton{"PREPARE", TT_KEYWORD},
+	tokeniton{"EXECUTE", TT_KEYWORD},
+	tokeniton{"DEALLOCATE", TT_KEYWORD},
+	tokeniton{"variable", TT_LITERAL}, // VARIABLE
+
+	// Join
+	tokeniton{"STRAIGHT_JOIN", TT_KEYWORD},
+
+	// Date and time
+	tokeniton{"INTERVAL", TT_KEYWORD},
+	tokeniton{"CURRENT_DATE", TT_KEYWORD},
+	tokeniton{"CURRENT_TIME", TT_KEYWORD},
+	tokeniton{"CURRENT_TIMESTAMP", TT_KEYWORD},
 }